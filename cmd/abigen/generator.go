@@ -0,0 +1,363 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"sort"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"github.com/joeqian10/neo-gogogo/sc"
+)
+
+type goType struct {
+	Name   string // go type name
+	Zero   string // zero value
+	Import string // package to import, empty for builtin types
+}
+
+var resultTypes = map[sc.ContractParameterType]goType{
+	sc.Signature:        {"[]byte", "nil", ""},
+	sc.Boolean:          {"bool", "false", ""},
+	sc.Integer:          {"*big.Int", "nil", "math/big"},
+	sc.Hash160:          {"helper.UInt160", "helper.UInt160{}", "github.com/joeqian10/neo-gogogo/helper"},
+	sc.Hash256:          {"helper.UInt256", "helper.UInt256{}", "github.com/joeqian10/neo-gogogo/helper"},
+	sc.ByteArray:        {"[]byte", "nil", ""},
+	sc.PublicKey:        {"[]byte", "nil", ""},
+	sc.String:           {"string", `""`, ""},
+	sc.Array:            {"[]interface{}", "nil", ""},
+	sc.Map:              {"[]models.StackMapEntry", "nil", "github.com/joeqian10/neo-gogogo/rpc/models"},
+	sc.InteropInterface: {"interface{}", "nil", ""},
+	sc.Any:              {"interface{}", "nil", ""},
+}
+
+// parameters accept anything sc.NewContractParameter can convert
+var paramTypes = map[sc.ContractParameterType]goType{
+	sc.Array: {"[]interface{}", "nil", ""},
+	sc.Map:   {"interface{}", "nil", ""},
+}
+
+type paramData struct {
+	Name     string // abi name
+	GoName   string // go argument or field name
+	Type     string
+	AbiType  string // sc constant name
+	Zero     string
+	ItemName string
+	Optional bool // a Hash160 event field, which is nil when null or empty
+}
+
+type functionData struct {
+	Name       string // abi name
+	GoName     string
+	Params     []paramData
+	HasResult  bool
+	ResultType string
+	ResultZero string
+}
+
+type eventData struct {
+	Name     string
+	GoName   string
+	Params   []paramData
+	HasTyped bool // some fields need a type assertion
+}
+
+type contractData struct {
+	Package   string
+	Name      string
+	Hash      string
+	AbiJson   string
+	Imports   []string
+	Functions []functionData
+	Events    []eventData
+}
+
+// Generate generates the go bindings of the contract described by the abi json
+func Generate(packageName string, contractName string, abiJson []byte) ([]byte, error) {
+	abi, err := sc.NewContractAbi(abiJson)
+	if err != nil {
+		return nil, err
+	}
+	name := exportedName(contractName)
+	if name == "" {
+		return nil, fmt.Errorf("invalid contract name %s", contractName)
+	}
+	data := contractData{
+		Package: packageName,
+		Name:    name,
+		Hash:    "0x" + abi.Hash.String(),
+		AbiJson: strings.ReplaceAll(string(abiJson), "`", "` + \"`\" + `"),
+	}
+	imports := map[string]bool{
+		"fmt": true,
+		"github.com/joeqian10/neo-gogogo/contract": true,
+		"github.com/joeqian10/neo-gogogo/sc":       true,
+	}
+	for _, f := range abi.Functions {
+		if f.Name == abi.EntryPoint {
+			continue // the entry point dispatches by operation, call the other methods instead
+		}
+		fd := functionData{Name: f.Name, GoName: exportedName(f.Name)}
+		fd.Params, err = makeParams(f.Parameters, imports, false)
+		if err != nil {
+			return nil, fmt.Errorf("method %s: %v", f.Name, err)
+		}
+		if f.ReturnType != sc.Void {
+			t, ok := resultTypes[f.ReturnType]
+			if !ok {
+				return nil, fmt.Errorf("method %s: unsupported return type %s", f.Name, f.ReturnType)
+			}
+			addImport(imports, t.Import)
+			fd.HasResult = true
+			fd.ResultType = t.Name
+			fd.ResultZero = t.Zero
+		}
+		data.Functions = append(data.Functions, fd)
+	}
+	if len(data.Functions) != 0 {
+		addImport(imports, "github.com/joeqian10/neo-gogogo/helper")
+		addImport(imports, "github.com/joeqian10/neo-gogogo/wallet")
+	}
+	for _, e := range abi.Events {
+		ed := eventData{Name: e.Name, GoName: exportedName(e.Name)}
+		ed.Params, err = makeParams(e.Parameters, imports, true)
+		if err != nil {
+			return nil, fmt.Errorf("event %s: %v", e.Name, err)
+		}
+		for _, p := range ed.Params {
+			if p.Type != "interface{}" && !p.Optional {
+				ed.HasTyped = true
+			}
+		}
+		data.Events = append(data.Events, ed)
+	}
+	if len(data.Events) != 0 {
+		addImport(imports, "github.com/joeqian10/neo-gogogo/rpc/models")
+	}
+	for i := range imports {
+		data.Imports = append(data.Imports, i)
+	}
+	sort.Strings(data.Imports)
+
+	buf := new(bytes.Buffer)
+	if err = bindingTemplate.Execute(buf, data); err != nil {
+		return nil, err
+	}
+	return format.Source(buf.Bytes())
+}
+
+// event fields are decoded, so they use the result types
+func makeParams(ps []sc.AbiParameter, imports map[string]bool, decoded bool) ([]paramData, error) {
+	var result []paramData
+	used := map[string]bool{}
+	for i, p := range ps {
+		t, ok := resultTypes[p.Type]
+		if !decoded {
+			if pt, ok2 := paramTypes[p.Type]; ok2 {
+				t = pt
+			}
+		}
+		if !ok {
+			return nil, fmt.Errorf("unsupported parameter type %s", p.Type)
+		}
+		addImport(imports, t.Import)
+		goName := unexportedName(p.Name)
+		if decoded {
+			goName = exportedName(p.Name)
+		}
+		if goName == "" || used[goName] || goName == "c" || goName == "w" {
+			goName = fmt.Sprintf("arg%d", i)
+			if decoded {
+				goName = fmt.Sprintf("Arg%d", i)
+			}
+		}
+		used[goName] = true
+		pd := paramData{
+			Name:     p.Name,
+			GoName:   goName,
+			Type:     t.Name,
+			AbiType:  "sc." + p.Type.String(),
+			Zero:     t.Zero,
+			ItemName: fmt.Sprintf("items[%d]", i+1),
+		}
+		// the from and to of the transfer notification are empty when minting and burning
+		if decoded && p.Type == sc.Hash160 {
+			pd.Type = "*helper.UInt160"
+			pd.Zero = "nil"
+			pd.Optional = true
+		}
+		result = append(result, pd)
+	}
+	return result, nil
+}
+
+func addImport(imports map[string]bool, path string) {
+	if path != "" {
+		imports[path] = true
+	}
+}
+
+func exportedName(s string) string {
+	n := identifier(s)
+	if n == "" {
+		return ""
+	}
+	r := []rune(n)
+	r[0] = unicode.ToUpper(r[0])
+	return string(r)
+}
+
+func unexportedName(s string) string {
+	n := identifier(s)
+	if n == "" {
+		return ""
+	}
+	r := []rune(n)
+	r[0] = unicode.ToLower(r[0])
+	n = string(r)
+	if goKeywords[n] {
+		n += "_"
+	}
+	return n
+}
+
+// identifier removes the characters not allowed in go identifiers, and camel cases the words
+func identifier(s string) string {
+	var sb strings.Builder
+	upper := false
+	for _, r := range s {
+		if unicode.IsLetter(r) || (unicode.IsDigit(r) && sb.Len() > 0) {
+			if upper {
+				r = unicode.ToUpper(r)
+				upper = false
+			}
+			sb.WriteRune(r)
+		} else {
+			upper = sb.Len() > 0
+		}
+	}
+	return sb.String()
+}
+
+var goKeywords = map[string]bool{
+	"break": true, "case": true, "chan": true, "const": true, "continue": true, "default": true,
+	"defer": true, "else": true, "fallthrough": true, "for": true, "func": true, "go": true, "goto": true,
+	"if": true, "import": true, "interface": true, "map": true, "package": true, "range": true,
+	"return": true, "select": true, "struct": true, "switch": true, "type": true, "var": true,
+	// names used by the generated code
+	"abi": true, "err": true, "params": true, "v": true, "r": true, "ok": true,
+	"fmt": true, "big": true, "contract": true, "helper": true, "models": true, "sc": true, "wallet": true,
+}
+
+var bindingTemplate = template.Must(template.New("binding").Parse(`// Code generated by abigen. DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+{{- range .Imports}}
+	"{{.}}"
+{{- end}}
+)
+
+const {{.Name}}AbiJson = ` + "`{{.AbiJson}}`" + `
+
+// {{.Name}} is the go binding of contract {{.Hash}}
+type {{.Name}} struct {
+	Abi    *sc.ContractAbi
+	Client *contract.ContractClient
+}
+
+// New{{.Name}} creates the binding which calls the contract through the rpc end point
+func New{{.Name}}(endPoint string) (*{{.Name}}, error) {
+	abi, err := sc.NewContractAbi([]byte({{.Name}}AbiJson))
+	if err != nil {
+		return nil, err
+	}
+	client := contract.NewContractClient(abi, endPoint)
+	if client == nil {
+		return nil, fmt.Errorf("invalid end point %s", endPoint)
+	}
+	return &{{.Name}}{Abi: abi, Client: client}, nil
+}
+{{range .Functions}}{{$f := .}}
+// Call{{.GoName}} calls {{.Name}} with InvokeScript, no transaction is sent
+func (c *{{$.Name}}) Call{{.GoName}}({{range $i, $p := .Params}}{{if $i}}, {{end}}{{$p.GoName}} {{$p.Type}}{{end}}) {{if .HasResult}}({{.ResultType}}, error){{else}}error{{end}} {
+	{{if .HasResult}}v{{else}}_{{end}}, err := c.Client.Call("{{.Name}}"{{range .Params}}, {{.GoName}}{{end}})
+	if err != nil {
+		return {{if .HasResult}}{{.ResultZero}}, {{end}}err
+	}
+	{{- if .HasResult}}
+	{{- if eq .ResultType "interface{}"}}
+	return v, nil
+	{{- else}}
+	r, ok := v.({{.ResultType}})
+	if !ok {
+		return {{.ResultZero}}, fmt.Errorf("unexpected result type %T", v)
+	}
+	return r, nil
+	{{- end}}
+	{{- else}}
+	return nil
+	{{- end}}
+}
+
+// Invoke{{.GoName}} invokes {{.Name}} with a transaction signed by the account of the wallet helper
+func (c *{{$.Name}}) Invoke{{.GoName}}(w *wallet.WalletHelper{{range .Params}}, {{.GoName}} {{.Type}}{{end}}) (*helper.UInt256, error) {
+	params, err := c.Abi.MakeParameters("{{.Name}}"{{range .Params}}, {{.GoName}}{{end}})
+	if err != nil {
+		return nil, err
+	}
+	return w.InvokeContract(c.Abi.Hash, "{{.Name}}", params)
+}
+{{end}}
+{{- range .Events}}
+// {{$.Name}}{{.GoName}}Event is the {{.Name}} notification of the contract
+type {{$.Name}}{{.GoName}}Event struct {
+{{- range .Params}}
+	{{.GoName}} {{.Type}}
+{{- end}}
+}
+
+// Decode{{$.Name}}{{.GoName}}Event decodes the {{.Name}} notification
+func Decode{{$.Name}}{{.GoName}}Event(notification models.RpcNotification) (*{{$.Name}}{{.GoName}}Event, error) {
+	items, err := notification.State.AsArray()
+	if err != nil {
+		return nil, err
+	}
+	if len(items) != {{len .Params}}+1 {
+		return nil, fmt.Errorf("{{.Name}} event expects {{len .Params}} arguments, got %d", len(items)-1)
+	}
+	name, err := items[0].AsString()
+	if err != nil {
+		return nil, err
+	}
+	if name != "{{.Name}}" {
+		return nil, fmt.Errorf("expected {{.Name}} event, got %s", name)
+	}
+	e := &{{$.Name}}{{.GoName}}Event{}
+	{{- if .HasTyped}}
+	var ok bool
+	{{- end}}
+	{{- range .Params}}
+	{{- if .Optional}}
+	if e.{{.GoName}}, err = sc.DecodeOptionalHash160({{.ItemName}}); err != nil {
+		return nil, err
+	}
+	{{- else if eq .Type "interface{}"}}
+	if e.{{.GoName}}, err = sc.DecodeStackItem({{.AbiType}}, {{.ItemName}}); err != nil {
+		return nil, err
+	}
+	{{- else}}
+	if v, err := sc.DecodeStackItem({{.AbiType}}, {{.ItemName}}); err != nil {
+		return nil, err
+	} else if e.{{.GoName}}, ok = v.({{.Type}}); !ok {
+		return nil, fmt.Errorf("unexpected {{.Name}} type %T", v)
+	}
+	{{- end}}
+	{{- end}}
+	return e, nil
+}
+{{end}}`))
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const tokenAbiJson = `{
+	"hash": "0xb9d7ea3062e6aeeb3e8ad9548220c4ba1361d263",
+	"entrypoint": "Main",
+	"functions": [
+		{
+			"name": "Main",
+			"parameters": [
+				{"name": "operation", "type": "String"},
+				{"name": "args", "type": "Array"}
+			],
+			"returntype": "Any"
+		},
+		{
+			"name": "balanceOf",
+			"parameters": [{"name": "account", "type": "Hash160"}],
+			"returntype": "Integer"
+		},
+		{
+			"name": "transfer",
+			"parameters": [
+				{"name": "from", "type": "Hash160"},
+				{"name": "to", "type": "Hash160"},
+				{"name": "amount", "type": "Integer"}
+			],
+			"returntype": "Boolean"
+		},
+		{
+			"name": "set_owner",
+			"parameters": [{"name": "type", "type": "PublicKey"}],
+			"returntype": "Void"
+		}
+	],
+	"events": [
+		{
+			"name": "transfer",
+			"parameters": [
+				{"name": "from", "type": "Hash160"},
+				{"name": "to", "type": "Hash160"},
+				{"name": "amount", "type": "Integer"}
+			],
+			"returntype": "Void"
+		}
+	]
+}`
+
+// typeCheck type-checks the generated code against the packages of the repo, imported from source
+func typeCheck(t *testing.T, code []byte) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "token.go", code, 0)
+	assert.Nil(t, err)
+	if err != nil {
+		return
+	}
+	conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	_, err = conf.Check("token", fset, []*ast.File{file}, nil)
+	assert.Nil(t, err)
+}
+
+func TestGenerate(t *testing.T) {
+	code, err := Generate("token", "my-token", []byte(tokenAbiJson))
+	assert.Nil(t, err)
+	typeCheck(t, code)
+
+	s := string(code)
+	assert.True(t, strings.HasPrefix(s, "// Code generated by abigen. DO NOT EDIT."))
+	assert.Contains(t, s, "func NewMyToken(endPoint string) (*MyToken, error)")
+	assert.Contains(t, s, "func (c *MyToken) CallBalanceOf(account helper.UInt160) (*big.Int, error)")
+	assert.Contains(t, s, "func (c *MyToken) InvokeTransfer(w *wallet.WalletHelper, from helper.UInt160, to helper.UInt160, amount *big.Int) (*helper.UInt256, error)")
+	assert.Contains(t, s, "func (c *MyToken) CallSetOwner(type_ []byte) error")
+	assert.Contains(t, s, "func DecodeMyTokenTransferEvent(notification models.RpcNotification) (*MyTokenTransferEvent, error)")
+	assert.Contains(t, s, "From   *helper.UInt160")
+	assert.NotContains(t, s, "CallMain")
+}
+
+// the generated decoder accepts the empty from of the transfer notification raised when minting
+const mintTestSource = `package token
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/joeqian10/neo-gogogo/rpc/models"
+)
+
+func TestDecodeMint(t *testing.T) {
+	var n models.RpcNotification
+	err := json.Unmarshal([]byte(` + "`" + `{
+		"contract": "0xb9d7ea3062e6aeeb3e8ad9548220c4ba1361d263",
+		"state": {"type": "Array", "value": [
+			{"type": "ByteArray", "value": "7472616e73666572"},
+			{"type": "ByteArray", "value": ""},
+			{"type": "ByteArray", "value": "8f6c5be89c0cb6579e44a8bf9bfd2ecbcc11dfdc"},
+			{"type": "Integer", "value": "100"}
+		]}
+	}` + "`" + `), &n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, err := DecodeMyTokenTransferEvent(n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.From != nil || e.To == nil || e.To.String() != "dcdf11cccb2efd9bbfa8449e57b60c9ce85b6c8f" || e.Amount.Int64() != 100 {
+		t.Fatalf("unexpected event %+v", e)
+	}
+}
+`
+
+func TestGenerate_DecodeMint(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds the generated code")
+	}
+	goTool, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go tool not found")
+	}
+	code, err := Generate("token", "my-token", []byte(tokenAbiJson))
+	assert.Nil(t, err)
+
+	// a directory in the module, so the generated code builds against the packages of the repo
+	err = os.MkdirAll("testdata", 0755)
+	assert.Nil(t, err)
+	defer os.Remove("testdata") // only if empty
+	dir, err := os.MkdirTemp("testdata", "mint")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "token.go"), code, 0644))
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "token_test.go"), []byte(mintTestSource), 0644))
+
+	out, err := exec.Command(goTool, "test", "./"+filepath.ToSlash(dir)).CombinedOutput()
+	assert.Nil(t, err, string(out))
+}
+
+func TestGenerate_Invalid(t *testing.T) {
+	_, err := Generate("token", "", []byte(tokenAbiJson))
+	assert.NotNil(t, err)
+
+	_, err = Generate("token", "Token", []byte(`{"functions": [{"name": "a", "returntype": "Float"}]}`))
+	assert.NotNil(t, err)
+
+	_, err = Generate("token", "Token", []byte(`{"functions": [{"name": "a", "returntype": "Void"}]}`))
+	assert.Nil(t, err)
+}
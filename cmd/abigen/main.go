@@ -0,0 +1,55 @@
+// abigen generates typed go bindings from the .abi.json file of a neo 2.x contract.
+//
+// Usage:
+//
+//	abigen -abi token.abi.json -pkg token -type Token -out token.go
+//
+// Each method gets a Call variant which runs through InvokeScript without sending a transaction,
+// and an Invoke variant which sends a transaction signed with wallet.WalletHelper.
+// Each event gets a struct and a decoder for the notifications in the application log.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func main() {
+	abiPath := flag.String("abi", "", "path of the .abi.json file")
+	pkg := flag.String("pkg", "main", "package name of the generated file")
+	typeName := flag.String("type", "", "go type name of the contract, defaults to the abi file name")
+	out := flag.String("out", "", "output file, defaults to stdout")
+	flag.Parse()
+
+	if *abiPath == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if *typeName == "" {
+		*typeName = strings.TrimSuffix(strings.TrimSuffix(filepath.Base(*abiPath), ".json"), ".abi")
+	}
+	data, err := ioutil.ReadFile(*abiPath)
+	if err != nil {
+		fail(err)
+	}
+	code, err := Generate(*pkg, *typeName, data)
+	if err != nil {
+		fail(err)
+	}
+	if *out == "" {
+		os.Stdout.Write(code)
+		return
+	}
+	if err = ioutil.WriteFile(*out, code, 0644); err != nil {
+		fail(err)
+	}
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, "abigen:", err)
+	os.Exit(1)
+}
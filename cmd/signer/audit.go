@@ -0,0 +1,57 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+)
+
+// AuditEntry is a line of the audit log, written for every signing request whether it is allowed or not
+type AuditEntry struct {
+	Time   string `json:"time"`
+	Remote string `json:"remote"`
+	Api    string `json:"api"`
+	TxId   string `json:"txid,omitempty"`
+	Type   string `json:"type,omitempty"`
+	// RawTransaction is the unsigned raw transaction in hex
+	RawTransaction string   `json:"raw_transaction,omitempty"`
+	Signers        []string `json:"signers,omitempty"`
+	Allowed        bool     `json:"allowed"`
+	Reason         string   `json:"reason,omitempty"`
+}
+
+// AuditLog appends json lines to a file and syncs each of them
+type AuditLog struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+func OpenAuditLog(path string) (*AuditLog, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, err
+	}
+	return &AuditLog{file: file}, nil
+}
+
+// Write appends the entry, the time is set if it is empty
+func (l *AuditLog) Write(e AuditEntry) error {
+	if e.Time == "" {
+		e.Time = time.Now().UTC().Format(time.RFC3339Nano)
+	}
+	line, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, err = l.file.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	return l.file.Sync()
+}
+
+func (l *AuditLog) Close() error {
+	return l.file.Close()
+}
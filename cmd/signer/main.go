@@ -0,0 +1,136 @@
+// signer is a signing daemon which keeps the keys of a NEP-6 wallet and signs only the transactions
+// passing a policy file.
+//
+// Usage:
+//
+//	NEO_SIGNER_PASSWORD=... signer -wallet wallet.json -policy policy.json -audit audit.log -listen unix:///run/signer.sock
+//
+// If NEO_SIGNER_TOKEN is set, every request must carry it as "Authorization: Bearer <token>", which
+// keys.RemoteSigner sends from its Token. A tcp address which is not a loopback one requires the token.
+// A unix socket is created with mode 0600, only the user of the signer can connect to it.
+// Request bodies are limited to MaxRequestBytes.
+//
+// POST /witnesses with {"raw_transaction":"<unsigned raw transaction>"} returns the witnesses of the accounts
+// in the Script attributes, or of the "accounts" addresses of the request. POST /sign serves keys.RemoteSigner,
+// whose message must be an unsigned raw transaction. Contract, claim and invocation transactions are supported,
+// every request is appended to the audit log as a json line.
+//
+// The policy file is like
+//
+//	{
+//	  "allowed_assets": ["0xc56f33fc6ecfcd0c225c4ab356fee59390af8560be0e930faebe74a6daff7c9b"],
+//	  "max_amounts": {"0xc56f33fc6ecfcd0c225c4ab356fee59390af8560be0e930faebe74a6daff7c9b": "10"},
+//	  "destinations": ["AJntkozhVgbc6irY9hRFtNUvuPZS4YcUyD"],
+//	  "contracts": ["0x9aff1e08aea2048a26a3d2ddbb3df495b932b1e7"],
+//	  "max_gas": "0"
+//	}
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/joeqian10/neo-gogogo/wallet"
+)
+
+const unixScheme = "unix://"
+
+func main() {
+	walletPath := flag.String("wallet", "", "path of the NEP-6 wallet")
+	policyPath := flag.String("policy", "", "path of the policy file")
+	auditPath := flag.String("audit", "signer-audit.log", "path of the audit log")
+	listen := flag.String("listen", "127.0.0.1:10350", "tcp address, or unix:// followed by the socket path")
+	passwordEnv := flag.String("password-env", "NEO_SIGNER_PASSWORD", "environment variable of the wallet password")
+	tokenEnv := flag.String("token-env", "NEO_SIGNER_TOKEN", "environment variable of the bearer token of the requests")
+	flag.Parse()
+
+	if *walletPath == "" || *policyPath == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	policy, err := LoadPolicy(*policyPath)
+	if err != nil {
+		fail(err)
+	}
+	w, err := wallet.NewWalletFromFile(*walletPath)
+	if err != nil {
+		fail(err)
+	}
+	if err = w.DecryptAll(os.Getenv(*passwordEnv)); err != nil {
+		fail(err)
+	}
+	_ = os.Unsetenv(*passwordEnv)
+	token := os.Getenv(*tokenEnv)
+	_ = os.Unsetenv(*tokenEnv)
+	audit, err := OpenAuditLog(*auditPath)
+	if err != nil {
+		fail(err)
+	}
+	server, err := NewServer(w, policy, audit)
+	if err != nil {
+		fail(err)
+	}
+	server.Token = token
+
+	var listener net.Listener
+	if strings.HasPrefix(*listen, unixScheme) {
+		listener, err = listenUnix(strings.TrimPrefix(*listen, unixScheme))
+	} else if token == "" && !isLoopback(*listen) {
+		err = fmt.Errorf("%s is not a loopback address, set %s to require a token", *listen, *tokenEnv)
+	} else {
+		listener, err = net.Listen("tcp", *listen)
+	}
+	if err != nil {
+		fail(err)
+	}
+	fmt.Fprintln(os.Stderr, "signer: listening on", *listen)
+	fail(http.Serve(listener, server.Handler()))
+}
+
+// listenUnix creates the socket in a new directory of mode 0700, so no other user can connect to it before
+// it is restricted to 0600, then moves it to the path
+func listenUnix(path string) (net.Listener, error) {
+	dir, err := os.MkdirTemp(filepath.Dir(path), ".signer")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+	temp := filepath.Join(dir, "signer.sock")
+	listener, err := net.Listen("unix", temp)
+	if err != nil {
+		return nil, err
+	}
+	// the socket is moved, closing the listener must not remove another file at the temporary path
+	listener.(*net.UnixListener).SetUnlinkOnClose(false)
+	if err = os.Chmod(temp, 0600); err == nil {
+		err = os.Rename(temp, path)
+	}
+	if err != nil {
+		listener.Close()
+		return nil, err
+	}
+	return listener, nil
+}
+
+// isLoopback reports whether the tcp address only accepts local connections
+func isLoopback(address string) bool {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, "signer:", err)
+	os.Exit(1)
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsLoopback(t *testing.T) {
+	assert.True(t, isLoopback("127.0.0.1:10350"))
+	assert.True(t, isLoopback("[::1]:10350"))
+	assert.True(t, isLoopback("localhost:10350"))
+	assert.False(t, isLoopback(":10350"))
+	assert.False(t, isLoopback("0.0.0.0:10350"))
+	assert.False(t, isLoopback("10.0.0.1:10350"))
+	assert.False(t, isLoopback("127.0.0.1"))
+}
+
+func TestListenUnix(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "signer.sock")
+	listener, err := listenUnix(path)
+	assert.Nil(t, err)
+	defer listener.Close()
+
+	info, err := os.Stat(path)
+	assert.Nil(t, err)
+	assert.Equal(t, os.ModeSocket, info.Mode()&os.ModeSocket)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	// the private directory of the socket is removed
+	entries, _ := os.ReadDir(dir)
+	assert.Equal(t, 1, len(entries))
+
+	go func() {
+		conn, err := listener.Accept()
+		if err == nil {
+			conn.Close()
+		}
+	}()
+	conn, err := net.Dial("unix", path)
+	assert.Nil(t, err)
+	if conn != nil {
+		conn.Close()
+	}
+}
@@ -0,0 +1,290 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"strings"
+
+	"github.com/joeqian10/neo-gogogo/helper"
+	"github.com/joeqian10/neo-gogogo/sc"
+	"github.com/joeqian10/neo-gogogo/tx"
+)
+
+// PolicyFile is the json policy file, hashes are hex strings with or without 0x in big endian
+type PolicyFile struct {
+	// AllowedAssets are the utxo assets the outputs can send, e.g. the NEO and GAS asset ids
+	AllowedAssets []string `json:"allowed_assets"`
+	// MaxAmounts limits each transfer to a destination, keyed by the utxo asset id
+	// or the NEP-5 contract hash. Utxo amounts are decimals like "1.5", NEP-5 amounts are in the smallest unit.
+	// An asset without a limit can send any amount.
+	MaxAmounts map[string]string `json:"max_amounts"`
+	// Destinations are the addresses or script hashes which can receive besides the accounts the daemon signs for
+	Destinations []string `json:"destinations"`
+	// Contracts are the contracts an invocation can call
+	Contracts []string `json:"contracts"`
+	// MaxGas is the max system fee of an invocation, empty means no system fee
+	MaxGas string `json:"max_gas"`
+}
+
+// Policy is the parsed PolicyFile
+type Policy struct {
+	assets       map[helper.UInt256]bool
+	maxAmounts   map[helper.UInt256]helper.Fixed8
+	maxNep5      map[helper.UInt160]*big.Int
+	destinations map[helper.UInt160]bool
+	contracts    map[helper.UInt160]bool
+	maxGas       helper.Fixed8
+}
+
+// LoadPolicy reads and parses the policy file
+func LoadPolicy(path string) (*Policy, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var f PolicyFile
+	if err = json.Unmarshal(data, &f); err != nil {
+		return nil, err
+	}
+	return NewPolicy(f)
+}
+
+// NewPolicy parses the policy file
+func NewPolicy(f PolicyFile) (*Policy, error) {
+	p := &Policy{
+		assets:       make(map[helper.UInt256]bool),
+		maxAmounts:   make(map[helper.UInt256]helper.Fixed8),
+		maxNep5:      make(map[helper.UInt160]*big.Int),
+		destinations: make(map[helper.UInt160]bool),
+		contracts:    make(map[helper.UInt160]bool),
+	}
+	for _, s := range f.AllowedAssets {
+		asset, err := helper.UInt256FromString(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid allowed asset %s", s)
+		}
+		p.assets[asset] = true
+	}
+	for s, amount := range f.MaxAmounts {
+		if len(strings.TrimPrefix(s, "0x")) == 40 {
+			contract, err := helper.UInt160FromString(s)
+			if err != nil {
+				return nil, fmt.Errorf("invalid max amount contract %s", s)
+			}
+			max, ok := new(big.Int).SetString(amount, 10)
+			if !ok {
+				return nil, fmt.Errorf("invalid max amount %s of %s", amount, s)
+			}
+			p.maxNep5[contract] = max
+			continue
+		}
+		asset, err := helper.UInt256FromString(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid max amount asset %s", s)
+		}
+		max, err := helper.Fixed8FromString(amount)
+		if err != nil {
+			return nil, fmt.Errorf("invalid max amount %s of %s", amount, s)
+		}
+		p.maxAmounts[asset] = max
+	}
+	for _, s := range f.Destinations {
+		hash, err := parseScriptHash(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid destination %s", s)
+		}
+		p.destinations[hash] = true
+	}
+	for _, s := range f.Contracts {
+		hash, err := helper.UInt160FromString(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid contract %s", s)
+		}
+		p.contracts[hash] = true
+	}
+	if f.MaxGas != "" {
+		max, err := helper.Fixed8FromString(f.MaxGas)
+		if err != nil {
+			return nil, fmt.Errorf("invalid max gas %s", f.MaxGas)
+		}
+		p.maxGas = max
+	}
+	return p, nil
+}
+
+// parseScriptHash parses an address or a script hash
+func parseScriptHash(s string) (helper.UInt160, error) {
+	if hash, err := helper.AddressToScriptHash(s); err == nil {
+		return hash, nil
+	}
+	return helper.UInt160FromString(s)
+}
+
+// Check returns the reason why the transaction violates the policy, own are the script hashes of the accounts
+// which can sign, they can receive any amount
+func (p *Policy) Check(t tx.ITransaction, own map[helper.UInt160]bool) error {
+	base := t.GetTransaction()
+	switch base.Type {
+	case tx.Contract_Transaction, tx.Claim_Transaction, tx.Invocation_Transaction:
+	default:
+		return fmt.Errorf("%s is not allowed", base.Type)
+	}
+	for i, output := range base.Outputs {
+		if !p.assets[output.AssetId] {
+			return fmt.Errorf("output %d sends the asset %s which is not allowed", i, output.AssetId.String())
+		}
+		if own[output.ScriptHash] {
+			continue
+		}
+		if !p.destinations[output.ScriptHash] {
+			return fmt.Errorf("output %d sends to %s which is not allowed", i, helper.ScriptHashToAddress(output.ScriptHash))
+		}
+		if max, ok := p.maxAmounts[output.AssetId]; ok && output.Value.GreaterThan(max) {
+			return fmt.Errorf("output %d sends %s of %s, more than %s", i, output.Value.String(), output.AssetId.String(), max.String())
+		}
+	}
+	if itx, ok := t.(*tx.InvocationTransaction); ok {
+		if itx.Gas.GreaterThan(p.maxGas) {
+			return fmt.Errorf("system fee %s is more than %s", itx.Gas.String(), p.maxGas.String())
+		}
+		return p.checkScript(itx.Script, own)
+	}
+	return nil
+}
+
+// checkScript checks the contracts called by the invocation script, the method and the arguments of each call
+// must be plain pushes in the form of ScriptBuilder.MakeInvocationScript, so that the NEP-5 transfers can be checked
+func (p *Policy) checkScript(script []byte, own map[helper.UInt160]bool) error {
+	instructions, err := sc.Disassemble(script)
+	if err != nil {
+		return err
+	}
+	for i, ins := range instructions {
+		switch ins.OpCode {
+		case sc.CALL_ED, sc.CALL_EDT:
+			return fmt.Errorf("dynamic call at offset %d is not allowed", ins.Offset)
+		case sc.JMP, sc.JMPIF, sc.JMPIFNOT, sc.CALL, sc.CALL_I:
+			// a jump into the arguments of a call would change what the contract receives
+			return fmt.Errorf("%s at offset %d is not allowed", ins.OpCode, ins.Offset)
+		case sc.APPCALL, sc.TAILCALL, sc.CALL_E, sc.CALL_ET:
+		default:
+			continue
+		}
+		contract, _ := helper.UInt160FromBytes(ins.Operand[len(ins.Operand)-20:])
+		if contract == (helper.UInt160{}) {
+			return fmt.Errorf("dynamic call at offset %d is not allowed", ins.Offset)
+		}
+		if !p.contracts[contract] {
+			return fmt.Errorf("call to the contract %s is not allowed", contract.String())
+		}
+		// CALL_E and CALL_ET pass the method and the arguments only
+		if (ins.OpCode == sc.CALL_E || ins.OpCode == sc.CALL_ET) && ins.Operand[1] != 2 {
+			return fmt.Errorf("the call to %s at offset %d cannot be checked", contract.String(), ins.Offset)
+		}
+		method, args, ok := callArguments(instructions[:i])
+		if !ok {
+			return fmt.Errorf("the call to %s at offset %d cannot be checked", contract.String(), ins.Offset)
+		}
+		if err = p.checkNep5Call(contract, string(method), args, own); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// callArguments returns the method and the arguments pushed right before the call,
+// as args..., PUSHn, PACK, method or PUSH0, method for no arguments
+func callArguments(instructions []sc.Instruction) (method []byte, args []sc.Instruction, ok bool) {
+	l := len(instructions)
+	if l < 2 {
+		return nil, nil, false
+	}
+	method, ok = pushedBytes(instructions[l-1])
+	if !ok {
+		return nil, nil, false
+	}
+	if instructions[l-2].OpCode == sc.PUSH0 {
+		return method, nil, true
+	}
+	if l < 3 || instructions[l-2].OpCode != sc.PACK {
+		return nil, nil, false
+	}
+	n, ok := pushedInteger(instructions[l-3])
+	if !ok || !n.IsInt64() || n.Int64() < 0 || n.Int64() > int64(l-3) {
+		return nil, nil, false
+	}
+	// the first argument is pushed last
+	args = make([]sc.Instruction, n.Int64())
+	for k := range args {
+		args[k] = instructions[l-4-k]
+		if _, isBytes := pushedBytes(args[k]); !isBytes {
+			if _, isInteger := pushedInteger(args[k]); !isInteger {
+				return nil, nil, false
+			}
+		}
+	}
+	return method, args, true
+}
+
+// checkNep5Call checks the destination and the amount of transfer(from, to, amount),
+// approve(owner, spender, amount) and transferFrom(spender, from, to, amount)
+func (p *Policy) checkNep5Call(contract helper.UInt160, method string, args []sc.Instruction, own map[helper.UInt160]bool) error {
+	var count, destination int
+	switch method {
+	case "transfer":
+		count, destination = 3, 1
+	case "approve":
+		count, destination = 3, 1
+	case "transferFrom":
+		count, destination = 4, 2
+	default:
+		return nil
+	}
+	if len(args) != count {
+		return fmt.Errorf("the arguments of the %s of %s cannot be checked", method, contract.String())
+	}
+	to, ok1 := pushedBytes(args[destination])
+	amount, ok2 := pushedInteger(args[count-1])
+	if !ok1 || !ok2 || len(to) != 20 {
+		return fmt.Errorf("the arguments of the %s of %s cannot be checked", method, contract.String())
+	}
+	hash, _ := helper.UInt160FromBytes(to)
+	if own[hash] {
+		return nil
+	}
+	if !p.destinations[hash] {
+		return fmt.Errorf("%s of %s to %s is not allowed", method, contract.String(), helper.ScriptHashToAddress(hash))
+	}
+	if max, ok := p.maxNep5[contract]; ok && amount.Cmp(max) > 0 {
+		return fmt.Errorf("%s of %s sends %s, more than %s", method, contract.String(), amount.String(), max.String())
+	}
+	return nil
+}
+
+// pushedBytes returns the data pushed by PUSHBYTES or PUSHDATA
+func pushedBytes(ins sc.Instruction) ([]byte, bool) {
+	if ins.OpCode == sc.PUSH0 {
+		return []byte{}, true
+	}
+	if ins.OpCode >= sc.PUSHBYTES1 && ins.OpCode <= sc.PUSHDATA4 {
+		return ins.Operand, true
+	}
+	return nil, false
+}
+
+// pushedInteger returns the integer pushed by the instruction
+func pushedInteger(ins sc.Instruction) (*big.Int, bool) {
+	switch {
+	case ins.OpCode == sc.PUSHM1:
+		return big.NewInt(-1), true
+	case ins.OpCode >= sc.PUSH1 && ins.OpCode <= sc.PUSH16:
+		return big.NewInt(int64(ins.OpCode-sc.PUSH1) + 1), true
+	}
+	data, ok := pushedBytes(ins)
+	if !ok {
+		return nil, false
+	}
+	return helper.BigIntFromNeoBytes(data), true
+}
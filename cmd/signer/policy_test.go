@@ -0,0 +1,152 @@
+package main
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/joeqian10/neo-gogogo/helper"
+	"github.com/joeqian10/neo-gogogo/sc"
+	"github.com/joeqian10/neo-gogogo/tx"
+)
+
+const (
+	testToken = "0x9aff1e08aea2048a26a3d2ddbb3df495b932b1e7"
+	testOwn   = "AJntkozhVgbc6irY9hRFtNUvuPZS4YcUyD"
+	testTo    = "AHbwJGdhUy3d1BwhXQKc1VrNojjBTX6g87"
+)
+
+func newTestPolicy(t *testing.T) (*Policy, map[helper.UInt160]bool) {
+	p, err := NewPolicy(PolicyFile{
+		AllowedAssets: []string{tx.NeoTokenId},
+		MaxAmounts:    map[string]string{tx.NeoTokenId: "10", testToken: "1000"},
+		Destinations:  []string{testTo},
+		Contracts:     []string{testToken},
+	})
+	assert.Nil(t, err)
+	own, _ := helper.AddressToScriptHash(testOwn)
+	return p, map[helper.UInt160]bool{own: true}
+}
+
+func TestPolicy_Check_Outputs(t *testing.T) {
+	p, own := newTestPolicy(t)
+	ownHash, _ := helper.AddressToScriptHash(testOwn)
+	to, _ := helper.AddressToScriptHash(testTo)
+	other, _ := helper.UInt160FromString(testToken)
+
+	ctx := tx.NewContractTransaction()
+	ctx.Outputs = []*tx.TransactionOutput{
+		tx.NewTransactionOutput(tx.NeoToken, helper.Fixed8FromInt64(10), to),
+		tx.NewTransactionOutput(tx.NeoToken, helper.Fixed8FromInt64(100), ownHash),
+	}
+	assert.Nil(t, p.Check(ctx, own))
+
+	ctx.Outputs[0].Value = helper.Fixed8FromInt64(11)
+	assert.EqualError(t, p.Check(ctx, own), "output 0 sends 11 of "+tx.NeoTokenId+", more than 10")
+
+	ctx.Outputs[0] = tx.NewTransactionOutput(tx.NeoToken, helper.Fixed8FromInt64(1), other)
+	assert.EqualError(t, p.Check(ctx, own), "output 0 sends to "+helper.ScriptHashToAddress(other)+" which is not allowed")
+
+	ctx.Outputs[0] = tx.NewTransactionOutput(tx.GasToken, helper.Fixed8FromInt64(1), ownHash)
+	assert.EqualError(t, p.Check(ctx, own), "output 0 sends the asset "+tx.GasTokenId+" which is not allowed")
+
+	ctx.Type = tx.Issue_Transaction
+	assert.EqualError(t, p.Check(ctx, own), "IssueTransaction is not allowed")
+}
+
+func TestPolicy_Check_Invocation(t *testing.T) {
+	p, own := newTestPolicy(t)
+	token, _ := helper.UInt160FromString(testToken)
+	from, _ := helper.AddressToScriptHash(testOwn)
+	to, _ := helper.AddressToScriptHash(testTo)
+	transfer := func(contract helper.UInt160, amount int64) *tx.InvocationTransaction {
+		sb := sc.NewScriptBuilder()
+		_ = sb.MakeInvocationScript(contract.Bytes(), "transfer", []sc.ContractParameter{
+			{Type: sc.Hash160, Value: from.Bytes()},
+			{Type: sc.Hash160, Value: to.Bytes()},
+			{Type: sc.Integer, Value: *big.NewInt(amount)},
+		})
+		return tx.NewInvocationTransaction(sb.ToArray())
+	}
+
+	assert.Nil(t, p.Check(transfer(token, 1000), own))
+	assert.EqualError(t, p.Check(transfer(token, 1001), own), "transfer of "+token.String()+" sends 1001, more than 1000")
+
+	other, _ := helper.UInt160FromString("0x0000000000000000000000000000000000000001")
+	assert.EqualError(t, p.Check(transfer(other, 1), own), "call to the contract "+other.String()+" is not allowed")
+
+	itx := transfer(token, 1)
+	itx.Gas = helper.Fixed8FromInt64(1)
+	assert.EqualError(t, p.Check(itx, own), "system fee 1 is more than 0")
+
+	// arguments built at runtime cannot be checked
+	sb := sc.NewScriptBuilder()
+	_ = sb.EmitPushBytes(from.Bytes())
+	_ = sb.Emit(sc.DUP)
+	_ = sb.EmitPushInt(1)
+	_ = sb.Emit(sc.PUSH3)
+	_ = sb.Emit(sc.PACK)
+	_ = sb.EmitPushString("transfer")
+	_ = sb.EmitAppCall(token.Bytes(), false)
+	assert.EqualError(t, p.Check(tx.NewInvocationTransaction(sb.ToArray()), own), "the call to "+token.String()+" at offset 34 cannot be checked")
+
+	call := func(method string, args ...sc.ContractParameter) *tx.InvocationTransaction {
+		sb := sc.NewScriptBuilder()
+		_ = sb.MakeInvocationScript(token.Bytes(), method, args)
+		return tx.NewInvocationTransaction(sb.ToArray())
+	}
+	hash160 := func(h helper.UInt160) sc.ContractParameter {
+		return sc.ContractParameter{Type: sc.Hash160, Value: h.Bytes()}
+	}
+	integer := func(i int64) sc.ContractParameter {
+		return sc.ContractParameter{Type: sc.Integer, Value: *big.NewInt(i)}
+	}
+
+	// a NOP between the method and the call
+	script := transfer(token, 1000000).Script
+	script = append(append(append([]byte{}, script[:len(script)-21]...), byte(sc.NOP)), script[len(script)-21:]...)
+	assert.EqualError(t, p.Check(tx.NewInvocationTransaction(script), own), fmt.Sprintf("the call to %s at offset %d cannot be checked", token.String(), len(script)-21))
+
+	// the method name built at runtime
+	sb = sc.NewScriptBuilder()
+	_ = sb.EmitPushBytes(helper.BigIntToNeoBytes(big.NewInt(1000000)))
+	_ = sb.EmitPushBytes(token.Bytes())
+	_ = sb.EmitPushBytes(from.Bytes())
+	_ = sb.Emit(sc.PUSH3)
+	_ = sb.Emit(sc.PACK)
+	_ = sb.EmitPushString("trans")
+	_ = sb.EmitPushString("fer")
+	_ = sb.Emit(sc.CAT)
+	_ = sb.EmitAppCall(token.Bytes(), false)
+	script = sb.ToArray()
+	assert.EqualError(t, p.Check(tx.NewInvocationTransaction(script), own), fmt.Sprintf("the call to %s at offset %d cannot be checked", token.String(), len(script)-21))
+
+	// a jump into the arguments
+	sb = sc.NewScriptBuilder()
+	_ = sb.EmitJump(sc.JMP, 3)
+	itx = tx.NewInvocationTransaction(append(sb.ToArray(), transfer(token, 1).Script...))
+	assert.EqualError(t, p.Check(itx, own), "JMP at offset 0 is not allowed")
+
+	// approve and transferFrom
+	other, _ = helper.UInt160FromString("0x0000000000000000000000000000000000000002")
+	assert.Nil(t, p.Check(call("approve", hash160(from), hash160(to), integer(1000)), own))
+	assert.EqualError(t, p.Check(call("approve", hash160(from), hash160(other), integer(1)), own),
+		"approve of "+token.String()+" to "+helper.ScriptHashToAddress(other)+" is not allowed")
+	assert.EqualError(t, p.Check(call("approve", hash160(from), hash160(to), integer(1001)), own),
+		"approve of "+token.String()+" sends 1001, more than 1000")
+	assert.Nil(t, p.Check(call("transferFrom", hash160(from), hash160(other), hash160(to), integer(1000)), own))
+	assert.EqualError(t, p.Check(call("transferFrom", hash160(from), hash160(to), hash160(other), integer(1)), own),
+		"transferFrom of "+token.String()+" to "+helper.ScriptHashToAddress(other)+" is not allowed")
+	assert.EqualError(t, p.Check(call("transferFrom", hash160(from), hash160(from), hash160(to), integer(1001)), own),
+		"transferFrom of "+token.String()+" sends 1001, more than 1000")
+	assert.EqualError(t, p.Check(call("transferFrom", hash160(from), hash160(to), integer(1)), own),
+		"the arguments of the transferFrom of "+token.String()+" cannot be checked")
+
+	// other methods of an allowed contract
+	assert.Nil(t, p.Check(call("balanceOf", hash160(from)), own))
+	sb = sc.NewScriptBuilder()
+	_ = sb.MakeInvocationScript(token.Bytes(), "name", nil)
+	assert.Nil(t, p.Check(tx.NewInvocationTransaction(sb.ToArray()), own))
+}
@@ -0,0 +1,299 @@
+package main
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/joeqian10/neo-gogogo/helper"
+	"github.com/joeqian10/neo-gogogo/helper/io"
+	"github.com/joeqian10/neo-gogogo/rpc/models"
+	"github.com/joeqian10/neo-gogogo/tx"
+	"github.com/joeqian10/neo-gogogo/wallet"
+	"github.com/joeqian10/neo-gogogo/wallet/keys"
+)
+
+// WitnessesPath is the path of the api which returns the witnesses of an unsigned transaction
+const WitnessesPath = "/witnesses"
+
+// MaxRequestBytes is the max size of a request body, a raw transaction is at most 100 KB, 200 KB in hex
+const MaxRequestBytes = 512 * 1024
+
+// WitnessesRequest is the body posted to WitnessesPath
+type WitnessesRequest struct {
+	// RawTransaction is the unsigned raw transaction in hex
+	RawTransaction string `json:"raw_transaction"`
+	// Accounts are the addresses to sign with, empty for the accounts in the Script attributes
+	Accounts []string `json:"accounts,omitempty"`
+}
+
+// WitnessesResponse is the body returned by WitnessesPath, the status is not 200 when Error is set
+type WitnessesResponse struct {
+	TxId      string              `json:"txid,omitempty"`
+	Witnesses []models.RpcWitness `json:"witnesses,omitempty"`
+	// RawTransaction is the raw transaction with the witnesses, ready to send
+	RawTransaction string `json:"raw_transaction,omitempty"`
+	Error          string `json:"error,omitempty"`
+}
+
+// unsignedTransaction is a transaction decoded from its unsigned raw transaction
+type unsignedTransaction interface {
+	tx.ITransaction
+	DeserializeUnsigned(br *io.BinaryReader)
+	RawTransaction() []byte
+	HashString() string
+}
+
+// Server signs transactions passing the policy with the accounts of the wallet
+type Server struct {
+	// Token is the bearer token the requests must carry in the Authorization header, empty for none
+	Token string
+
+	// accounts which can sign alone, by address
+	accounts map[string]*wallet.Account
+	// script hashes of the accounts which can sign, watch-only accounts are not own
+	own    map[helper.UInt160]bool
+	policy *Policy
+	audit  *AuditLog
+}
+
+// NewServer creates the server, the accounts of the wallet should have been decrypted
+func NewServer(w *wallet.Wallet, policy *Policy, audit *AuditLog) (*Server, error) {
+	s := &Server{
+		accounts: make(map[string]*wallet.Account),
+		own:      make(map[helper.UInt160]bool),
+		policy:   policy,
+		audit:    audit,
+	}
+	for _, acc := range w.Accounts {
+		if !canSign(acc) {
+			continue
+		}
+		hash, err := acc.ScriptHash()
+		if err != nil {
+			return nil, err
+		}
+		s.own[hash] = true
+		s.accounts[acc.Address] = acc
+	}
+	if len(s.accounts) == 0 {
+		return nil, fmt.Errorf("no account of the wallet can sign")
+	}
+	return s, nil
+}
+
+// canSign reports whether the account has a key of its signature contract
+func canSign(acc *wallet.Account) bool {
+	signer := acc.GetSigner()
+	if signer == nil {
+		return false
+	}
+	script, err := acc.VerificationScript()
+	if err != nil {
+		return false
+	}
+	vs, err := keys.ParseVerificationScript(script)
+	return err == nil && vs.Kind == keys.SignatureScript &&
+		bytes.Equal(vs.PublicKeys[0].EncodeCompression(), signer.Public().EncodeCompression())
+}
+
+// Handler serves WitnessesPath, and keys.RemoteSignPath for keys.RemoteSigner
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(WitnessesPath, s.handleWitnesses)
+	mux.HandleFunc(keys.RemoteSignPath, s.handleSign)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !s.authorized(r) {
+			entry := AuditEntry{Remote: r.RemoteAddr, Api: r.URL.Path}
+			s.reply(w, http.StatusUnauthorized, map[string]string{"error": "invalid token"}, entry, nil)
+			return
+		}
+		r.Body = http.MaxBytesReader(w, r.Body, MaxRequestBytes)
+		mux.ServeHTTP(w, r)
+	})
+}
+
+// authorized reports whether the request carries the token, compared in constant time
+func (s *Server) authorized(r *http.Request) bool {
+	if s.Token == "" {
+		return true
+	}
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return subtle.ConstantTimeCompare([]byte(token), []byte(s.Token)) == 1
+}
+
+func (s *Server) handleWitnesses(w http.ResponseWriter, r *http.Request) {
+	entry := AuditEntry{Remote: r.RemoteAddr, Api: WitnessesPath}
+	var request WitnessesRequest
+	if r.Method != http.MethodPost {
+		s.reply(w, http.StatusMethodNotAllowed, WitnessesResponse{Error: "only POST is allowed"}, entry, nil)
+		return
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		s.reply(w, http.StatusBadRequest, WitnessesResponse{Error: err.Error()}, entry, err)
+		return
+	}
+	entry.RawTransaction = request.RawTransaction
+	t, err := decodeUnsignedTransaction(helper.HexToBytes(request.RawTransaction))
+	if err != nil {
+		s.reply(w, http.StatusBadRequest, WitnessesResponse{Error: err.Error()}, entry, err)
+		return
+	}
+	entry.TxId, entry.Type = t.HashString(), t.GetTransaction().Type.String()
+	accounts, err := s.signingAccounts(t, request.Accounts)
+	if err == nil {
+		for _, acc := range accounts {
+			entry.Signers = append(entry.Signers, acc.Address)
+		}
+		err = s.policy.Check(t, s.own)
+	}
+	if err != nil {
+		s.reply(w, http.StatusForbidden, WitnessesResponse{Error: err.Error()}, entry, err)
+		return
+	}
+
+	message := t.UnsignedRawTransaction()
+	witnesses := tx.WitnessSlice{}
+	for _, acc := range accounts {
+		witness, err := tx.CreateSignatureWitness(message, acc.GetSigner())
+		if err != nil {
+			s.reply(w, http.StatusInternalServerError, WitnessesResponse{Error: err.Error()}, entry, err)
+			return
+		}
+		witnesses = append(witnesses, witness)
+	}
+	sort.Sort(witnesses)
+	t.GetTransaction().Witnesses = witnesses
+	response := WitnessesResponse{TxId: entry.TxId, RawTransaction: helper.BytesToHex(t.RawTransaction())}
+	for _, witness := range witnesses {
+		response.Witnesses = append(response.Witnesses, models.RpcWitness{
+			Invocation:   helper.BytesToHex(witness.InvocationScript),
+			Verification: helper.BytesToHex(witness.VerificationScript),
+		})
+	}
+	s.reply(w, http.StatusOK, response, entry, nil)
+}
+
+func (s *Server) handleSign(w http.ResponseWriter, r *http.Request) {
+	entry := AuditEntry{Remote: r.RemoteAddr, Api: keys.RemoteSignPath}
+	var request keys.RemoteSignRequest
+	if r.Method != http.MethodPost {
+		s.reply(w, http.StatusMethodNotAllowed, keys.RemoteSignResponse{Error: "only POST is allowed"}, entry, nil)
+		return
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		s.reply(w, http.StatusBadRequest, keys.RemoteSignResponse{Error: err.Error()}, entry, err)
+		return
+	}
+	entry.RawTransaction = request.Message
+	// the message of a transaction signature is the unsigned raw transaction, which is checked as well
+	message := helper.HexToBytes(request.Message)
+	t, err := decodeUnsignedTransaction(message)
+	if err != nil {
+		s.reply(w, http.StatusBadRequest, keys.RemoteSignResponse{Error: err.Error()}, entry, err)
+		return
+	}
+	entry.TxId, entry.Type = t.HashString(), t.GetTransaction().Type.String()
+	var acc *wallet.Account
+	for _, a := range s.accounts {
+		if helper.BytesToHex(a.GetSigner().Public().EncodeCompression()) == request.PublicKey {
+			acc = a
+			break
+		}
+	}
+	if acc == nil {
+		err = fmt.Errorf("no account of the public key %s", request.PublicKey)
+	} else {
+		entry.Signers = []string{acc.Address}
+		err = s.policy.Check(t, s.own)
+	}
+	if err != nil {
+		s.reply(w, http.StatusForbidden, keys.RemoteSignResponse{Error: err.Error()}, entry, err)
+		return
+	}
+	signature, err := acc.GetSigner().Sign(message)
+	if err != nil {
+		s.reply(w, http.StatusInternalServerError, keys.RemoteSignResponse{Error: err.Error()}, entry, err)
+		return
+	}
+	s.reply(w, http.StatusOK, keys.RemoteSignResponse{Signature: helper.BytesToHex(signature)}, entry, nil)
+}
+
+// reply writes the audit entry before the response, a request is refused if it cannot be audited
+func (s *Server) reply(w http.ResponseWriter, status int, response interface{}, entry AuditEntry, reason error) {
+	entry.Allowed = status == http.StatusOK
+	if reason != nil {
+		entry.Reason = reason.Error()
+	} else if !entry.Allowed {
+		entry.Reason = http.StatusText(status)
+	}
+	if err := s.audit.Write(entry); err != nil {
+		status, response = http.StatusInternalServerError, map[string]string{"error": "audit log failed"}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(response)
+}
+
+// signingAccounts returns the accounts of the addresses, or of the Script attributes if addresses are empty
+func (s *Server) signingAccounts(t tx.ITransaction, addresses []string) ([]*wallet.Account, error) {
+	var accounts []*wallet.Account
+	if len(addresses) == 0 {
+		for _, attr := range t.GetTransaction().Attributes {
+			if attr.Usage != tx.Script {
+				continue
+			}
+			hash, err := helper.UInt160FromBytes(attr.Data)
+			if err != nil {
+				continue
+			}
+			if acc, ok := s.accounts[helper.ScriptHashToAddress(hash)]; ok {
+				accounts = append(accounts, acc)
+			}
+		}
+		if len(accounts) == 0 {
+			return nil, fmt.Errorf("no account of the wallet is in the script attributes")
+		}
+		return accounts, nil
+	}
+	for _, address := range addresses {
+		acc, ok := s.accounts[address]
+		if !ok {
+			return nil, fmt.Errorf("the account %s cannot sign", address)
+		}
+		accounts = append(accounts, acc)
+	}
+	return accounts, nil
+}
+
+// decodeUnsignedTransaction decodes the unsigned raw transaction of the types the policy can check
+func decodeUnsignedTransaction(raw []byte) (unsignedTransaction, error) {
+	if len(raw) == 0 {
+		return nil, fmt.Errorf("empty transaction")
+	}
+	var t unsignedTransaction
+	switch tx.TransactionType(raw[0]) {
+	case tx.Contract_Transaction:
+		t = &tx.ContractTransaction{Transaction: tx.NewTransaction()}
+	case tx.Claim_Transaction:
+		t = &tx.ClaimTransaction{Transaction: tx.NewTransaction()}
+	case tx.Invocation_Transaction:
+		t = &tx.InvocationTransaction{Transaction: tx.NewTransaction()}
+	default:
+		return nil, fmt.Errorf("%s is not supported", tx.TransactionType(raw[0]))
+	}
+	br := io.NewBinaryReaderFromBuf(raw)
+	t.DeserializeUnsigned(br)
+	if br.Err != nil {
+		return nil, br.Err
+	}
+	// signed transactions and trailing data are refused
+	if !bytes.Equal(t.UnsignedRawTransaction(), raw) {
+		return nil, fmt.Errorf("not an unsigned raw transaction")
+	}
+	return t, nil
+}
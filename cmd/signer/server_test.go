@@ -0,0 +1,149 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/joeqian10/neo-gogogo/helper"
+	"github.com/joeqian10/neo-gogogo/tx"
+	"github.com/joeqian10/neo-gogogo/wallet"
+	"github.com/joeqian10/neo-gogogo/wallet/keys"
+)
+
+func newTestServer(t *testing.T) (*httptest.Server, string) {
+	p, _ := newTestPolicy(t)
+	w := wallet.NewWallet()
+	assert.Nil(t, w.ImportFromWIF(keys.KeyCases[0].Wif))
+	assert.Nil(t, w.ImportWatchOnly(testTo))
+	auditPath := filepath.Join(t.TempDir(), "audit.log")
+	audit, err := OpenAuditLog(auditPath)
+	assert.Nil(t, err)
+	t.Cleanup(func() { _ = audit.Close() })
+	s, err := NewServer(w, p, audit)
+	assert.Nil(t, err)
+	return httptest.NewServer(s.Handler()), auditPath
+}
+
+func newTestTransfer(value int64) *tx.ContractTransaction {
+	own, _ := helper.AddressToScriptHash(testOwn)
+	to, _ := helper.AddressToScriptHash(testTo)
+	ctx := tx.NewContractTransaction()
+	ctx.Outputs = []*tx.TransactionOutput{tx.NewTransactionOutput(tx.NeoToken, helper.Fixed8FromInt64(value), to)}
+	ctx.AddScriptHashToAttribute(own)
+	return ctx
+}
+
+func postWitnesses(t *testing.T, url string, request WitnessesRequest) (int, WitnessesResponse) {
+	body, _ := json.Marshal(request)
+	res, err := http.Post(url+WitnessesPath, "application/json", bytes.NewReader(body))
+	assert.Nil(t, err)
+	defer res.Body.Close()
+	var response WitnessesResponse
+	assert.Nil(t, json.NewDecoder(res.Body).Decode(&response))
+	return res.StatusCode, response
+}
+
+func TestServer_Witnesses(t *testing.T) {
+	server, auditPath := newTestServer(t)
+	defer server.Close()
+
+	ctx := newTestTransfer(5)
+	status, response := postWitnesses(t, server.URL, WitnessesRequest{RawTransaction: helper.BytesToHex(ctx.UnsignedRawTransaction())})
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, ctx.HashString(), response.TxId)
+	assert.Equal(t, 1, len(response.Witnesses))
+	signed, err := (&tx.ContractTransaction{Transaction: tx.NewTransaction()}).FromHexString(response.RawTransaction)
+	assert.Nil(t, err)
+	assert.True(t, tx.VerifySignatureWitness(ctx.UnsignedRawTransaction(), signed.Witnesses[0]))
+
+	// over the max amount
+	ctx = newTestTransfer(11)
+	status, response = postWitnesses(t, server.URL, WitnessesRequest{RawTransaction: helper.BytesToHex(ctx.UnsignedRawTransaction())})
+	assert.Equal(t, http.StatusForbidden, status)
+	assert.Equal(t, "output 0 sends 11 of "+tx.NeoTokenId+", more than 10", response.Error)
+
+	// a watch-only account cannot sign
+	status, response = postWitnesses(t, server.URL, WitnessesRequest{RawTransaction: helper.BytesToHex(ctx.UnsignedRawTransaction()), Accounts: []string{testTo}})
+	assert.Equal(t, http.StatusForbidden, status)
+	assert.Equal(t, "the account "+testTo+" cannot sign", response.Error)
+
+	// signed transactions are refused
+	status, _ = postWitnesses(t, server.URL, WitnessesRequest{RawTransaction: response.RawTransaction + signed.RawTransactionString()})
+	assert.Equal(t, http.StatusBadRequest, status)
+
+	data, _ := ioutil.ReadFile(auditPath)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	assert.Equal(t, 4, len(lines))
+	var entry AuditEntry
+	assert.Nil(t, json.Unmarshal([]byte(lines[0]), &entry))
+	assert.True(t, entry.Allowed)
+	assert.Equal(t, []string{testOwn}, entry.Signers)
+	assert.Nil(t, json.Unmarshal([]byte(lines[1]), &entry))
+	assert.False(t, entry.Allowed)
+	assert.Equal(t, ctx.HashString(), entry.TxId)
+	assert.Equal(t, "ContractTransaction", entry.Type)
+}
+
+func TestServer_RemoteSigner(t *testing.T) {
+	server, _ := newTestServer(t)
+	defer server.Close()
+	pair, _ := keys.NewKeyPairFromWIF(keys.KeyCases[0].Wif)
+	signer := keys.NewRemoteSigner(server.URL, pair.PublicKey, time.Second)
+
+	ctx := newTestTransfer(5)
+	assert.Nil(t, tx.AddSignature(ctx, signer))
+	assert.True(t, tx.VerifySignatureWitness(ctx.UnsignedRawTransaction(), ctx.Witnesses[0]))
+
+	ctx = newTestTransfer(11)
+	err := tx.AddSignature(ctx, signer)
+	assert.EqualError(t, err, "signing daemon refused to sign, status 403: output 0 sends 11 of "+tx.NeoTokenId+", more than 10")
+
+	// only transactions are signed
+	_, err = signer.Sign([]byte("hello"))
+	assert.NotNil(t, err)
+}
+
+func TestServer_Token(t *testing.T) {
+	p, _ := newTestPolicy(t)
+	w := wallet.NewWallet()
+	assert.Nil(t, w.ImportFromWIF(keys.KeyCases[0].Wif))
+	audit, err := OpenAuditLog(filepath.Join(t.TempDir(), "audit.log"))
+	assert.Nil(t, err)
+	defer audit.Close()
+	s, err := NewServer(w, p, audit)
+	assert.Nil(t, err)
+	s.Token = "secret"
+	server := httptest.NewServer(s.Handler())
+	defer server.Close()
+	pair, _ := keys.NewKeyPairFromWIF(keys.KeyCases[0].Wif)
+
+	// without the token
+	ctx := newTestTransfer(5)
+	status, response := postWitnesses(t, server.URL, WitnessesRequest{RawTransaction: helper.BytesToHex(ctx.UnsignedRawTransaction())})
+	assert.Equal(t, http.StatusUnauthorized, status)
+	assert.Equal(t, "invalid token", response.Error)
+	signer := keys.NewRemoteSigner(server.URL, pair.PublicKey, time.Second)
+	signer.Token = "wrong"
+	err = tx.AddSignature(ctx, signer)
+	assert.EqualError(t, err, "signing daemon refused to sign, status 401: invalid token")
+
+	signer.Token = "secret"
+	assert.Nil(t, tx.AddSignature(ctx, signer))
+}
+
+func TestServer_MaxRequestBytes(t *testing.T) {
+	server, _ := newTestServer(t)
+	defer server.Close()
+	status, response := postWitnesses(t, server.URL, WitnessesRequest{RawTransaction: strings.Repeat("00", MaxRequestBytes)})
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, "http: request body too large", response.Error)
+}
@@ -0,0 +1,57 @@
+package contract
+
+import (
+	"fmt"
+
+	"github.com/joeqian10/neo-gogogo/helper"
+	"github.com/joeqian10/neo-gogogo/rpc"
+	"github.com/joeqian10/neo-gogogo/rpc/models"
+	"github.com/joeqian10/neo-gogogo/sc"
+)
+
+// ContractClient makes read-only calls to the contract described by the abi with go values
+type ContractClient struct {
+	Abi      *sc.ContractAbi
+	EndPoint string
+	Client   rpc.IRpcClient
+}
+
+func NewContractClient(abi *sc.ContractAbi, endPoint string) *ContractClient {
+	client := rpc.NewClient(endPoint)
+	if client == nil {
+		return nil
+	}
+	return &ContractClient{
+		Abi:      abi,
+		EndPoint: endPoint,
+		Client:   client,
+	}
+}
+
+// Invoke runs the method with InvokeScript and returns the raw result
+func (c *ContractClient) Invoke(method string, args ...interface{}) (*models.InvokeResult, error) {
+	script, err := c.Abi.MakeInvocationScript(method, args...)
+	if err != nil {
+		return nil, err
+	}
+	response := c.Client.InvokeScript(helper.BytesToHex(script), helper.ZeroScriptHashString)
+	if response.HasError() {
+		return nil, fmt.Errorf(response.ErrorResponse.Error.Message)
+	}
+	if response.Result.State == "FAULT" {
+		return nil, fmt.Errorf("engine faulted")
+	}
+	return &response.Result, nil
+}
+
+// Call runs the method with InvokeScript and decodes the result by the return type declared in the abi
+func (c *ContractClient) Call(method string, args ...interface{}) (interface{}, error) {
+	result, err := c.Invoke(method, args...)
+	if err != nil {
+		return nil, err
+	}
+	if len(result.Stack) == 0 {
+		return nil, fmt.Errorf("no stack result returned")
+	}
+	return c.Abi.DecodeResult(method, result.Stack[0])
+}
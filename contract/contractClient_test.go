@@ -0,0 +1,44 @@
+package contract
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/joeqian10/neo-gogogo/rpc"
+	"github.com/joeqian10/neo-gogogo/rpc/models"
+	"github.com/joeqian10/neo-gogogo/sc"
+)
+
+const abiJson = `{
+	"hash": "0xb9d7ea3062e6aeeb3e8ad9548220c4ba1361d263",
+	"entrypoint": "Main",
+	"functions": [
+		{"name": "name", "parameters": [], "returntype": "String"},
+		{
+			"name": "balanceOf",
+			"parameters": [{"name": "account", "type": "Hash160"}],
+			"returntype": "Integer"
+		}
+	],
+	"events": []
+}`
+
+func TestContractClient_Call(t *testing.T) {
+	var clientMock = new(rpc.RpcClientMock)
+	abi, _ := sc.NewContractAbi([]byte(abiJson))
+	c := &ContractClient{Abi: abi, Client: clientMock}
+	clientMock.On("InvokeScript", mock.Anything, mock.Anything).Return(rpc.InvokeScriptResponse{
+		Result: models.InvokeResult{
+			State: "HALT",
+			Stack: []models.InvokeStack{{Type: "ByteArray", Value: "516c696e6b20546f6b656e"}},
+		},
+	})
+	v, err := c.Call("name")
+	assert.Nil(t, err)
+	assert.Equal(t, "Qlink Token", v)
+
+	_, err = c.Call("balanceOf")
+	assert.NotNil(t, err)
+}
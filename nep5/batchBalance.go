@@ -0,0 +1,130 @@
+package nep5
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/joeqian10/neo-gogogo/helper"
+	"github.com/joeqian10/neo-gogogo/rpc"
+	"github.com/joeqian10/neo-gogogo/sc"
+)
+
+const (
+	// DefaultBalanceGasLimit is the gas free limit of an invocation, in GAS
+	DefaultBalanceGasLimit = 10
+	// DefaultMaxBalanceCalls is the max number of balanceOf calls in one script
+	DefaultMaxBalanceCalls = 256
+)
+
+// BalanceBatchOptions are the options of BatchBalanceOfWithOptions, the zero values are the defaults
+type BalanceBatchOptions struct {
+	// GasLimit is the gas a script can consume, the scripts are sized to stay below it
+	GasLimit helper.Fixed8
+	// MaxCalls is the max number of balanceOf calls in one script
+	MaxCalls int
+}
+
+type balancePair struct {
+	token   helper.UInt160
+	address helper.UInt160
+}
+
+// BatchBalanceOf returns the balances of the addresses for each token, balances[i][j] is the balance of
+// addresses[j] of tokens[i], see BatchBalanceOfWithOptions
+func BatchBalanceOf(client rpc.IRpcClient, tokens []helper.UInt160, addresses []helper.UInt160) ([][]*big.Int, error) {
+	return BatchBalanceOfWithOptions(client, tokens, addresses, BalanceBatchOptions{})
+}
+
+// BatchBalanceOfWithOptions calls balanceOf of all the pairs of tokens and addresses in as few InvokeScript
+// as possible. The calls are split into scripts which are expected to consume less than the gas limit,
+// estimated from the gas consumed by the previous scripts, and a script which faults is retried with half of the calls.
+// A nil balance means the call faulted, e.g. the contract is not a NEP-5 token.
+func BatchBalanceOfWithOptions(client rpc.IRpcClient, tokens []helper.UInt160, addresses []helper.UInt160, options BalanceBatchOptions) ([][]*big.Int, error) {
+	if options.GasLimit.Value <= 0 {
+		options.GasLimit = helper.Fixed8FromInt64(DefaultBalanceGasLimit)
+	}
+	if options.MaxCalls <= 0 {
+		options.MaxCalls = DefaultMaxBalanceCalls
+	}
+	pairs := make([]balancePair, 0, len(tokens)*len(addresses))
+	for _, token := range tokens {
+		for _, address := range addresses {
+			pairs = append(pairs, balancePair{token: token, address: address})
+		}
+	}
+
+	results := make([]*big.Int, 0, len(pairs))
+	size := options.MaxCalls
+	for len(pairs) > 0 {
+		n := len(pairs)
+		if n > size {
+			n = size
+		}
+		balances, gas, err := invokeBalances(client, pairs[:n])
+		if err != nil {
+			return nil, err
+		}
+		if balances == nil {
+			// the script faulted, retry with half of the calls, a single call which faults has no balance
+			if n > 1 {
+				size = n / 2
+				continue
+			}
+			balances = []*big.Int{nil}
+		}
+		results = append(results, balances...)
+		pairs = pairs[n:]
+		// size the next script with the average gas of a call, leaving a margin of 10%
+		if gas.Value > 0 {
+			size = int(options.GasLimit.Value / 10 * 9 * int64(n) / gas.Value)
+			if size < 1 {
+				size = 1
+			} else if size > options.MaxCalls {
+				size = options.MaxCalls
+			}
+		}
+	}
+
+	matrix := make([][]*big.Int, len(tokens))
+	for i := range tokens {
+		matrix[i] = results[i*len(addresses) : (i+1)*len(addresses)]
+	}
+	return matrix, nil
+}
+
+// invokeBalances runs the balanceOf calls in one script, returns the balances in order and the gas consumed,
+// or nil balances if the script faulted
+func invokeBalances(client rpc.IRpcClient, pairs []balancePair) ([]*big.Int, helper.Fixed8, error) {
+	sb := sc.NewScriptBuilder()
+	for _, pair := range pairs {
+		cp := sc.ContractParameter{Type: sc.Hash160, Value: pair.address.Bytes()}
+		if err := sb.MakeInvocationScript(pair.token.Bytes(), "balanceOf", []sc.ContractParameter{cp}); err != nil {
+			return nil, helper.Zero, err
+		}
+	}
+	response := client.InvokeScript(helper.BytesToHex(sb.ToArray()), helper.ZeroScriptHashString)
+	if response.HasError() {
+		return nil, helper.Zero, fmt.Errorf(response.ErrorResponse.Error.Message)
+	}
+	if response.Result.State == "FAULT" {
+		return nil, helper.Zero, nil
+	}
+	// the results are in the order of the calls
+	stack := response.Result.Stack
+	if len(stack) != len(pairs) {
+		return nil, helper.Zero, fmt.Errorf("%d stack results returned for %d calls", len(stack), len(pairs))
+	}
+	balances := make([]*big.Int, len(pairs))
+	for i := range stack {
+		balance, err := stack[i].AsBigInt()
+		if err != nil {
+			return nil, helper.Zero, err
+		}
+		balances[i] = balance
+	}
+	gas, err := helper.Fixed8FromString(response.Result.GasConsumed)
+	if err != nil {
+		return nil, helper.Zero, err
+	}
+	return balances, gas, nil
+}
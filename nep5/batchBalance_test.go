@@ -0,0 +1,102 @@
+package nep5
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/joeqian10/neo-gogogo/helper"
+	"github.com/joeqian10/neo-gogogo/rpc"
+	"github.com/joeqian10/neo-gogogo/rpc/models"
+	"github.com/joeqian10/neo-gogogo/sc"
+)
+
+// balanceClient runs the balanceOf calls of the script, each call consumes 1 GAS, the balance is
+// the first byte of the token times 1000 plus the first byte of the address
+type balanceClient struct {
+	rpc.RpcClientMock
+	tokens   map[helper.UInt160]bool
+	gasLimit int
+	calls    []int
+}
+
+func (c *balanceClient) InvokeScript(script string, checkWitnessHashes string) rpc.InvokeScriptResponse {
+	instructions, _ := sc.Disassemble(helper.HexToBytes(script))
+	var stack []models.InvokeStack
+	fault := false
+	for i, ins := range instructions {
+		if ins.OpCode != sc.APPCALL {
+			continue
+		}
+		token, _ := helper.UInt160FromBytes(ins.Operand)
+		address := instructions[i-4].Operand
+		if !c.tokens[token] || len(stack) == c.gasLimit {
+			fault = true
+			break
+		}
+		balance := big.NewInt(int64(token.Bytes()[0])*1000 + int64(address[0]))
+		stack = append(stack, models.InvokeStack{Type: "ByteArray", Value: helper.BytesToHex(helper.BigIntToNeoBytes(balance))})
+	}
+	c.calls = append(c.calls, len(instructions)/5)
+	if fault {
+		return rpc.InvokeScriptResponse{Result: models.InvokeResult{State: "FAULT"}}
+	}
+	return rpc.InvokeScriptResponse{Result: models.InvokeResult{
+		State:       "HALT",
+		GasConsumed: helper.Fixed8FromInt64(int64(len(stack))).String(),
+		Stack:       stack,
+	}}
+}
+
+func TestBatchBalanceOf(t *testing.T) {
+	tokens := []helper.UInt160{{1}, {2}, {3}}
+	addresses := []helper.UInt160{{10}, {20}}
+	client := &balanceClient{tokens: map[helper.UInt160]bool{{1}: true, {2}: true, {3}: true}, gasLimit: 10}
+
+	balances, err := BatchBalanceOf(client, tokens, addresses)
+	assert.Nil(t, err)
+	assert.Equal(t, [][]*big.Int{
+		{big.NewInt(1010), big.NewInt(1020)},
+		{big.NewInt(2010), big.NewInt(2020)},
+		{big.NewInt(3010), big.NewInt(3020)},
+	}, balances)
+	assert.Equal(t, []int{6}, client.calls)
+}
+
+func TestBatchBalanceOf_GasLimit(t *testing.T) {
+	var tokens []helper.UInt160
+	for i := 1; i <= 10; i++ {
+		tokens = append(tokens, helper.UInt160{byte(i)})
+	}
+	addresses := []helper.UInt160{{10}, {20}, {30}}
+	client := &balanceClient{tokens: map[helper.UInt160]bool{}, gasLimit: 10}
+	for _, token := range tokens {
+		client.tokens[token] = true
+	}
+
+	balances, err := BatchBalanceOfWithOptions(client, tokens, addresses, BalanceBatchOptions{GasLimit: helper.Fixed8FromInt64(10), MaxCalls: 8})
+	assert.Nil(t, err)
+	assert.Equal(t, 10, len(balances))
+	assert.Equal(t, big.NewInt(10030), balances[9][2])
+	// the scripts are capped by MaxCalls
+	assert.Equal(t, []int{8, 8, 8, 6}, client.calls)
+
+	// a script over the limit faults and is retried with half of the calls, then 7 calls consume 7 GAS
+	// and the next scripts have 9 calls to stay below 90% of the limit
+	client.calls = nil
+	balances, err = BatchBalanceOfWithOptions(client, tokens, addresses, BalanceBatchOptions{MaxCalls: 30})
+	assert.Nil(t, err)
+	assert.Equal(t, big.NewInt(5020), balances[4][1])
+	assert.Equal(t, []int{30, 15, 7, 9, 9, 5}, client.calls)
+}
+
+func TestBatchBalanceOf_Fault(t *testing.T) {
+	tokens := []helper.UInt160{{1}, {2}}
+	addresses := []helper.UInt160{{10}, {20}}
+	client := &balanceClient{tokens: map[helper.UInt160]bool{{1}: true}, gasLimit: 10}
+
+	balances, err := BatchBalanceOf(client, tokens, addresses)
+	assert.Nil(t, err)
+	assert.Equal(t, [][]*big.Int{{big.NewInt(1010), big.NewInt(1020)}, {nil, nil}}, balances)
+}
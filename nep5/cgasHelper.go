@@ -62,7 +62,11 @@ func (c *CgasHelper) MintTokens(from *wallet.Account, amount float64) (string, e
 	myTx.Outputs = append(myTx.Outputs, tx.NewTransactionOutput(tx.GasToken, totalPay.Sub(a).Sub(*gas), f)) // send to sender account
 
 	// Fifth, sign
-	err = tx.AddSignature(myTx, from.KeyPair)
+	signer := from.GetSigner()
+	if signer == nil {
+		return "", fmt.Errorf("the account %s has no key to sign", from.Address)
+	}
+	err = tx.AddSignature(myTx, signer)
 	if err != nil {
 		return "", err
 	}
@@ -135,14 +139,18 @@ func (c *CgasHelper) Refund1(from *wallet.Account, txHash helper.UInt256, amount
 
 	// add two witnesses to tx
 	// add the user's signature
-	additionalSignature, err := from.KeyPair.Sign(t.UnsignedRawTransaction())
+	signer := from.GetSigner()
+	if signer == nil {
+		return "", fmt.Errorf("the account %s has no key to sign", from.Address)
+	}
+	additionalSignature, err := signer.Sign(t.UnsignedRawTransaction())
 	if err != nil {
 		return "", err
 	}
 	sb2 := sc.NewScriptBuilder()
 	_ = sb2.EmitPushBytes(additionalSignature)
 	additionalVerificationScript := sb2.ToArray()
-	additionalWitness, err := tx.CreateWitness(additionalVerificationScript, keys.CreateSignatureRedeemScript(from.KeyPair.PublicKey))
+	additionalWitness, err := tx.CreateWitness(additionalVerificationScript, keys.CreateSignatureRedeemScript(signer.Public()))
 	if err != nil {
 		return "", err
 	}
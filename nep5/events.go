@@ -0,0 +1,214 @@
+package nep5
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+
+	"github.com/joeqian10/neo-gogogo/helper"
+	"github.com/joeqian10/neo-gogogo/rpc/models"
+)
+
+const (
+	TransferEventName = "transfer"
+	RefundEventName   = "refund" // CGAS refund event
+)
+
+// Event is a typed notification raised by a contract
+type Event interface {
+	EventName() string
+	EventContract() helper.UInt160
+}
+
+// TransferEvent is the NEP-5 transfer event, From is nil when minting and To is nil when burning
+type TransferEvent struct {
+	Contract helper.UInt160
+	From     *helper.UInt160
+	To       *helper.UInt160
+	Amount   *big.Int
+}
+
+func (e *TransferEvent) EventName() string             { return TransferEventName }
+func (e *TransferEvent) EventContract() helper.UInt160 { return e.Contract }
+
+// RefundEvent is raised by CGAS when a user refunds CGAS to GAS
+type RefundEvent struct {
+	Contract helper.UInt160
+	TxId     helper.UInt256
+	Who      helper.UInt160
+}
+
+func (e *RefundEvent) EventName() string             { return RefundEventName }
+func (e *RefundEvent) EventContract() helper.UInt160 { return e.Contract }
+
+// EventDecoder decodes the arguments of a notification, the event name is excluded
+type EventDecoder func(contract helper.UInt160, args []models.InvokeStack) (Event, error)
+
+// EventRegistry holds event decoders by event name, decoders registered for a
+// specific contract take precedence over the general ones
+type EventRegistry struct {
+	mu        sync.RWMutex
+	decoders  map[string]EventDecoder
+	contracts map[helper.UInt160]map[string]EventDecoder
+}
+
+// NewEventRegistry creates an EventRegistry with the transfer and refund decoders registered
+func NewEventRegistry() *EventRegistry {
+	r := &EventRegistry{
+		decoders:  map[string]EventDecoder{},
+		contracts: map[helper.UInt160]map[string]EventDecoder{},
+	}
+	r.Register(TransferEventName, DecodeTransferEvent)
+	r.Register(RefundEventName, DecodeRefundEvent)
+	return r
+}
+
+// Register registers a decoder for the event name raised by any contract
+func (r *EventRegistry) Register(name string, decoder EventDecoder) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.decoders[strings.ToLower(name)] = decoder
+}
+
+// RegisterForContract registers a decoder for the event name raised by the contract
+func (r *EventRegistry) RegisterForContract(contract helper.UInt160, name string, decoder EventDecoder) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.contracts[contract] == nil {
+		r.contracts[contract] = map[string]EventDecoder{}
+	}
+	r.contracts[contract][strings.ToLower(name)] = decoder
+}
+
+func (r *EventRegistry) decoder(contract helper.UInt160, name string) EventDecoder {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	name = strings.ToLower(name)
+	if ds, ok := r.contracts[contract]; ok {
+		if d, ok := ds[name]; ok {
+			return d
+		}
+	}
+	return r.decoders[name]
+}
+
+// Decode decodes a notification, it returns nil without error if no decoder is registered for the event
+func (r *EventRegistry) Decode(notification models.RpcNotification) (Event, error) {
+	contract, err := helper.UInt160FromString(notification.Contract)
+	if err != nil {
+		return nil, err
+	}
+	items, err := notification.State.AsArray()
+	if err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return nil, fmt.Errorf("notification has no event name")
+	}
+	name, err := items[0].AsString()
+	if err != nil {
+		return nil, err
+	}
+	d := r.decoder(contract, name)
+	if d == nil {
+		return nil, nil
+	}
+	return d(contract, items[1:])
+}
+
+// NotificationError is the error of a notification which fails to decode
+type NotificationError struct {
+	Execution    int // index of the execution in the application log
+	Notification int // index of the notification in the execution
+	Err          error
+}
+
+func (e *NotificationError) Error() string {
+	return fmt.Sprintf("execution %d notification %d: %s", e.Execution, e.Notification, e.Err)
+}
+
+func (e *NotificationError) Unwrap() error { return e.Err }
+
+// DecodeErrors holds the errors of all the notifications which fail to decode in an application log
+type DecodeErrors []*NotificationError
+
+func (e DecodeErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "; ")
+}
+
+// DecodeApplicationLog decodes all the notifications in the application log which have a registered decoder.
+// A notification which fails to decode, such as a "transfer" raised by a contract which is not NEP-5, does
+// not stop the others: the events decoded are always returned, together with DecodeErrors if any failed.
+func (r *EventRegistry) DecodeApplicationLog(log models.RpcApplicationLog) ([]Event, error) {
+	var events []Event
+	var errs DecodeErrors
+	for i, execution := range log.Executions {
+		for j, notification := range execution.Notifications {
+			e, err := r.Decode(notification)
+			if err != nil {
+				errs = append(errs, &NotificationError{Execution: i, Notification: j, Err: err})
+				continue
+			}
+			if e != nil {
+				events = append(events, e)
+			}
+		}
+	}
+	if len(errs) != 0 {
+		return events, errs
+	}
+	return events, nil
+}
+
+// DecodeTransferEvent decodes the arguments of a NEP-5 transfer event
+func DecodeTransferEvent(contract helper.UInt160, args []models.InvokeStack) (Event, error) {
+	if len(args) != 3 {
+		return nil, fmt.Errorf("transfer event expects 3 arguments, got %d", len(args))
+	}
+	from, err := optionalUInt160(args[0])
+	if err != nil {
+		return nil, err
+	}
+	to, err := optionalUInt160(args[1])
+	if err != nil {
+		return nil, err
+	}
+	amount, err := args[2].AsBigInt()
+	if err != nil {
+		return nil, err
+	}
+	return &TransferEvent{Contract: contract, From: from, To: to, Amount: amount}, nil
+}
+
+// DecodeRefundEvent decodes the arguments of a CGAS refund event
+func DecodeRefundEvent(contract helper.UInt160, args []models.InvokeStack) (Event, error) {
+	if len(args) != 2 {
+		return nil, fmt.Errorf("refund event expects 2 arguments, got %d", len(args))
+	}
+	txId, err := args[0].AsUInt256()
+	if err != nil {
+		return nil, err
+	}
+	who, err := args[1].AsUInt160()
+	if err != nil {
+		return nil, err
+	}
+	return &RefundEvent{Contract: contract, TxId: txId, Who: who}, nil
+}
+
+// from and to are null when minting or burning
+func optionalUInt160(s models.InvokeStack) (*helper.UInt160, error) {
+	if s.IsNull() {
+		return nil, nil
+	}
+	u, err := s.AsUInt160()
+	if err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
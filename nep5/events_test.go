@@ -0,0 +1,168 @@
+package nep5
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/joeqian10/neo-gogogo/helper"
+	"github.com/joeqian10/neo-gogogo/rpc/models"
+)
+
+const applicationLogJson = `{
+	"txid": "0xca159430e3d72227c06a3880244111aea0368ecc09fa8c2eade001a1bbcc7d4a",
+	"executions": [
+		{
+			"trigger": "Application",
+			"contract": "0x003bd113b3bc841657f3a84db8546daa6e4953c3",
+			"vmstate": "HALT",
+			"gas_consumed": "2.855",
+			"stack": [],
+			"notifications": [
+				{
+					"contract": "0xb9d7ea3062e6aeeb3e8ad9548220c4ba1361d263",
+					"state": {
+						"type": "Array",
+						"value": [
+							{"type": "ByteArray", "value": "7472616e73666572"},
+							{"type": "ByteArray", "value": "5c564ab204122ddce30eb9a6accbfa23b27cc3ac"},
+							{"type": "ByteArray", "value": "8f6c5be89c0cb6579e44a8bf9bfd2ecbcc11dfdc"},
+							{"type": "ByteArray", "value": "00203d88792d"}
+						]
+					}
+				},
+				{
+					"contract": "0xb9d7ea3062e6aeeb3e8ad9548220c4ba1361d263",
+					"state": {
+						"type": "Array",
+						"value": [
+							{"type": "ByteArray", "value": "7472616e73666572"},
+							{"type": "ByteArray", "value": ""},
+							{"type": "ByteArray", "value": "8f6c5be89c0cb6579e44a8bf9bfd2ecbcc11dfdc"},
+							{"type": "Integer", "value": "100"}
+						]
+					}
+				},
+				{
+					"contract": "0x74f2dc36a68fdc4682034178eb2220729231db76",
+					"state": {
+						"type": "Array",
+						"value": [
+							{"type": "ByteArray", "value": "726566756e64"},
+							{"type": "ByteArray", "value": "4a7dccbba101e0ad2e8cfa09cc8e36a0ae11412480386ac02722d7e3309415ca"},
+							{"type": "ByteArray", "value": "5c564ab204122ddce30eb9a6accbfa23b27cc3ac"}
+						]
+					}
+				},
+				{
+					"contract": "0x74f2dc36a68fdc4682034178eb2220729231db76",
+					"state": {
+						"type": "Array",
+						"value": [
+							{"type": "ByteArray", "value": "756e6b6e6f776e"}
+						]
+					}
+				}
+			]
+		}
+	]
+}`
+
+func TestEventRegistry_DecodeApplicationLog(t *testing.T) {
+	var log models.RpcApplicationLog
+	err := json.Unmarshal([]byte(applicationLogJson), &log)
+	assert.Nil(t, err)
+
+	events, err := NewEventRegistry().DecodeApplicationLog(log)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(events))
+
+	transfer := events[0].(*TransferEvent)
+	assert.Equal(t, "b9d7ea3062e6aeeb3e8ad9548220c4ba1361d263", transfer.Contract.String())
+	assert.Equal(t, "acc37cb223facbaca6b90ee3dc2d1204b24a565c", transfer.From.String())
+	assert.Equal(t, "dcdf11cccb2efd9bbfa8449e57b60c9ce85b6c8f", transfer.To.String())
+	assert.Equal(t, big.NewInt(50000000000000), transfer.Amount)
+
+	mint := events[1].(*TransferEvent)
+	assert.Nil(t, mint.From)
+	assert.Equal(t, big.NewInt(100), mint.Amount)
+
+	refund := events[2].(*RefundEvent)
+	assert.Equal(t, RefundEventName, refund.EventName())
+	assert.Equal(t, "ca159430e3d72227c06a3880244111aea0368ecc09fa8c2eade001a1bbcc7d4a", refund.TxId.String())
+	assert.Equal(t, "acc37cb223facbaca6b90ee3dc2d1204b24a565c", refund.Who.String())
+}
+
+type unknownEvent struct {
+	contract helper.UInt160
+}
+
+func (e *unknownEvent) EventName() string             { return "unknown" }
+func (e *unknownEvent) EventContract() helper.UInt160 { return e.contract }
+
+func TestEventRegistry_RegisterForContract(t *testing.T) {
+	var log models.RpcApplicationLog
+	err := json.Unmarshal([]byte(applicationLogJson), &log)
+	assert.Nil(t, err)
+
+	contract, _ := helper.UInt160FromString("0x74f2dc36a68fdc4682034178eb2220729231db76")
+	r := NewEventRegistry()
+	r.RegisterForContract(contract, "unknown", func(contract helper.UInt160, args []models.InvokeStack) (Event, error) {
+		return &unknownEvent{contract: contract}, nil
+	})
+	events, err := r.DecodeApplicationLog(log)
+	assert.Nil(t, err)
+	assert.Equal(t, 4, len(events))
+	assert.Equal(t, "unknown", events[3].EventName())
+	assert.Equal(t, contract, events[3].EventContract())
+}
+
+func TestEventRegistry_DecodeApplicationLog_Malformed(t *testing.T) {
+	var log models.RpcApplicationLog
+	err := json.Unmarshal([]byte(applicationLogJson), &log)
+	assert.Nil(t, err)
+	notifications := log.Executions[0].Notifications
+	malformed := []models.RpcNotification{
+		// a transfer with other arguments raised by a contract which is not NEP-5
+		{
+			Contract: "0x003bd113b3bc841657f3a84db8546daa6e4953c3",
+			State: models.InvokeStack{Type: "Array", Value: []interface{}{
+				map[string]interface{}{"type": "ByteArray", "value": "7472616e73666572"},
+				map[string]interface{}{"type": "Integer", "value": "1"},
+			}},
+		},
+		// a state which is not an array
+		{
+			Contract: "0x003bd113b3bc841657f3a84db8546daa6e4953c3",
+			State:    models.InvokeStack{Type: "ByteArray", Value: "7472616e73666572"},
+		},
+	}
+	log.Executions[0].Notifications = append([]models.RpcNotification{notifications[0], malformed[0]},
+		append(notifications[1:], malformed[1])...)
+
+	events, err := NewEventRegistry().DecodeApplicationLog(log)
+	assert.Equal(t, 3, len(events))
+	assert.Equal(t, TransferEventName, events[0].EventName())
+	assert.Equal(t, TransferEventName, events[1].EventName())
+	assert.Equal(t, RefundEventName, events[2].EventName())
+
+	errs, ok := err.(DecodeErrors)
+	assert.True(t, ok)
+	assert.Equal(t, 2, len(errs))
+	assert.Equal(t, 1, errs[0].Notification)
+	assert.Equal(t, 5, errs[1].Notification)
+}
+
+func TestDecodeTransferEvent(t *testing.T) {
+	_, err := DecodeTransferEvent(helper.UInt160{}, []models.InvokeStack{{Type: "ByteArray", Value: ""}})
+	assert.NotNil(t, err)
+
+	_, err = DecodeTransferEvent(helper.UInt160{}, []models.InvokeStack{
+		{Type: "ByteArray", Value: "5c564ab204122ddce30eb9a6accbfa23b27cc3ac"},
+		{Type: "ByteArray", Value: "8f6c5be89c0cb6579e44a8bf9bfd2ecbcc11dfdc"},
+		{Type: "Array", Value: []interface{}{}},
+	})
+	assert.NotNil(t, err)
+}
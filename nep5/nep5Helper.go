@@ -1,13 +1,17 @@
 package nep5
 
 import (
-	"encoding/binary"
 	"fmt"
-	"strconv"
+	"math/big"
+	"strings"
+	"sync"
 
 	"github.com/joeqian10/neo-gogogo/helper"
 	"github.com/joeqian10/neo-gogogo/rpc"
+	"github.com/joeqian10/neo-gogogo/rpc/models"
 	"github.com/joeqian10/neo-gogogo/sc"
+	"github.com/joeqian10/neo-gogogo/tx"
+	"github.com/joeqian10/neo-gogogo/wallet"
 )
 
 // Nep5Helper wrapper class, api reference: https://github.com/neo-project/proposals/blob/master/nep-5.mediawiki#name
@@ -15,6 +19,11 @@ type Nep5Helper struct {
 	scriptHash helper.UInt160 // the script hash of the nep5 token
 	EndPoint   string
 	Client     rpc.IRpcClient
+
+	// decimals is read once for ParseAmount and FormatAmount, the decimals of a token do not change
+	decimalsMu  sync.Mutex
+	decimals    uint8
+	hasDecimals bool
 }
 
 func NewNep5Helper(scriptHash helper.UInt160, endPoint string) *Nep5Helper {
@@ -44,12 +53,7 @@ func (n *Nep5Helper) TotalSupply() (uint64, error) {
 		return 0, fmt.Errorf("no stack result returned")
 	}
 	stack := response.Result.Stack[0]
-	bytes := helper.HexToBytes(stack.Value.(string))
-	for len(bytes) < 8 {
-		bytes = append(bytes, byte(0x00))
-	}
-	ts := binary.LittleEndian.Uint64(bytes)
-	return ts, nil
+	return stackUint64(stack)
 }
 
 func (n *Nep5Helper) Name() (string, error) {
@@ -67,7 +71,10 @@ func (n *Nep5Helper) Name() (string, error) {
 		return "", fmt.Errorf("no stack result returned")
 	}
 	stack := response.Result.Stack[0]
-	name := string(helper.HexToBytes(stack.Value.(string)))
+	name, err := stack.AsString()
+	if err != nil {
+		return "", err
+	}
 	return name, nil
 }
 
@@ -86,7 +93,10 @@ func (n *Nep5Helper) Symbol() (string, error) {
 		return "", fmt.Errorf("no stack result returned")
 	}
 	stack := response.Result.Stack[0]
-	symbol := string(helper.HexToBytes(stack.Value.(string)))
+	symbol, err := stack.AsString()
+	if err != nil {
+		return "", err
+	}
 	return symbol, nil
 }
 
@@ -105,11 +115,11 @@ func (n *Nep5Helper) Decimals() (uint8, error) {
 		return 0, fmt.Errorf("no stack result returned")
 	}
 	stack := response.Result.Stack[0]
-	decimals, err := strconv.ParseUint(stack.Value.(string), 10, 8)
-	if err != nil {
+	decimals, err := stack.AsBigInt()
+	if err != nil || !decimals.IsUint64() || decimals.Uint64() > 255 {
 		return 0, fmt.Errorf("conversion failed")
 	}
-	return uint8(decimals), nil
+	return uint8(decimals.Uint64()), nil
 }
 
 func (n *Nep5Helper) BalanceOf(address helper.UInt160) (uint64, error) {
@@ -131,46 +141,219 @@ func (n *Nep5Helper) BalanceOf(address helper.UInt160) (uint64, error) {
 		return 0, fmt.Errorf("no stack result returned")
 	}
 	stack := response.Result.Stack[0]
-	bytes := helper.HexToBytes(stack.Value.(string))
-	for len(bytes) < 8 {
-		bytes = append(bytes, byte(0x00))
-	}
-	balance := binary.LittleEndian.Uint64(bytes)
-	return balance, nil
-}
-
-// This method is deprecated
-// Transfer is only testing the transfer script, please use WalletHelper to truly transfer nep5 token
-//func (n *Nep5Helper) Transfer(from helper.UInt160, to helper.UInt160, amount helper.Fixed8) (bool, []byte, error) {
-//	sb := sc.NewScriptBuilder()
-//	cp1 := sc.ContractParameter{
-//		Type:  sc.Hash160,
-//		Value: from.Bytes(),
-//	}
-//	cp2 := sc.ContractParameter{
-//		Type:  sc.Hash160,
-//		Value: to.Bytes(),
-//	}
-//	cp3 := sc.ContractParameter{
-//		Type:  sc.Integer,
-//		Value: amount.Value,
-//	}
-//	sb.MakeInvocationScript(n.scriptHash.Bytes(), "transfer", []sc.ContractParameter{cp1, cp2, cp3})
-//	script := sb.ToArray()
-//	response := n.Client.InvokeScript(helper.BytesToHex(script))
-//	if response.HasError() {
-//		return false, []byte{}, fmt.Errorf(response.ErrorResponse.Error.Message)
-//	}
-//	if response.Result.State == "FAULT" {
-//		return false, []byte{}, fmt.Errorf("engine faulted")
-//	}
-//	if len(response.Result.Stack) == 0 {
-//		return false, []byte{}, fmt.Errorf("no stack result returned")
-//	}
-//	stack := response.Result.Stack[0]
-//	b, err := strconv.ParseBool(stack.Value)
-//	if err != nil {
-//		return false, []byte{}, fmt.Errorf("conversion failed")
-//	}
-//	return b, script, nil
-//}
+	return stackUint64(stack)
+}
+
+// stackUint64 reads an integer result which does not fit in a uint64 as an error rather than wrapping it
+func stackUint64(stack models.InvokeStack) (uint64, error) {
+	i, err := stack.AsBigInt()
+	if err != nil {
+		return 0, err
+	}
+	if !i.IsUint64() {
+		return 0, fmt.Errorf("%s is out of the range of uint64", i.String())
+	}
+	return i.Uint64(), nil
+}
+
+// Allowance returns the amount the spender can transfer from the owner with TransferFrom
+func (n *Nep5Helper) Allowance(owner helper.UInt160, spender helper.UInt160) (*big.Int, error) {
+	sb := sc.NewScriptBuilder()
+	args := []sc.ContractParameter{
+		{Type: sc.Hash160, Value: owner.Bytes()},
+		{Type: sc.Hash160, Value: spender.Bytes()},
+	}
+	if err := sb.MakeInvocationScript(n.scriptHash.Bytes(), "allowance", args); err != nil {
+		return nil, err
+	}
+	script := sb.ToArray()
+	response := n.Client.InvokeScript(helper.BytesToHex(script), helper.ZeroScriptHashString)
+	if response.HasError() {
+		return nil, fmt.Errorf(response.ErrorResponse.Error.Message)
+	}
+	if response.Result.State == "FAULT" {
+		return nil, fmt.Errorf("engine faulted")
+	}
+	if len(response.Result.Stack) == 0 {
+		return nil, fmt.Errorf("no stack result returned")
+	}
+	stack := response.Result.Stack[0]
+	return stack.AsBigInt()
+}
+
+// ParseAmount converts a decimal amount like "1.5" to the integer amount of the token, scaled by Decimals
+func (n *Nep5Helper) ParseAmount(amount string) (*big.Int, error) {
+	decimals, err := n.cachedDecimals()
+	if err != nil {
+		return nil, err
+	}
+	integer, fraction := amount, ""
+	if i := strings.IndexByte(amount, '.'); i >= 0 {
+		integer, fraction = amount[:i], amount[i+1:]
+	}
+	if integer == "" || len(fraction) > int(decimals) || strings.Trim(integer+fraction, "0123456789") != "" {
+		return nil, fmt.Errorf("invalid amount %s of %d decimals", amount, decimals)
+	}
+	a, _ := new(big.Int).SetString(integer+fraction+strings.Repeat("0", int(decimals)-len(fraction)), 10)
+	return a, nil
+}
+
+// FormatAmount converts the integer amount of the token to a decimal string, scaled by Decimals
+func (n *Nep5Helper) FormatAmount(amount *big.Int) (string, error) {
+	decimals, err := n.cachedDecimals()
+	if err != nil {
+		return "", err
+	}
+	s := new(big.Int).Abs(amount).String()
+	if decimals > 0 {
+		if len(s) <= int(decimals) {
+			s = strings.Repeat("0", int(decimals)-len(s)+1) + s
+		}
+		s = strings.TrimRight(s[:len(s)-int(decimals)]+"."+s[len(s)-int(decimals):], "0")
+		s = strings.TrimSuffix(s, ".")
+	}
+	if amount.Sign() < 0 {
+		s = "-" + s
+	}
+	return s, nil
+}
+
+// cachedDecimals returns the decimals read by the first successful call
+func (n *Nep5Helper) cachedDecimals() (uint8, error) {
+	n.decimalsMu.Lock()
+	defer n.decimalsMu.Unlock()
+	if !n.hasDecimals {
+		decimals, err := n.Decimals()
+		if err != nil {
+			return 0, err
+		}
+		n.decimals, n.hasDecimals = decimals, true
+	}
+	return n.decimals, nil
+}
+
+// MakeTransfer builds the unsigned invocation transaction of transfer, the amount is in the smallest unit
+// of the token, see ParseAmount
+func (n *Nep5Helper) MakeTransfer(from helper.UInt160, to helper.UInt160, amount *big.Int) (*tx.InvocationTransaction, error) {
+	return n.makeInvocation(from, "transfer", amount, from, to)
+}
+
+// Transfer transfers the tokens of the account, return txid
+func (n *Nep5Helper) Transfer(from *wallet.Account, to helper.UInt160, amount *big.Int) (string, error) {
+	f, err := from.ScriptHash()
+	if err != nil {
+		return "", err
+	}
+	itx, err := n.MakeTransfer(f, to, amount)
+	if err != nil {
+		return "", err
+	}
+	return n.signAndSend(itx, from)
+}
+
+// MakeApprove builds the unsigned invocation transaction which allows the spender to transfer the amount
+// from the owner, 0 revokes the allowance
+func (n *Nep5Helper) MakeApprove(owner helper.UInt160, spender helper.UInt160, amount *big.Int) (*tx.InvocationTransaction, error) {
+	return n.makeInvocation(owner, "approve", amount, owner, spender)
+}
+
+// Approve allows the spender to transfer the amount from the account, return txid
+func (n *Nep5Helper) Approve(owner *wallet.Account, spender helper.UInt160, amount *big.Int) (string, error) {
+	o, err := owner.ScriptHash()
+	if err != nil {
+		return "", err
+	}
+	itx, err := n.MakeApprove(o, spender, amount)
+	if err != nil {
+		return "", err
+	}
+	return n.signAndSend(itx, owner)
+}
+
+// MakeTransferFrom builds the unsigned invocation transaction of transferFrom, where the spender
+// transfers from the owner within the allowance
+func (n *Nep5Helper) MakeTransferFrom(spender helper.UInt160, from helper.UInt160, to helper.UInt160, amount *big.Int) (*tx.InvocationTransaction, error) {
+	return n.makeInvocation(spender, "transferFrom", amount, spender, from, to)
+}
+
+// TransferFrom transfers the tokens of the owner approved for the account, return txid
+func (n *Nep5Helper) TransferFrom(spender *wallet.Account, from helper.UInt160, to helper.UInt160, amount *big.Int) (string, error) {
+	s, err := spender.ScriptHash()
+	if err != nil {
+		return "", err
+	}
+	itx, err := n.MakeTransferFrom(s, from, to, amount)
+	if err != nil {
+		return "", err
+	}
+	return n.signAndSend(itx, spender)
+}
+
+// makeInvocation calls the method with the script hashes and the amount, checks the result is true with
+// InvokeScript and builds the transaction with the Script attribute of the sender, who pays the fee
+// for the gas consumed by the same InvokeScript
+func (n *Nep5Helper) makeInvocation(sender helper.UInt160, method string, amount *big.Int, hashes ...helper.UInt160) (*tx.InvocationTransaction, error) {
+	if amount == nil || amount.Sign() < 0 {
+		return nil, fmt.Errorf("invalid amount %v", amount)
+	}
+	args := make([]sc.ContractParameter, 0, len(hashes)+1)
+	for _, hash := range hashes {
+		args = append(args, sc.ContractParameter{Type: sc.Hash160, Value: hash.Bytes()})
+	}
+	args = append(args, sc.ContractParameter{Type: sc.Integer, Value: *amount})
+	sb := sc.NewScriptBuilder()
+	if err := sb.MakeInvocationScript(n.scriptHash.Bytes(), method, args); err != nil {
+		return nil, err
+	}
+	script := sb.ToArray()
+
+	response := n.Client.InvokeScript(helper.BytesToHex(script), sender.String())
+	if response.HasError() {
+		return nil, fmt.Errorf(response.ErrorResponse.Error.Message)
+	}
+	if response.Result.State == "FAULT" {
+		return nil, fmt.Errorf("engine faulted")
+	}
+	if len(response.Result.Stack) == 0 {
+		return nil, fmt.Errorf("no stack result returned")
+	}
+	ok, err := response.Result.Stack[0].AsBool()
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("%s of %s returns false", method, n.scriptHash.String())
+	}
+
+	gas, err := tx.InvocationGas(response.Result)
+	if err != nil {
+		return nil, err
+	}
+	tb := &tx.TransactionBuilder{EndPoint: n.EndPoint, Client: n.Client}
+	itx, err := tb.MakeInvocationTransactionWithGas(script, sender, nil, helper.UInt160{}, gas, helper.Zero, helper.Zero)
+	if err != nil {
+		return nil, err
+	}
+	// the witness of the sender is required by CheckWitness even if the transaction has no inputs
+	itx.AddScriptHashToAttribute(sender)
+	return itx, nil
+}
+
+// signAndSend signs the transaction with the signer of the account and sends it, return txid
+func (n *Nep5Helper) signAndSend(itx *tx.InvocationTransaction, from *wallet.Account) (string, error) {
+	signer := from.GetSigner()
+	if signer == nil {
+		return "", fmt.Errorf("the account %s has no key to sign", from.Address)
+	}
+	if signer.Public().Address() != from.Address {
+		return "", fmt.Errorf("the account %s cannot sign alone", from.Address)
+	}
+	if err := tx.AddSignature(itx, signer); err != nil {
+		return "", err
+	}
+	response := n.Client.SendRawTransaction(itx.RawTransactionString())
+	if response.HasError() {
+		return "", fmt.Errorf(response.ErrorResponse.Error.Message)
+	}
+	return itx.HashString(), nil
+}
@@ -1,6 +1,8 @@
 package nep5
 
 import (
+	"math/big"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -9,6 +11,9 @@ import (
 	"github.com/joeqian10/neo-gogogo/helper"
 	"github.com/joeqian10/neo-gogogo/rpc"
 	"github.com/joeqian10/neo-gogogo/rpc/models"
+	"github.com/joeqian10/neo-gogogo/tx"
+	"github.com/joeqian10/neo-gogogo/wallet"
+	"github.com/joeqian10/neo-gogogo/wallet/keys"
 )
 
 func TestNewNep5Helper(t *testing.T) {
@@ -197,6 +202,25 @@ func TestNep5Helper_TotalSupply(t *testing.T) {
 	assert.Equal(t, uint64(59480000000000000), s)
 }
 
+func TestNep5Helper_OutOfUint64(t *testing.T) {
+	for _, value := range []string{"000000000000000001", "ff"} { // 2^64 and -1
+		var clientMock = new(rpc.RpcClientMock)
+		var nh = Nep5Helper{
+			Client: clientMock,
+		}
+		clientMock.On("InvokeScript", mock.Anything, mock.Anything).Return(rpc.InvokeScriptResponse{
+			Result: models.InvokeResult{
+				State: "HALT",
+				Stack: []models.InvokeStack{{Type: "ByteArray", Value: value}},
+			},
+		})
+		_, err := nh.TotalSupply()
+		assert.NotNil(t, err)
+		_, err = nh.BalanceOf(helper.UInt160{})
+		assert.NotNil(t, err)
+	}
+}
+
 //func TestNep5Helper_Transfer(t *testing.T) {
 //	var clientMock = new(rpc.RpcClientMock)
 //	var nh = Nep5Helper{
@@ -231,3 +255,120 @@ func TestNep5Helper_TotalSupply(t *testing.T) {
 //	assert.Nil(t, e)
 //	assert.Equal(t, true, b)
 //}
+
+func newNep5HelperInvoking(stack models.InvokeStack) (*Nep5Helper, *rpc.RpcClientMock) {
+	var clientMock = new(rpc.RpcClientMock)
+	scriptHash, _ := helper.UInt160FromString("0xb9d7ea3062e6aeeb3e8ad9548220c4ba1361d263")
+	clientMock.On("InvokeScript", mock.Anything, mock.Anything).Return(rpc.InvokeScriptResponse{
+		Result: models.InvokeResult{
+			State:       "HALT",
+			GasConsumed: "1.2",
+			Stack:       []models.InvokeStack{stack},
+		},
+	})
+	return &Nep5Helper{scriptHash: scriptHash, Client: clientMock}, clientMock
+}
+
+func scriptAttributes(itx *tx.InvocationTransaction) [][]byte {
+	var data [][]byte
+	for _, attr := range itx.Attributes {
+		if attr.Usage == tx.Script {
+			data = append(data, attr.Data)
+		}
+	}
+	return data
+}
+
+func TestNep5Helper_Allowance(t *testing.T) {
+	nh, clientMock := newNep5HelperInvoking(models.InvokeStack{Type: "Integer", Value: "100000000"})
+	owner, _ := helper.AddressToScriptHash(keys.KeyCases[0].Address)
+	spender, _ := helper.AddressToScriptHash(keys.KeyCases[1].Address)
+	allowance, err := nh.Allowance(owner, spender)
+	assert.Nil(t, err)
+	assert.Equal(t, big.NewInt(100000000), allowance)
+	clientMock.AssertCalled(t, "InvokeScript", mock.MatchedBy(func(script string) bool {
+		return strings.Contains(script, helper.BytesToHex([]byte("allowance")))
+	}), helper.ZeroScriptHashString)
+}
+
+func TestNep5Helper_ParseAmount(t *testing.T) {
+	nh, clientMock := newNep5HelperInvoking(models.InvokeStack{Type: "Integer", Value: "8"})
+	a, err := nh.ParseAmount("1.5")
+	assert.Nil(t, err)
+	assert.Equal(t, big.NewInt(150000000), a)
+	a, err = nh.ParseAmount("12345678901234567890")
+	assert.Nil(t, err)
+	assert.Equal(t, "1234567890123456789000000000", a.String())
+	for _, s := range []string{"", ".5", "1.123456789", "-1", "1e8", "1,5"} {
+		_, err = nh.ParseAmount(s)
+		assert.NotNil(t, err, s)
+	}
+
+	s, err := nh.FormatAmount(big.NewInt(150000000))
+	assert.Nil(t, err)
+	assert.Equal(t, "1.5", s)
+	s, err = nh.FormatAmount(big.NewInt(1))
+	assert.Nil(t, err)
+	assert.Equal(t, "0.00000001", s)
+	s, err = nh.FormatAmount(big.NewInt(-200000000))
+	assert.Nil(t, err)
+	assert.Equal(t, "-2", s)
+	// the decimals are read once
+	clientMock.AssertNumberOfCalls(t, "InvokeScript", 1)
+}
+
+func TestNep5Helper_Transfer(t *testing.T) {
+	nh, clientMock := newNep5HelperInvoking(models.InvokeStack{Type: "Boolean", Value: true})
+	clientMock.On("SendRawTransaction", mock.Anything).Return(rpc.SendRawTransactionResponse{Result: true})
+	from, err := wallet.NewAccountFromWIF(keys.KeyCases[0].Wif)
+	assert.Nil(t, err)
+	f, _ := from.ScriptHash()
+	to, _ := helper.AddressToScriptHash(keys.KeyCases[1].Address)
+
+	itx, err := nh.MakeTransfer(f, to, big.NewInt(150000000))
+	assert.Nil(t, err)
+	assert.Equal(t, [][]byte{f.Bytes()}, scriptAttributes(itx))
+	// the pre-check is invoked with the witness of the sender, and its gas is used for the fee
+	clientMock.AssertCalled(t, "InvokeScript", helper.BytesToHex(itx.Script), f.String())
+	clientMock.AssertNumberOfCalls(t, "InvokeScript", 1)
+
+	txId, err := nh.Transfer(from, to, big.NewInt(150000000))
+	assert.Nil(t, err)
+	assert.Equal(t, 64, len(txId))
+	clientMock.AssertNumberOfCalls(t, "SendRawTransaction", 1)
+
+	_, err = nh.MakeTransfer(f, to, big.NewInt(-1))
+	assert.NotNil(t, err)
+
+	// watch-only accounts cannot sign
+	watchOnly, _ := wallet.NewWatchOnlyAccount(keys.KeyCases[1].Address)
+	_, err = nh.Transfer(watchOnly, f, big.NewInt(1))
+	assert.NotNil(t, err)
+}
+
+func TestNep5Helper_ApproveAndTransferFrom(t *testing.T) {
+	nh, clientMock := newNep5HelperInvoking(models.InvokeStack{Type: "Boolean", Value: true})
+	clientMock.On("SendRawTransaction", mock.Anything).Return(rpc.SendRawTransactionResponse{Result: true})
+	owner, _ := wallet.NewAccountFromWIF(keys.KeyCases[0].Wif)
+	spender, _ := wallet.NewAccountFromWIF(keys.KeyCases[1].Wif)
+	o, _ := owner.ScriptHash()
+	s, _ := spender.ScriptHash()
+
+	_, err := nh.Approve(owner, s, big.NewInt(100))
+	assert.Nil(t, err)
+	itx, err := nh.MakeTransferFrom(s, o, s, big.NewInt(100))
+	assert.Nil(t, err)
+	assert.Equal(t, [][]byte{s.Bytes()}, scriptAttributes(itx))
+	assert.True(t, strings.Contains(helper.BytesToHex(itx.Script), helper.BytesToHex([]byte("transferFrom"))))
+	_, err = nh.TransferFrom(spender, o, s, big.NewInt(100))
+	assert.Nil(t, err)
+	clientMock.AssertNumberOfCalls(t, "SendRawTransaction", 2)
+}
+
+func TestNep5Helper_Transfer_False(t *testing.T) {
+	nh, _ := newNep5HelperInvoking(models.InvokeStack{Type: "Boolean", Value: false})
+	from, _ := helper.AddressToScriptHash(keys.KeyCases[0].Address)
+	to, _ := helper.AddressToScriptHash(keys.KeyCases[1].Address)
+	_, err := nh.MakeTransfer(from, to, big.NewInt(1))
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,229 @@
+package nep5
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sync"
+	"time"
+
+	"github.com/joeqian10/neo-gogogo/helper"
+	"github.com/joeqian10/neo-gogogo/rpc"
+	"github.com/joeqian10/neo-gogogo/sc"
+)
+
+const (
+	// DefaultTokenTTL is how long the fetched metadata is cached
+	DefaultTokenTTL = time.Hour
+	// TokenBatchSize is the max number of tokens whose metadata is read in one InvokeScript
+	TokenBatchSize = 16
+
+	MainNet = "mainnet"
+	TestNet = "testnet"
+)
+
+// TokenInfo is the metadata of a NEP-5 token
+type TokenInfo struct {
+	ScriptHash helper.UInt160
+	Name       string
+	Symbol     string
+	Decimals   uint8
+}
+
+// TokenSeed is an entry of the json seed file, the hash is in big endian with or without 0x
+type TokenSeed struct {
+	Network  string `json:"network"`
+	Hash     string `json:"hash"`
+	Name     string `json:"name"`
+	Symbol   string `json:"symbol"`
+	Decimals uint8  `json:"decimals"`
+}
+
+type tokenEntry struct {
+	info TokenInfo
+	// notToken is set for a contract whose metadata faulted, so that it is not read again until it expires
+	notToken bool
+	// expires is zero for the seeded tokens, which never expire
+	expires time.Time
+}
+
+// TokenRegistry caches the metadata of NEP-5 tokens, the metadata of the tokens not cached is read in
+// batches, calling name, symbol and decimals of several tokens in one script
+type TokenRegistry struct {
+	Client rpc.IRpcClient
+	// TTL is how long the fetched metadata is cached
+	TTL time.Duration
+
+	mu     sync.RWMutex
+	tokens map[helper.UInt160]tokenEntry
+	now    func() time.Time
+}
+
+// NewTokenRegistry creates a TokenRegistry, ttl 0 means DefaultTokenTTL
+func NewTokenRegistry(client rpc.IRpcClient, ttl time.Duration) *TokenRegistry {
+	if ttl == 0 {
+		ttl = DefaultTokenTTL
+	}
+	return &TokenRegistry{
+		Client: client,
+		TTL:    ttl,
+		tokens: map[helper.UInt160]tokenEntry{},
+		now:    time.Now,
+	}
+}
+
+// Seed adds well-known tokens which never expire, the metadata of a deployed token does not change
+func (r *TokenRegistry) Seed(tokens ...TokenInfo) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, token := range tokens {
+		r.tokens[token.ScriptHash] = tokenEntry{info: token}
+	}
+}
+
+// LoadSeedFile seeds the tokens of the network from a json array of TokenSeed, e.g. tokens.json
+func (r *TokenRegistry) LoadSeedFile(path string, network string) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var seeds []TokenSeed
+	if err = json.Unmarshal(data, &seeds); err != nil {
+		return err
+	}
+	var tokens []TokenInfo
+	for _, seed := range seeds {
+		if seed.Network != network {
+			continue
+		}
+		hash, err := helper.UInt160FromString(seed.Hash)
+		if err != nil {
+			return fmt.Errorf("invalid token hash %s", seed.Hash)
+		}
+		tokens = append(tokens, TokenInfo{ScriptHash: hash, Name: seed.Name, Symbol: seed.Symbol, Decimals: seed.Decimals})
+	}
+	r.Seed(tokens...)
+	return nil
+}
+
+// Invalidate removes the token from the cache, seeded or not
+func (r *TokenRegistry) Invalidate(hash helper.UInt160) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.tokens, hash)
+}
+
+// GetToken returns the metadata of the token
+func (r *TokenRegistry) GetToken(hash helper.UInt160) (*TokenInfo, error) {
+	tokens, err := r.GetTokens([]helper.UInt160{hash})
+	if err != nil {
+		return nil, err
+	}
+	token, ok := tokens[hash]
+	if !ok {
+		return nil, fmt.Errorf("%s is not a NEP-5 token", hash.String())
+	}
+	return token, nil
+}
+
+// GetTokens returns the metadata of the tokens, reading those not cached with as few InvokeScript as possible.
+// The contracts which fault, e.g. not NEP-5 tokens, are missing in the result, and are cached as such for the TTL.
+func (r *TokenRegistry) GetTokens(hashes []helper.UInt160) (map[helper.UInt160]*TokenInfo, error) {
+	result := make(map[helper.UInt160]*TokenInfo, len(hashes))
+	var missing []helper.UInt160
+	seen := make(map[helper.UInt160]bool, len(hashes))
+	now := r.now()
+	r.mu.RLock()
+	for _, hash := range hashes {
+		if seen[hash] {
+			continue
+		}
+		seen[hash] = true
+		entry, ok := r.tokens[hash]
+		if ok && (entry.expires.IsZero() || now.Before(entry.expires)) {
+			if !entry.notToken {
+				info := entry.info
+				result[hash] = &info
+			}
+		} else {
+			missing = append(missing, hash)
+		}
+	}
+	r.mu.RUnlock()
+
+	for len(missing) > 0 {
+		n := len(missing)
+		if n > TokenBatchSize {
+			n = TokenBatchSize
+		}
+		tokens, err := r.fetch(missing[:n])
+		if err != nil {
+			return nil, err
+		}
+		expires := r.now().Add(r.TTL)
+		r.mu.Lock()
+		for _, hash := range missing[:n] {
+			r.tokens[hash] = tokenEntry{notToken: true, expires: expires}
+		}
+		for _, token := range tokens {
+			r.tokens[token.ScriptHash] = tokenEntry{info: *token, expires: expires}
+			result[token.ScriptHash] = token
+		}
+		r.mu.Unlock()
+		missing = missing[n:]
+	}
+	return result, nil
+}
+
+// fetch reads the metadata in one script, the batch is split in halves when it faults to skip the faulting contracts
+func (r *TokenRegistry) fetch(hashes []helper.UInt160) ([]*TokenInfo, error) {
+	sb := sc.NewScriptBuilder()
+	for _, hash := range hashes {
+		for _, method := range []string{"name", "symbol", "decimals"} {
+			if err := sb.MakeInvocationScript(hash.Bytes(), method, []sc.ContractParameter{}); err != nil {
+				return nil, err
+			}
+		}
+	}
+	response := r.Client.InvokeScript(helper.BytesToHex(sb.ToArray()), helper.ZeroScriptHashString)
+	if response.HasError() {
+		return nil, fmt.Errorf(response.ErrorResponse.Error.Message)
+	}
+	if response.Result.State == "FAULT" {
+		if len(hashes) == 1 {
+			return nil, nil
+		}
+		half := len(hashes) / 2
+		first, err := r.fetch(hashes[:half])
+		if err != nil {
+			return nil, err
+		}
+		second, err := r.fetch(hashes[half:])
+		if err != nil {
+			return nil, err
+		}
+		return append(first, second...), nil
+	}
+	// the results are in the order of the calls
+	stack := response.Result.Stack
+	if len(stack) != len(hashes)*3 {
+		return nil, fmt.Errorf("%d stack results returned for %d tokens", len(stack), len(hashes))
+	}
+	tokens := make([]*TokenInfo, 0, len(hashes))
+	for i, hash := range hashes {
+		name, err := stack[i*3].AsString()
+		if err != nil {
+			return nil, err
+		}
+		symbol, err := stack[i*3+1].AsString()
+		if err != nil {
+			return nil, err
+		}
+		decimals, err := stack[i*3+2].AsBigInt()
+		if err != nil || !decimals.IsUint64() || decimals.Uint64() > 255 {
+			return nil, fmt.Errorf("invalid decimals of %s", hash.String())
+		}
+		tokens = append(tokens, &TokenInfo{ScriptHash: hash, Name: name, Symbol: symbol, Decimals: uint8(decimals.Uint64())})
+	}
+	return tokens, nil
+}
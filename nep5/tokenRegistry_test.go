@@ -0,0 +1,161 @@
+package nep5
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/joeqian10/neo-gogogo/helper"
+	"github.com/joeqian10/neo-gogogo/rpc"
+	"github.com/joeqian10/neo-gogogo/rpc/models"
+)
+
+// tokenStack returns the stack results of name, symbol and decimals of the token
+func tokenStack(symbol string, decimals string) []models.InvokeStack {
+	return []models.InvokeStack{
+		{Type: "ByteArray", Value: helper.BytesToHex([]byte(symbol + " token"))},
+		{Type: "ByteArray", Value: helper.BytesToHex([]byte(symbol))},
+		{Type: "Integer", Value: decimals},
+	}
+}
+
+func scriptCalls(hash helper.UInt160) func(string) bool {
+	return func(script string) bool {
+		return strings.Contains(script, helper.BytesToHex(hash.Bytes()))
+	}
+}
+
+func TestTokenRegistry_GetTokens(t *testing.T) {
+	token1, _ := helper.UInt160FromString("0xb9d7ea3062e6aeeb3e8ad9548220c4ba1361d263")
+	token2, _ := helper.UInt160FromString("0x74f2dc36a68fdc4682034178eb2220729231db76")
+	var clientMock = new(rpc.RpcClientMock)
+	clientMock.On("InvokeScript", mock.Anything, helper.ZeroScriptHashString).Return(rpc.InvokeScriptResponse{
+		Result: models.InvokeResult{State: "HALT", Stack: append(tokenStack("ABC", "8"), tokenStack("CGAS", "8")...)},
+	})
+	r := NewTokenRegistry(clientMock, time.Minute)
+	now := time.Now()
+	r.now = func() time.Time { return now }
+
+	// both tokens are read in one script
+	tokens, err := r.GetTokens([]helper.UInt160{token1, token2, token1})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(tokens))
+	assert.Equal(t, "ABC token", tokens[token1].Name)
+	assert.Equal(t, "ABC", tokens[token1].Symbol)
+	assert.Equal(t, "CGAS", tokens[token2].Symbol)
+	assert.Equal(t, uint8(8), tokens[token2].Decimals)
+	clientMock.AssertNumberOfCalls(t, "InvokeScript", 1)
+	clientMock.AssertCalled(t, "InvokeScript", mock.MatchedBy(func(script string) bool {
+		return scriptCalls(token1)(script) && scriptCalls(token2)(script)
+	}), helper.ZeroScriptHashString)
+
+	// cached
+	_, err = r.GetTokens([]helper.UInt160{token1, token2})
+	assert.Nil(t, err)
+	clientMock.AssertNumberOfCalls(t, "InvokeScript", 1)
+
+	// expired
+	now = now.Add(time.Minute)
+	_, err = r.GetTokens([]helper.UInt160{token1, token2})
+	assert.Nil(t, err)
+	clientMock.AssertNumberOfCalls(t, "InvokeScript", 2)
+}
+
+func TestTokenRegistry_GetTokens_Fault(t *testing.T) {
+	token1, _ := helper.UInt160FromString("0xb9d7ea3062e6aeeb3e8ad9548220c4ba1361d263")
+	notToken, _ := helper.UInt160FromString("0x0000000000000000000000000000000000000001")
+	var clientMock = new(rpc.RpcClientMock)
+	clientMock.On("InvokeScript", mock.MatchedBy(scriptCalls(notToken)), mock.Anything).Return(rpc.InvokeScriptResponse{
+		Result: models.InvokeResult{State: "FAULT"},
+	})
+	clientMock.On("InvokeScript", mock.Anything, mock.Anything).Return(rpc.InvokeScriptResponse{
+		Result: models.InvokeResult{State: "HALT", Stack: tokenStack("ABC", "2")},
+	})
+	r := NewTokenRegistry(clientMock, 0)
+	assert.Equal(t, DefaultTokenTTL, r.TTL)
+	now := time.Now()
+	r.now = func() time.Time { return now }
+
+	// the batch is split to skip the contract which faults
+	tokens, err := r.GetTokens([]helper.UInt160{token1, notToken})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(tokens))
+	assert.Equal(t, uint8(2), tokens[token1].Decimals)
+	clientMock.AssertNumberOfCalls(t, "InvokeScript", 3)
+
+	// the contract which faults is cached as not a token
+	_, err = r.GetToken(notToken)
+	assert.EqualError(t, err, notToken.String()+" is not a NEP-5 token")
+	tokens, err = r.GetTokens([]helper.UInt160{token1, notToken})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(tokens))
+	clientMock.AssertNumberOfCalls(t, "InvokeScript", 3)
+
+	// until it expires
+	now = now.Add(DefaultTokenTTL)
+	_, err = r.GetToken(notToken)
+	assert.NotNil(t, err)
+	clientMock.AssertNumberOfCalls(t, "InvokeScript", 4)
+}
+
+func TestTokenRegistry_GetTokens_Batches(t *testing.T) {
+	var clientMock = new(rpc.RpcClientMock)
+	var stack []models.InvokeStack
+	for i := 0; i < TokenBatchSize; i++ {
+		stack = append(stack, tokenStack("T", "0")...)
+	}
+	clientMock.On("InvokeScript", mock.Anything, mock.Anything).Return(rpc.InvokeScriptResponse{
+		Result: models.InvokeResult{State: "HALT", Stack: stack},
+	}).Once()
+	clientMock.On("InvokeScript", mock.Anything, mock.Anything).Return(rpc.InvokeScriptResponse{
+		Result: models.InvokeResult{State: "HALT", Stack: tokenStack("T", "0")},
+	}).Once()
+	var hashes []helper.UInt160
+	for i := 0; i <= TokenBatchSize; i++ {
+		hashes = append(hashes, helper.UInt160{byte(i + 1)})
+	}
+	r := NewTokenRegistry(clientMock, 0)
+	tokens, err := r.GetTokens(hashes)
+	assert.Nil(t, err)
+	assert.Equal(t, TokenBatchSize+1, len(tokens))
+	clientMock.AssertNumberOfCalls(t, "InvokeScript", 2)
+}
+
+func TestTokenRegistry_LoadSeedFile(t *testing.T) {
+	var clientMock = new(rpc.RpcClientMock)
+	r := NewTokenRegistry(clientMock, time.Minute)
+	now := time.Now()
+	r.now = func() time.Time { return now }
+	err := r.LoadSeedFile("tokens.json", MainNet)
+	assert.Nil(t, err)
+
+	cgas, _ := helper.UInt160FromString("0x74f2dc36a68fdc4682034178eb2220729231db76")
+	testNetCgas, _ := helper.UInt160FromString("0x2a8cc3b07d25dfae0d212738b39c2e0d17a1c7f3")
+	token, err := r.GetToken(cgas)
+	assert.Nil(t, err)
+	assert.Equal(t, "CGAS", token.Symbol)
+	assert.Equal(t, uint8(8), token.Decimals)
+
+	// the seeded tokens never expire
+	now = now.Add(24 * time.Hour)
+	_, err = r.GetToken(cgas)
+	assert.Nil(t, err)
+	clientMock.AssertNotCalled(t, "InvokeScript", mock.Anything, mock.Anything)
+
+	// the tokens of the other network are not seeded
+	r.mu.RLock()
+	_, ok := r.tokens[testNetCgas]
+	r.mu.RUnlock()
+	assert.False(t, ok)
+
+	r.Invalidate(cgas)
+	clientMock.On("InvokeScript", mock.Anything, mock.Anything).Return(rpc.InvokeScriptResponse{
+		Result: models.InvokeResult{State: "HALT", Stack: tokenStack("CGAS", "8")},
+	})
+	_, err = r.GetToken(cgas)
+	assert.Nil(t, err)
+	clientMock.AssertNumberOfCalls(t, "InvokeScript", 1)
+}
@@ -1,5 +1,7 @@
 package rpc
 
+import "encoding/json"
+
 // add IRpcClient for mock UT
 type IRpcClient interface {
 	ClaimGas(s string) ClaimGasResponse
@@ -18,6 +20,7 @@ type IRpcClient interface {
 	GetContractState(s string) GetContractStateResponse
 	GetNep5Balances(s string) GetNep5BalancesResponse
 	GetNep5Transfers(s string) GetNep5TransfersResponse
+	GetNep5TransfersFrom(s string, t uint32) GetNep5TransfersResponse
 	GetNewAddress() GetNewAddressResponse
 	GetPeers() GetPeersResponse
 	GetRawMemPool() GetRawMemPoolResponse
@@ -32,7 +35,7 @@ type IRpcClient interface {
 	GetVersion() GetVersionResponse
 	GetWalletHeight() GetWalletHeightResponse
 	ImportPrivKey(s string) ImportPrivKeyResponse
-	InvokeFunction(s1 string, s2 string, s3 string, args ...interface{}) InvokeFunctionResponse
+	InvokeFunction(s1 string, s2 string, s3 string, args ...json.Marshaler) InvokeFunctionResponse
 	InvokeScript(s1 string, s2 string) InvokeScriptResponse
 	ListPlugins() ListPluginsResponse
 	ListAddress() ListAddressResponse
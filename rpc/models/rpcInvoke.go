@@ -31,17 +31,13 @@ func (s *InvokeStack) Convert() {
 			break
 		}
 	} else {
-		vs := s.Value.([]interface{})
-		result := make([]InvokeStack, len(vs))
-		for i, v := range vs {
-			m := v.(map[string]interface{})
-			s2 := InvokeStack{
-				Type:  m["type"].(string),
-				Value: m["value"],
-			}
-			s2.Convert()
-			result[i] = s2
+		items, err := s.AsArray()
+		if err != nil {
+			return
+		}
+		for i := range items {
+			items[i].Convert()
 		}
-		s.Value = result
+		s.Value = items
 	}
 }
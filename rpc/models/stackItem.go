@@ -0,0 +1,305 @@
+package models
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strconv"
+
+	"github.com/joeqian10/neo-gogogo/helper"
+)
+
+// stack item types returned by the node
+const (
+	StackTypeSignature        = "Signature"
+	StackTypeBoolean          = "Boolean"
+	StackTypeInteger          = "Integer"
+	StackTypeHash160          = "Hash160"
+	StackTypeHash256          = "Hash256"
+	StackTypeByteArray        = "ByteArray"
+	StackTypePublicKey        = "PublicKey"
+	StackTypeString           = "String"
+	StackTypeArray            = "Array"
+	StackTypeStruct           = "Struct"
+	StackTypeMap              = "Map"
+	StackTypeInteropInterface = "InteropInterface"
+	StackTypeVoid             = "Void"
+)
+
+// StackMapEntry is a key value pair of a Map stack item
+type StackMapEntry struct {
+	Key   InvokeStack `json:"key"`
+	Value InvokeStack `json:"value"`
+}
+
+// AsBytes returns the bytes of a ByteArray, String, Integer or Boolean stack item
+func (s *InvokeStack) AsBytes() ([]byte, error) {
+	switch s.Type {
+	case StackTypeByteArray, StackTypeSignature, StackTypePublicKey:
+		v, err := s.stringValue()
+		if err != nil {
+			return nil, err
+		}
+		b, err := hex.DecodeString(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s value %s: %v", s.Type, v, err)
+		}
+		return b, nil
+	case StackTypeString:
+		v, err := s.stringValue()
+		if err != nil {
+			return nil, err
+		}
+		return []byte(v), nil
+	case StackTypeHash160, StackTypeHash256:
+		v, err := s.stringValue()
+		if err != nil {
+			return nil, err
+		}
+		return helper.ReverseBytes(helper.HexToBytes(trimHexPrefix(v))), nil
+	case StackTypeInteger:
+		i, err := s.AsBigInt()
+		if err != nil {
+			return nil, err
+		}
+		return helper.BigIntToNeoBytes(i), nil
+	case StackTypeBoolean:
+		b, err := s.AsBool()
+		if err != nil {
+			return nil, err
+		}
+		if b {
+			return []byte{1}, nil
+		}
+		return []byte{}, nil
+	}
+	return nil, fmt.Errorf("cannot convert %s to bytes", s.Type)
+}
+
+// AsBigInt returns the integer value, ByteArray is decoded as Neo little-endian two's complement
+func (s *InvokeStack) AsBigInt() (*big.Int, error) {
+	switch s.Type {
+	case StackTypeInteger:
+		switch v := s.Value.(type) {
+		case string:
+			i, ok := new(big.Int).SetString(v, 10)
+			if !ok {
+				return nil, fmt.Errorf("invalid Integer value %s", v)
+			}
+			return i, nil
+		case float64:
+			return big.NewInt(int64(v)), nil
+		case int:
+			return big.NewInt(int64(v)), nil
+		case int64:
+			return big.NewInt(v), nil
+		}
+		return nil, fmt.Errorf("invalid Integer value %v", s.Value)
+	case StackTypeBoolean:
+		b, err := s.AsBool()
+		if err != nil {
+			return nil, err
+		}
+		if b {
+			return big.NewInt(1), nil
+		}
+		return big.NewInt(0), nil
+	case StackTypeByteArray:
+		b, err := s.AsBytes()
+		if err != nil {
+			return nil, err
+		}
+		if len(b) > 32 {
+			return nil, fmt.Errorf("ByteArray of %d bytes is too long for an integer", len(b))
+		}
+		return helper.BigIntFromNeoBytes(b), nil
+	}
+	return nil, fmt.Errorf("cannot convert %s to integer", s.Type)
+}
+
+// AsBool returns the boolean value, Integer and ByteArray are true if they are not zero
+func (s *InvokeStack) AsBool() (bool, error) {
+	switch s.Type {
+	case StackTypeBoolean:
+		switch v := s.Value.(type) {
+		case bool:
+			return v, nil
+		case string:
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				return false, fmt.Errorf("invalid Boolean value %s", v)
+			}
+			return b, nil
+		}
+		return false, fmt.Errorf("invalid Boolean value %v", s.Value)
+	case StackTypeInteger:
+		i, err := s.AsBigInt()
+		if err != nil {
+			return false, err
+		}
+		return i.Sign() != 0, nil
+	case StackTypeByteArray:
+		b, err := s.AsBytes()
+		if err != nil {
+			return false, err
+		}
+		for _, v := range b {
+			if v != 0 {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+	return false, fmt.Errorf("cannot convert %s to boolean", s.Type)
+}
+
+// AsString returns the UTF-8 string of a ByteArray or String stack item, Integer and Boolean are formatted
+func (s *InvokeStack) AsString() (string, error) {
+	switch s.Type {
+	case StackTypeInteger:
+		i, err := s.AsBigInt()
+		if err != nil {
+			return "", err
+		}
+		return i.String(), nil
+	case StackTypeBoolean:
+		b, err := s.AsBool()
+		if err != nil {
+			return "", err
+		}
+		return strconv.FormatBool(b), nil
+	case StackTypeString:
+		return s.stringValue()
+	}
+	b, err := s.AsBytes()
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// AsUInt160 returns the script hash of a 20 bytes ByteArray or a Hash160 stack item
+func (s *InvokeStack) AsUInt160() (helper.UInt160, error) {
+	b, err := s.AsBytes()
+	if err != nil {
+		return helper.UInt160{}, err
+	}
+	return helper.UInt160FromBytes(b)
+}
+
+// AsUInt256 returns the hash of a 32 bytes ByteArray or a Hash256 stack item
+func (s *InvokeStack) AsUInt256() (helper.UInt256, error) {
+	b, err := s.AsBytes()
+	if err != nil {
+		return helper.UInt256{}, err
+	}
+	return helper.UInt256FromBytes(b)
+}
+
+// AsArray returns the items of an Array or Struct stack item
+func (s *InvokeStack) AsArray() ([]InvokeStack, error) {
+	if s.Type != StackTypeArray && s.Type != StackTypeStruct {
+		return nil, fmt.Errorf("cannot convert %s to array", s.Type)
+	}
+	switch v := s.Value.(type) {
+	case []InvokeStack:
+		return v, nil
+	case nil:
+		return []InvokeStack{}, nil
+	case []interface{}:
+		items := make([]InvokeStack, len(v))
+		for i, item := range v {
+			si, err := stackItemFromInterface(item)
+			if err != nil {
+				return nil, err
+			}
+			items[i] = si
+		}
+		return items, nil
+	}
+	return nil, fmt.Errorf("invalid %s value %v", s.Type, s.Value)
+}
+
+// AsMap returns the entries of a Map stack item in the order returned by the node
+func (s *InvokeStack) AsMap() ([]StackMapEntry, error) {
+	if s.Type != StackTypeMap {
+		return nil, fmt.Errorf("cannot convert %s to map", s.Type)
+	}
+	switch v := s.Value.(type) {
+	case []StackMapEntry:
+		return v, nil
+	case nil:
+		return []StackMapEntry{}, nil
+	case []interface{}:
+		entries := make([]StackMapEntry, len(v))
+		for i, item := range v {
+			m, ok := item.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("invalid Map entry %v", item)
+			}
+			key, err := stackItemFromInterface(m["key"])
+			if err != nil {
+				return nil, err
+			}
+			value, err := stackItemFromInterface(m["value"])
+			if err != nil {
+				return nil, err
+			}
+			entries[i] = StackMapEntry{Key: key, Value: value}
+		}
+		return entries, nil
+	}
+	return nil, fmt.Errorf("invalid Map value %v", s.Value)
+}
+
+// AsInteropInterface returns the raw value of an InteropInterface stack item, which is usually empty
+// since the node can't serialize the interop object
+func (s *InvokeStack) AsInteropInterface() (interface{}, error) {
+	if s.Type != StackTypeInteropInterface {
+		return nil, fmt.Errorf("cannot convert %s to interop interface", s.Type)
+	}
+	return s.Value, nil
+}
+
+// IsNull returns true if the stack item has no value
+func (s *InvokeStack) IsNull() bool {
+	if s.Value == nil {
+		return true
+	}
+	if s.Type == StackTypeByteArray {
+		v, ok := s.Value.(string)
+		return ok && len(v) == 0
+	}
+	return false
+}
+
+func (s *InvokeStack) stringValue() (string, error) {
+	switch v := s.Value.(type) {
+	case string:
+		return v, nil
+	case nil:
+		return "", nil
+	}
+	return "", fmt.Errorf("invalid %s value %v", s.Type, s.Value)
+}
+
+func stackItemFromInterface(v interface{}) (InvokeStack, error) {
+	switch item := v.(type) {
+	case InvokeStack:
+		return item, nil
+	case map[string]interface{}:
+		t, ok := item["type"].(string)
+		if !ok {
+			return InvokeStack{}, fmt.Errorf("stack item has no type: %v", item)
+		}
+		return InvokeStack{Type: t, Value: item["value"]}, nil
+	}
+	return InvokeStack{}, fmt.Errorf("invalid stack item %v", v)
+}
+
+func trimHexPrefix(s string) string {
+	if len(s) >= 2 && s[0] == '0' && (s[1] == 'x' || s[1] == 'X') {
+		return s[2:]
+	}
+	return s
+}
@@ -0,0 +1,126 @@
+package models
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInvokeStack_AsBigInt(t *testing.T) {
+	s := InvokeStack{Type: "ByteArray", Value: "00203d88792d"}
+	i, err := s.AsBigInt()
+	assert.Nil(t, err)
+	assert.Equal(t, big.NewInt(50000000000000), i)
+
+	s = InvokeStack{Type: "ByteArray", Value: "ff"}
+	i, err = s.AsBigInt()
+	assert.Nil(t, err)
+	assert.Equal(t, big.NewInt(-1), i)
+
+	s = InvokeStack{Type: "Integer", Value: "-12345678901234567890"}
+	i, err = s.AsBigInt()
+	assert.Nil(t, err)
+	assert.Equal(t, "-12345678901234567890", i.String())
+
+	s = InvokeStack{Type: "Integer", Value: "abc"}
+	_, err = s.AsBigInt()
+	assert.NotNil(t, err)
+
+	s = InvokeStack{Type: "Array", Value: []interface{}{}}
+	_, err = s.AsBigInt()
+	assert.NotNil(t, err)
+}
+
+func TestInvokeStack_AsString(t *testing.T) {
+	s := InvokeStack{Type: "ByteArray", Value: "516c696e6b20546f6b656e"}
+	str, err := s.AsString()
+	assert.Nil(t, err)
+	assert.Equal(t, "Qlink Token", str)
+
+	s = InvokeStack{Type: "ByteArray", Value: 1}
+	_, err = s.AsString()
+	assert.NotNil(t, err)
+}
+
+func TestInvokeStack_AsBool(t *testing.T) {
+	s := InvokeStack{Type: "Boolean", Value: true}
+	b, err := s.AsBool()
+	assert.Nil(t, err)
+	assert.True(t, b)
+
+	s = InvokeStack{Type: "ByteArray", Value: "0000"}
+	b, err = s.AsBool()
+	assert.Nil(t, err)
+	assert.False(t, b)
+
+	s = InvokeStack{Type: "Integer", Value: "2"}
+	b, err = s.AsBool()
+	assert.Nil(t, err)
+	assert.True(t, b)
+}
+
+func TestInvokeStack_AsUInt160(t *testing.T) {
+	s := InvokeStack{Type: "ByteArray", Value: "5c564ab204122ddce30eb9a6accbfa23b27cc3ac"}
+	u, err := s.AsUInt160()
+	assert.Nil(t, err)
+	assert.Equal(t, "acc37cb223facbaca6b90ee3dc2d1204b24a565c", u.String())
+
+	s = InvokeStack{Type: "Hash160", Value: "0xacc37cb223facbaca6b90ee3dc2d1204b24a565c"}
+	u, err = s.AsUInt160()
+	assert.Nil(t, err)
+	assert.Equal(t, "acc37cb223facbaca6b90ee3dc2d1204b24a565c", u.String())
+
+	s = InvokeStack{Type: "ByteArray", Value: "5c56"}
+	_, err = s.AsUInt160()
+	assert.NotNil(t, err)
+}
+
+func TestInvokeStack_AsArrayAndMap(t *testing.T) {
+	var s InvokeStack
+	err := json.Unmarshal([]byte(`{
+		"type": "Array",
+		"value": [
+			{"type": "Integer", "value": "1"},
+			{"type": "InteropInterface"},
+			{
+				"type": "Map",
+				"value": [
+					{
+						"key": {"type": "ByteArray", "value": "6b6579"},
+						"value": {"type": "Array", "value": [{"type": "Boolean", "value": false}]}
+					}
+				]
+			}
+		]
+	}`), &s)
+	assert.Nil(t, err)
+
+	items, err := s.AsArray()
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(items))
+
+	_, err = items[1].AsInteropInterface()
+	assert.Nil(t, err)
+
+	entries, err := items[2].AsMap()
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(entries))
+	key, err := entries[0].Key.AsString()
+	assert.Nil(t, err)
+	assert.Equal(t, "key", key)
+	values, err := entries[0].Value.AsArray()
+	assert.Nil(t, err)
+	b, err := values[0].AsBool()
+	assert.Nil(t, err)
+	assert.False(t, b)
+
+	_, err = items[0].AsMap()
+	assert.NotNil(t, err)
+
+	bad := InvokeStack{Type: "Array", Value: []interface{}{"x"}}
+	_, err = bad.AsArray()
+	assert.NotNil(t, err)
+	bad.Convert() // should not panic
+}
@@ -7,6 +7,9 @@ type RpcResponse struct {
 	ID      int    `json:"id"`
 }
 
+// MethodNotFound is the error code of a method the node does not serve, e.g. of a plugin which is not installed
+const MethodNotFound = -32601
+
 type ErrorResponse struct {
 	Error RpcError `json:"error"`
 	NetError error
@@ -19,6 +22,11 @@ func (r *ErrorResponse) HasError() bool {
 	return true
 }
 
+// IsMethodNotFound reports whether the node does not serve the method
+func (r *ErrorResponse) IsMethodNotFound() bool {
+	return r.NetError == nil && r.Error.Code == MethodNotFound
+}
+
 func (r *ErrorResponse) GetErrorInfo() string {
 	if r.NetError != nil {
 		return r.NetError.Error()
@@ -164,11 +164,20 @@ func (n *RpcClient) GetNep5Balances(address string) GetNep5BalancesResponse {
 	return response
 }
 
-// this endpoint needs RpcNep5Tracker plugin
+// this endpoint needs RpcNep5Tracker plugin, it returns the transfers of the last 7 days
 func (n *RpcClient) GetNep5Transfers(address string) GetNep5TransfersResponse {
 	response := GetNep5TransfersResponse{}
 	params := []interface{}{address}
-	response.NetError = n.makeRequest("getnep5balances", params, &response)
+	response.NetError = n.makeRequest("getnep5transfers", params, &response)
+	return response
+}
+
+// GetNep5TransfersFrom returns the transfers since the start time in seconds, 0 for all of them.
+// this endpoint needs RpcNep5Tracker plugin
+func (n *RpcClient) GetNep5TransfersFrom(address string, startTime uint32) GetNep5TransfersResponse {
+	response := GetNep5TransfersResponse{}
+	params := []interface{}{address, startTime}
+	response.NetError = n.makeRequest("getnep5transfers", params, &response)
 	return response
 }
 
@@ -272,7 +281,9 @@ func (n *RpcClient) ImportPrivKey(wif string) ImportPrivKeyResponse {
 	return response
 }
 
-func (n *RpcClient) InvokeFunction(scriptHash string, method string, checkWitnessHashes string, args ...interface{}) InvokeFunctionResponse {
+// InvokeFunction invokes the method of the contract with the typed arguments, which marshal to the json form
+// of neo-cli, e.g. sc.ContractParameter
+func (n *RpcClient) InvokeFunction(scriptHash string, method string, checkWitnessHashes string, args ...json.Marshaler) InvokeFunctionResponse {
 	response := InvokeFunctionResponse{}
 	var params []interface{}
 	if args != nil {
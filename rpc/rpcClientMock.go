@@ -1,6 +1,10 @@
 package rpc
 
-import "github.com/stretchr/testify/mock"
+import (
+	"encoding/json"
+
+	"github.com/stretchr/testify/mock"
+)
 
 type RpcClientMock struct {
 	mock.Mock
@@ -73,6 +77,10 @@ func (r *RpcClientMock) GetNep5Transfers(s string) GetNep5TransfersResponse {
 	args := r.Called(s)
 	return args.Get(0).(GetNep5TransfersResponse)
 }
+func (r *RpcClientMock) GetNep5TransfersFrom(s string, t uint32) GetNep5TransfersResponse {
+	args := r.Called(s, t)
+	return args.Get(0).(GetNep5TransfersResponse)
+}
 func (r *RpcClientMock) GetNewAddress() GetNewAddressResponse {
 	args := r.Called()
 	return args.Get(0).(GetNewAddressResponse)
@@ -131,7 +139,7 @@ func (r *RpcClientMock) ImportPrivKey(s string) ImportPrivKeyResponse {
 	args := r.Called(s)
 	return args.Get(0).(ImportPrivKeyResponse)
 }
-func (r *RpcClientMock) InvokeFunction(s1 string, s2 string, s3 string, a ...interface{}) InvokeFunctionResponse {
+func (r *RpcClientMock) InvokeFunction(s1 string, s2 string, s3 string, a ...json.Marshaler) InvokeFunctionResponse {
 	args := r.Called(s1, s2, a, s3)
 	return args.Get(0).(InvokeFunctionResponse)
 }
@@ -5,6 +5,7 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/url"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -810,10 +811,39 @@ func TestRpcClient_GetNep5Balances(t *testing.T) {
 	assert.Equal(t, "AUrE5r4NHznrgvqoFAGhoUbu96PE5YeDZY", r.Address)
 }
 
+// requestWith matches the request of the rpc method and params
+func requestWith(method string, params string) interface{} {
+	return mock.MatchedBy(func(req *http.Request) bool {
+		body, _ := ioutil.ReadAll(req.Body)
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+		return strings.Contains(string(body), `"method":"`+method+`"`) && strings.Contains(string(body), `"params":`+params)
+	})
+}
+
+func TestRpcClient_GetNep5TransfersFrom(t *testing.T) {
+	var client = new(HttpClientMock)
+	var rpc = RpcClient{Endpoint: new(url.URL), httpClient: client}
+	client.On("Do", requestWith("getnep5transfers", `["AbHgdBaWEnHkCiLtDZXjhvhaAK2cwFh5pF",0]`)).Return(&http.Response{
+		Body: ioutil.NopCloser(bytes.NewReader([]byte(`{
+			"jsonrpc": "2.0",
+			"id": 1,
+			"result": {
+				"sent": [],
+				"received": [],
+				"address": "AbHgdBaWEnHkCiLtDZXjhvhaAK2cwFh5pF"
+			}
+		}`))),
+	}, nil)
+
+	response := rpc.GetNep5TransfersFrom("AbHgdBaWEnHkCiLtDZXjhvhaAK2cwFh5pF", 0)
+	assert.False(t, response.HasError())
+	assert.Equal(t, "AbHgdBaWEnHkCiLtDZXjhvhaAK2cwFh5pF", response.Result.Address)
+}
+
 func TestRpcClient_GetNep5Transfers(t *testing.T) {
 	var client = new(HttpClientMock)
 	var rpc = RpcClient{Endpoint: new(url.URL), httpClient: client}
-	client.On("Do", mock.Anything).Return(&http.Response{
+	client.On("Do", requestWith("getnep5transfers", `[""]`)).Return(&http.Response{
 		Body: ioutil.NopCloser(bytes.NewReader([]byte(`{
 			"jsonrpc": "2.0",
 			"id": 1,
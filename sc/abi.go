@@ -0,0 +1,190 @@
+package sc
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/joeqian10/neo-gogogo/helper"
+	"github.com/joeqian10/neo-gogogo/rpc/models"
+)
+
+// ContractAbi is the .abi.json file generated by the neo 2.x compiler
+type ContractAbi struct {
+	Hash       helper.UInt160 `json:"hash"`
+	EntryPoint string         `json:"entrypoint"`
+	Functions  []*AbiFunction `json:"functions"`
+	Events     []*AbiEvent    `json:"events"`
+}
+
+// AbiFunction describes a method of the contract
+type AbiFunction struct {
+	Name       string                `json:"name"`
+	Parameters []AbiParameter        `json:"parameters"`
+	ReturnType ContractParameterType `json:"returntype"`
+}
+
+// AbiEvent describes a notification raised by the contract
+type AbiEvent struct {
+	Name       string                `json:"name"`
+	Parameters []AbiParameter        `json:"parameters"`
+	ReturnType ContractParameterType `json:"returntype"`
+}
+
+// AbiParameter describes a parameter of a function or an event
+type AbiParameter struct {
+	Name string                `json:"name"`
+	Type ContractParameterType `json:"type"`
+}
+
+// NewContractAbi parses the abi json
+func NewContractAbi(data []byte) (*ContractAbi, error) {
+	abi := &ContractAbi{}
+	if err := json.Unmarshal(data, abi); err != nil {
+		return nil, err
+	}
+	return abi, nil
+}
+
+// NewContractAbiFromFile loads the abi from the .abi.json file
+func NewContractAbiFromFile(path string) (*ContractAbi, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewContractAbi(data)
+}
+
+// GetFunction returns the function with the given name, or nil
+func (abi *ContractAbi) GetFunction(name string) *AbiFunction {
+	for _, f := range abi.Functions {
+		if f.Name == name {
+			return f
+		}
+	}
+	return nil
+}
+
+// GetEvent returns the event with the given name, or nil
+func (abi *ContractAbi) GetEvent(name string) *AbiEvent {
+	for _, e := range abi.Events {
+		if e.Name == name {
+			return e
+		}
+	}
+	return nil
+}
+
+// MakeParameters checks the go values against the parameters of the method and converts them
+func (abi *ContractAbi) MakeParameters(method string, args ...interface{}) ([]ContractParameter, error) {
+	f := abi.GetFunction(method)
+	if f == nil {
+		return nil, fmt.Errorf("method %s not found in abi", method)
+	}
+	if len(args) != len(f.Parameters) {
+		return nil, fmt.Errorf("method %s expects %d arguments, got %d", method, len(f.Parameters), len(args))
+	}
+	params := make([]ContractParameter, len(args))
+	for i, arg := range args {
+		p, err := NewContractParameter(f.Parameters[i].Type, arg)
+		if err != nil {
+			return nil, fmt.Errorf("argument %s of method %s: %v", f.Parameters[i].Name, method, err)
+		}
+		params[i] = p
+	}
+	return params, nil
+}
+
+// MakeInvocationScript builds the script invoking the method with the go values,
+// the entry point is invoked with the arguments directly
+func (abi *ContractAbi) MakeInvocationScript(method string, args ...interface{}) ([]byte, error) {
+	params, err := abi.MakeParameters(method, args...)
+	if err != nil {
+		return nil, err
+	}
+	sb := NewScriptBuilder()
+	if method == abi.EntryPoint {
+		err = sb.MakeInvocationScript(abi.Hash.Bytes(), "", params)
+	} else {
+		err = sb.MakeInvocationScript(abi.Hash.Bytes(), method, params)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return sb.ToArray(), nil
+}
+
+// DecodeResult decodes the stack item returned by the method according to its return type
+func (abi *ContractAbi) DecodeResult(method string, item models.InvokeStack) (interface{}, error) {
+	f := abi.GetFunction(method)
+	if f == nil {
+		return nil, fmt.Errorf("method %s not found in abi", method)
+	}
+	return DecodeStackItem(f.ReturnType, item)
+}
+
+// DecodeOptionalHash160 decodes a Hash160 which can be null, like the from of the transfer notification
+// raised when minting, or the to when burning: a null or empty item is decoded to nil
+func DecodeOptionalHash160(item models.InvokeStack) (*helper.UInt160, error) {
+	if item.IsNull() {
+		return nil, nil
+	}
+	u, err := item.AsUInt160()
+	if err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
+
+// DecodeStackItem converts the stack item to a go value of the declared type:
+// Boolean to bool, Integer to *big.Int, Hash160 to helper.UInt160, Hash256 to helper.UInt256,
+// String to string, ByteArray, Signature and PublicKey to []byte, Array to []interface{},
+// Map to []models.StackMapEntry, Void to nil, and Any depending on the stack item type.
+func DecodeStackItem(t ContractParameterType, item models.InvokeStack) (interface{}, error) {
+	switch t {
+	case Boolean:
+		return item.AsBool()
+	case Integer:
+		return item.AsBigInt()
+	case Hash160:
+		return item.AsUInt160()
+	case Hash256:
+		return item.AsUInt256()
+	case String:
+		return item.AsString()
+	case ByteArray, Signature, PublicKey:
+		return item.AsBytes()
+	case Array:
+		items, err := item.AsArray()
+		if err != nil {
+			return nil, err
+		}
+		result := make([]interface{}, len(items))
+		for i, it := range items {
+			result[i], err = DecodeStackItem(Any, it)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return result, nil
+	case Map:
+		return item.AsMap()
+	case InteropInterface:
+		return item.AsInteropInterface()
+	case Void:
+		return nil, nil
+	case Any:
+		st, err := ContractParameterTypeFromString(item.Type)
+		if err != nil {
+			if item.Type == models.StackTypeStruct {
+				return DecodeStackItem(Array, item)
+			}
+			return nil, err
+		}
+		if st == Any {
+			return item.Value, nil
+		}
+		return DecodeStackItem(st, item)
+	}
+	return nil, fmt.Errorf("unsupported return type %s", t)
+}
@@ -0,0 +1,127 @@
+package sc
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/joeqian10/neo-gogogo/helper"
+	"github.com/joeqian10/neo-gogogo/rpc/models"
+)
+
+const nep5AbiJson = `{
+	"hash": "0xb9d7ea3062e6aeeb3e8ad9548220c4ba1361d263",
+	"entrypoint": "Main",
+	"functions": [
+		{
+			"name": "Main",
+			"parameters": [
+				{"name": "operation", "type": "String"},
+				{"name": "args", "type": "Array"}
+			],
+			"returntype": "Any"
+		},
+		{"name": "name", "parameters": [], "returntype": "String"},
+		{"name": "decimals", "parameters": [], "returntype": "Integer"},
+		{
+			"name": "balanceOf",
+			"parameters": [{"name": "account", "type": "Hash160"}],
+			"returntype": "Integer"
+		},
+		{
+			"name": "transfer",
+			"parameters": [
+				{"name": "from", "type": "Hash160"},
+				{"name": "to", "type": "Hash160"},
+				{"name": "amount", "type": "Integer"}
+			],
+			"returntype": "Boolean"
+		}
+	],
+	"events": [
+		{
+			"name": "transfer",
+			"parameters": [
+				{"name": "arg1", "type": "ByteArray"},
+				{"name": "arg2", "type": "ByteArray"},
+				{"name": "arg3", "type": "Integer"}
+			],
+			"returntype": "Void"
+		}
+	]
+}`
+
+func TestNewContractAbi(t *testing.T) {
+	abi, err := NewContractAbi([]byte(nep5AbiJson))
+	assert.Nil(t, err)
+	assert.Equal(t, "b9d7ea3062e6aeeb3e8ad9548220c4ba1361d263", abi.Hash.String())
+	assert.Equal(t, "Main", abi.EntryPoint)
+	assert.Equal(t, 5, len(abi.Functions))
+	f := abi.GetFunction("transfer")
+	assert.Equal(t, Hash160, f.Parameters[0].Type)
+	assert.Equal(t, Boolean, f.ReturnType)
+	assert.Equal(t, Integer, abi.GetEvent("transfer").Parameters[2].Type)
+	assert.Nil(t, abi.GetFunction("mint"))
+
+	_, err = NewContractAbi([]byte(`{"functions": [{"name": "a", "returntype": "Float"}]}`))
+	assert.NotNil(t, err)
+}
+
+func TestContractAbi_MakeInvocationScript(t *testing.T) {
+	abi, _ := NewContractAbi([]byte(nep5AbiJson))
+	script, err := abi.MakeInvocationScript("balanceOf", "AUrE5r4NHznrgvqoFAGhoUbu96PE5YeDZY")
+	assert.Nil(t, err)
+	assert.Equal(t, "148f6c5be89c0cb6579e44a8bf9bfd2ecbcc11dfdc51c10962616c616e63654f666763d26113bac4208254d98a3eebaee66230ead7b9", helper.BytesToHex(script))
+
+	address, _ := helper.AddressToScriptHash("AUrE5r4NHznrgvqoFAGhoUbu96PE5YeDZY")
+	script2, err := abi.MakeInvocationScript("balanceOf", address)
+	assert.Nil(t, err)
+	assert.Equal(t, script, script2)
+
+	_, err = abi.MakeInvocationScript("balanceOf")
+	assert.NotNil(t, err)
+	_, err = abi.MakeInvocationScript("balanceOf", true)
+	assert.NotNil(t, err)
+	_, err = abi.MakeInvocationScript("mint")
+	assert.NotNil(t, err)
+
+	script, err = abi.MakeInvocationScript("transfer", address, address, big.NewInt(100))
+	assert.Nil(t, err)
+	script2, err = abi.MakeInvocationScript("Main", "transfer", []interface{}{address, address, 100})
+	assert.Nil(t, err)
+	assert.Equal(t, script, script2)
+}
+
+func TestDecodeOptionalHash160(t *testing.T) {
+	u, err := DecodeOptionalHash160(models.InvokeStack{Type: "ByteArray", Value: ""})
+	assert.Nil(t, err)
+	assert.Nil(t, u)
+
+	u, err = DecodeOptionalHash160(models.InvokeStack{Type: "Any"})
+	assert.Nil(t, err)
+	assert.Nil(t, u)
+
+	u, err = DecodeOptionalHash160(models.InvokeStack{Type: "ByteArray", Value: "5c564ab204122ddce30eb9a6accbfa23b27cc3ac"})
+	assert.Nil(t, err)
+	assert.Equal(t, "acc37cb223facbaca6b90ee3dc2d1204b24a565c", u.String())
+
+	_, err = DecodeOptionalHash160(models.InvokeStack{Type: "ByteArray", Value: "5c56"})
+	assert.NotNil(t, err)
+}
+
+func TestDecodeStackItem(t *testing.T) {
+	v, err := DecodeStackItem(Integer, models.InvokeStack{Type: "ByteArray", Value: "004eaca7902c"})
+	assert.Nil(t, err)
+	assert.Equal(t, big.NewInt(48999800000000), v)
+
+	v, err = DecodeStackItem(Any, models.InvokeStack{Type: "Array", Value: []interface{}{
+		map[string]interface{}{"type": "Integer", "value": "1"},
+		map[string]interface{}{"type": "Boolean", "value": true},
+	}})
+	assert.Nil(t, err)
+	assert.Equal(t, []interface{}{big.NewInt(1), true}, v)
+
+	_, err = DecodeStackItem(Boolean, models.InvokeStack{Type: "Map", Value: []interface{}{}})
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,300 @@
+package sc
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/joeqian10/neo-gogogo/helper"
+)
+
+var opCodeValues = func() map[string]OpCode {
+	m := make(map[string]OpCode, len(opCodeNames)+77)
+	for op, name := range opCodeNames {
+		m[name] = op
+	}
+	for op := PUSHBYTES1; op <= PUSHBYTES75; op++ {
+		m[op.String()] = op
+	}
+	m["PUSHT"] = PUSHT
+	m["PUSHF"] = PUSHF
+	return m
+}()
+
+// jumpFixup is a jump offset to be filled in once all the labels are known
+type jumpFixup struct {
+	line   int
+	offset int // offset of the instruction
+	pos    int // position of the 2 bytes jump offset
+	target string
+}
+
+// Assemble builds the script from the neo vm assembly source, one instruction per line:
+//
+//	start:                      # a label, it can also prefix an instruction
+//	PUSH "hello"                # pushes an integer, a bool, 0x hex bytes or a quoted string in the shortest form
+//	PUSHBYTES2 0x0102           # explicit pushes are emitted as they are
+//	SYSCALL Neo.Runtime.Log     # compressed syscall, use a quoted name for the full one
+//	JMPIFNOT start              # jump targets are labels or absolute hex offsets
+//	APPCALL 0xb9d7ea3062e6aeeb3e8ad9548220c4ba1361d263
+//	CALL_I 1 2 start
+//
+// Comments start with # or ;. The output of DisassembleString can be assembled back to the same script.
+func Assemble(source string) ([]byte, error) {
+	sb := NewScriptBuilder()
+	labels := map[string]int{}
+	var fixups []jumpFixup
+	for i, text := range strings.Split(source, "\n") {
+		line := i + 1
+		fields, err := splitFields(text)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", line, err)
+		}
+		for len(fields) > 0 && strings.HasSuffix(fields[0], ":") {
+			label := strings.TrimSuffix(fields[0], ":")
+			if label == "" {
+				return nil, fmt.Errorf("line %d: empty label", line)
+			}
+			if _, ok := labels[label]; ok {
+				return nil, fmt.Errorf("line %d: duplicate label %s", line, label)
+			}
+			labels[label] = sb.buff.Len()
+			fields = fields[1:]
+		}
+		if len(fields) == 0 {
+			continue
+		}
+		target, err := assembleInstruction(&sb, fields)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", line, err)
+		}
+		if target != "" {
+			// the jump offset is always the last 2 bytes of the instruction
+			fixups = append(fixups, jumpFixup{line: line, offset: sb.buff.Len() - instructionSize(fields), pos: sb.buff.Len() - 2, target: target})
+		}
+	}
+	script := sb.ToArray()
+	for _, f := range fixups {
+		target, ok := labels[f.target]
+		if !ok {
+			n, err := strconv.ParseUint(strings.TrimPrefix(f.target, "0x"), 16, 32)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: undefined label %s", f.line, f.target)
+			}
+			target = int(n)
+		}
+		offset := target - f.offset
+		if offset < -0x8000 || offset > 0x7fff {
+			return nil, fmt.Errorf("line %d: jump to %s is out of range", f.line, f.target)
+		}
+		binary.LittleEndian.PutUint16(script[f.pos:], uint16(int16(offset)))
+	}
+	return script, nil
+}
+
+// instructionSize returns the size of the jump instructions which need a fixup
+func instructionSize(fields []string) int {
+	if strings.ToUpper(fields[0]) == "CALL_I" {
+		return 5
+	}
+	return 3
+}
+
+// assembleInstruction emits the instruction, it returns the jump target if the instruction needs a fixup
+func assembleInstruction(sb *ScriptBuilder, fields []string) (string, error) {
+	mnemonic := strings.ToUpper(fields[0])
+	args := fields[1:]
+	if mnemonic == "PUSH" {
+		if len(args) != 1 {
+			return "", fmt.Errorf("PUSH expects 1 operand")
+		}
+		return "", emitPushValue(sb, args[0])
+	}
+	op, ok := opCodeValues[mnemonic]
+	if !ok {
+		return "", fmt.Errorf("unknown instruction %s", fields[0])
+	}
+	switch {
+	case op >= PUSHBYTES1 && op <= PUSHDATA4:
+		if len(args) != 1 {
+			return "", fmt.Errorf("%s expects 1 operand", op)
+		}
+		data, err := parseData(args[0])
+		if err != nil {
+			return "", err
+		}
+		return "", emitPushData(sb, op, data)
+	case op == JMP || op == JMPIF || op == JMPIFNOT || op == CALL:
+		if len(args) != 1 {
+			return "", fmt.Errorf("%s expects 1 operand", op)
+		}
+		return args[0], sb.EmitJump(op, 0)
+	case op == APPCALL || op == TAILCALL:
+		if len(args) != 1 {
+			return "", fmt.Errorf("%s expects 1 operand", op)
+		}
+		u, err := helper.UInt160FromString(args[0])
+		if err != nil {
+			return "", err
+		}
+		return "", sb.Emit(op, u.Bytes()...)
+	case op == SYSCALL:
+		if len(args) != 1 {
+			return "", fmt.Errorf("%s expects 1 operand", op)
+		}
+		return "", emitSysCall(sb, args[0])
+	case op == CALL_I || op == CALL_E || op == CALL_ET || op == CALL_ED || op == CALL_EDT:
+		expected := 2
+		if op != CALL_ED && op != CALL_EDT {
+			expected = 3
+		}
+		if len(args) != expected {
+			return "", fmt.Errorf("%s expects %d operands", op, expected)
+		}
+		rv, err := strconv.ParseUint(args[0], 0, 8)
+		if err != nil {
+			return "", err
+		}
+		pc, err := strconv.ParseUint(args[1], 0, 8)
+		if err != nil {
+			return "", err
+		}
+		operand := []byte{byte(rv), byte(pc)}
+		switch op {
+		case CALL_I:
+			return args[2], sb.Emit(op, append(operand, 0, 0)...)
+		case CALL_E, CALL_ET:
+			u, err := helper.UInt160FromString(args[2])
+			if err != nil {
+				return "", err
+			}
+			operand = append(operand, u.Bytes()...)
+		}
+		return "", sb.Emit(op, operand...)
+	}
+	if len(args) != 0 {
+		return "", fmt.Errorf("%s expects no operand", op)
+	}
+	return "", sb.Emit(op)
+}
+
+// emitPushValue emits an integer, a bool, 0x hex bytes or a quoted string with the shortest push
+func emitPushValue(sb *ScriptBuilder, arg string) error {
+	switch {
+	case arg == "true" || arg == "false":
+		return sb.EmitPushBool(arg == "true")
+	case strings.HasPrefix(arg, "0x") || strings.HasPrefix(arg, "\""):
+		data, err := parseData(arg)
+		if err != nil {
+			return err
+		}
+		if len(data) == 0 {
+			return sb.Emit(PUSH0)
+		}
+		return sb.EmitPushBytes(data)
+	}
+	n, ok := new(big.Int).SetString(arg, 10)
+	if !ok {
+		return fmt.Errorf("invalid push value %s", arg)
+	}
+	return sb.EmitPushBigInt(*n)
+}
+
+// emitPushData emits the data with the exact push op code
+func emitPushData(sb *ScriptBuilder, op OpCode, data []byte) error {
+	l := len(data)
+	var prefix []byte
+	switch op {
+	case PUSHDATA1:
+		if l > 0xff {
+			return fmt.Errorf("%s can not push %d bytes", op, l)
+		}
+		prefix = []byte{byte(l)}
+	case PUSHDATA2:
+		if l > 0xffff {
+			return fmt.Errorf("%s can not push %d bytes", op, l)
+		}
+		prefix = make([]byte, 2)
+		binary.LittleEndian.PutUint16(prefix, uint16(l))
+	case PUSHDATA4:
+		prefix = make([]byte, 4)
+		binary.LittleEndian.PutUint32(prefix, uint32(l))
+	default:
+		if l != int(op) {
+			return fmt.Errorf("%s can not push %d bytes", op, l)
+		}
+	}
+	return sb.Emit(op, append(prefix, data...)...)
+}
+
+// emitSysCall emits the compressed syscall of a bare name, the full syscall of a quoted name
+// or the raw operand of 0x hex bytes
+func emitSysCall(sb *ScriptBuilder, arg string) error {
+	if strings.HasPrefix(arg, "\"") {
+		name, err := strconv.Unquote(arg)
+		if err != nil {
+			return err
+		}
+		return sb.EmitVmSysCall(name, false)
+	}
+	if strings.HasPrefix(arg, "0x") {
+		b, err := hex.DecodeString(arg[2:])
+		if err != nil {
+			return err
+		}
+		if len(b) > 252 {
+			return fmt.Errorf("syscall operand is too long")
+		}
+		return sb.Emit(SYSCALL, append([]byte{byte(len(b))}, b...)...)
+	}
+	return sb.EmitVmSysCall(arg, true)
+}
+
+// parseData parses 0x hex bytes or a quoted string
+func parseData(arg string) ([]byte, error) {
+	if strings.HasPrefix(arg, "0x") {
+		return hex.DecodeString(arg[2:])
+	}
+	if strings.HasPrefix(arg, "\"") {
+		s, err := strconv.Unquote(arg)
+		return []byte(s), err
+	}
+	return nil, fmt.Errorf("invalid data %s", arg)
+}
+
+// splitFields splits the line by white spaces, keeping the quoted strings and dropping the comment
+func splitFields(line string) ([]string, error) {
+	var fields []string
+	for i := 0; i < len(line); {
+		c := line[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\r':
+			i++
+		case c == '#' || c == ';':
+			return fields, nil
+		case c == '"':
+			j := i + 1
+			for ; j < len(line) && line[j] != '"'; j++ {
+				if line[j] == '\\' {
+					j++
+				}
+			}
+			if j >= len(line) {
+				return nil, fmt.Errorf("unterminated string")
+			}
+			fields = append(fields, line[i:j+1])
+			i = j + 1
+		default:
+			j := i
+			for j < len(line) && !strings.ContainsRune(" \t\r#;\"", rune(line[j])) {
+				j++
+			}
+			fields = append(fields, line[i:j])
+			i = j
+		}
+	}
+	return fields, nil
+}
@@ -0,0 +1,73 @@
+package sc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/joeqian10/neo-gogogo/helper"
+)
+
+func TestAssemble(t *testing.T) {
+	script, err := Assemble(`
+		PUSH 0x8f6c5be89c0cb6579e44a8bf9bfd2ecbcc11dfdc  ; address
+		PUSH 1
+		PACK
+		PUSH "balanceOf"
+		APPCALL 0xb9d7ea3062e6aeeb3e8ad9548220c4ba1361d263
+	`)
+	assert.Nil(t, err)
+	assert.Equal(t, "148f6c5be89c0cb6579e44a8bf9bfd2ecbcc11dfdc51c10962616c616e63654f666763d26113bac4208254d98a3eebaee66230ead7b9", helper.BytesToHex(script))
+
+	script, err = Assemble(`
+		PUSH -1
+	loop:
+		DUP
+		PUSH 1000
+		LT
+		JMPIFNOT end  # forward jump
+		INC
+		JMP loop
+	end: RET
+	`)
+	assert.Nil(t, err)
+	assert.Equal(t, "4f7602e8039f6407008b62f7ff66", helper.BytesToHex(script))
+}
+
+func TestAssemble_RoundTrip(t *testing.T) {
+	sb := NewScriptBuilder()
+	sb.EmitVmSysCall("System.Runtime.CheckWitness", true)
+	sb.EmitVmSysCall("Neo.Storage.Get", false)
+	sb.EmitJump(JMPIFNOT, 5)
+	sb.EmitPushBytes(make([]byte, 256))
+	sb.Emit(PUSHDATA1, 1, 0x23)
+	sb.EmitPushString("a # b; \"c\"")
+	sb.Emit(SYSCALL, 4, 1, 2, 3, 4)
+	sb.Emit(CALL_I, 1, 2, 0xfe, 0xff)
+	sb.Emit(CALL_E, append([]byte{0, 1}, make([]byte, 20)...)...)
+	sb.Emit(CALL_EDT, 1, 0)
+	sb.Emit(RET)
+	script := sb.ToArray()
+
+	source, err := DisassembleString(script)
+	assert.Nil(t, err)
+	b, err := Assemble(source)
+	assert.Nil(t, err)
+	assert.Equal(t, script, b)
+}
+
+func TestAssemble_Invalid(t *testing.T) {
+	for _, source := range []string{
+		"FOO",
+		"JMP nowhere",
+		"a:\na: RET",
+		"PUSHBYTES2 0x01",
+		"PUSH \"unterminated",
+		"ADD 1",
+		"CALL_I 1 2",
+		"APPCALL 0x01",
+	} {
+		_, err := Assemble(source)
+		assert.NotNil(t, err, source)
+	}
+}
@@ -1,5 +1,14 @@
 package sc
 
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/joeqian10/neo-gogogo/helper"
+)
+
 type ContractParameterType byte
 
 const (
@@ -25,3 +34,169 @@ type ContractParameter struct {
 	Type  ContractParameterType
 	Value interface{}
 }
+
+// ContractParameterPair is an entry of the value of a Map parameter
+type ContractParameterPair struct {
+	Key   ContractParameter `json:"key"`
+	Value ContractParameter `json:"value"`
+}
+
+// contractParameterJson is the json form of ContractParameter used by neo-cli and the rpc
+type contractParameterJson struct {
+	Type  ContractParameterType `json:"type"`
+	Value json.RawMessage       `json:"value,omitempty"`
+}
+
+var contractParameterTypeNames = map[ContractParameterType]string{
+	Signature:        "Signature",
+	Boolean:          "Boolean",
+	Integer:          "Integer",
+	Hash160:          "Hash160",
+	Hash256:          "Hash256",
+	ByteArray:        "ByteArray",
+	PublicKey:        "PublicKey",
+	String:           "String",
+	Array:            "Array",
+	Map:              "Map",
+	InteropInterface: "InteropInterface",
+	Any:              "Any",
+	Void:             "Void",
+}
+
+// String implements the Stringer interface.
+func (t ContractParameterType) String() string {
+	if s, ok := contractParameterTypeNames[t]; ok {
+		return s
+	}
+	return fmt.Sprintf("0x%02x", byte(t))
+}
+
+// ContractParameterTypeFromString parses the type name used in abi files and rpc results
+func ContractParameterTypeFromString(s string) (ContractParameterType, error) {
+	for t, name := range contractParameterTypeNames {
+		if strings.EqualFold(name, s) {
+			return t, nil
+		}
+	}
+	return Void, fmt.Errorf("unknown contract parameter type %s", s)
+}
+
+// MarshalJSON implements the json marshaller interface.
+func (t ContractParameterType) MarshalJSON() ([]byte, error) {
+	return json.Marshal(t.String())
+}
+
+// UnmarshalJSON implements the json unmarshaller interface.
+func (t *ContractParameterType) UnmarshalJSON(data []byte) (err error) {
+	var s string
+	if err = json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	*t, err = ContractParameterTypeFromString(s)
+	return err
+}
+
+// MarshalJSON implements the json marshaller interface, the value is written as neo-cli does:
+// hex strings for byte arrays and public keys, 0x prefixed big endian hex for hashes,
+// a decimal string for integers, and nested parameters for arrays and maps.
+// Any with a value is written as the type inferred from the go value.
+func (p ContractParameter) MarshalJSON() ([]byte, error) {
+	if p.Value == nil || p.Type == InteropInterface || p.Type == Void {
+		return json.Marshal(contractParameterJson{Type: p.Type})
+	}
+	n, err := NewContractParameter(p.Type, p.Value)
+	if err != nil {
+		return nil, err
+	}
+	var v interface{}
+	switch n.Type {
+	case Signature, ByteArray, PublicKey:
+		v = helper.BytesToHex(n.Value.([]byte))
+	case Integer:
+		i := n.Value.(big.Int)
+		v = i.String()
+	case Hash160:
+		u, _ := helper.UInt160FromBytes(n.Value.([]byte))
+		v = "0x" + u.String()
+	case Hash256:
+		u, _ := helper.UInt256FromBytes(n.Value.([]byte))
+		v = "0x" + u.String()
+	default:
+		v = n.Value
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(contractParameterJson{Type: n.Type, Value: raw})
+}
+
+// UnmarshalJSON implements the json unmarshaller interface, the value is stored in the form
+// NewContractParameter produces
+func (p *ContractParameter) UnmarshalJSON(data []byte) error {
+	var j contractParameterJson
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	p.Type, p.Value = j.Type, nil
+	if len(j.Value) == 0 || string(j.Value) == "null" {
+		return nil
+	}
+	switch j.Type {
+	case Signature, ByteArray, PublicKey, Hash160, Hash256, String:
+		var s string
+		if err := json.Unmarshal(j.Value, &s); err != nil {
+			return err
+		}
+		if j.Type == String {
+			p.Value = s
+			return nil
+		}
+		n, err := NewContractParameter(j.Type, s)
+		if err != nil {
+			return err
+		}
+		p.Value = n.Value
+	case Boolean:
+		var b interface{}
+		if err := json.Unmarshal(j.Value, &b); err != nil {
+			return err
+		}
+		switch v := b.(type) {
+		case bool:
+			p.Value = v
+		case string:
+			if !strings.EqualFold(v, "true") && !strings.EqualFold(v, "false") {
+				return fmt.Errorf("invalid boolean %s", v)
+			}
+			p.Value = strings.EqualFold(v, "true")
+		default:
+			return fmt.Errorf("invalid boolean %s", string(j.Value))
+		}
+	case Integer:
+		var n json.Number
+		if err := json.Unmarshal(j.Value, &n); err != nil {
+			return err
+		}
+		i, ok := new(big.Int).SetString(n.String(), 10)
+		if !ok {
+			return fmt.Errorf("invalid integer %s", n.String())
+		}
+		p.Value = *i
+	case Array:
+		var a []ContractParameter
+		if err := json.Unmarshal(j.Value, &a); err != nil {
+			return err
+		}
+		p.Value = a
+	case Map:
+		var m []ContractParameterPair
+		if err := json.Unmarshal(j.Value, &m); err != nil {
+			return err
+		}
+		p.Value = m
+	default:
+		return fmt.Errorf("%s parameter cannot have a value", j.Type)
+	}
+	return nil
+}
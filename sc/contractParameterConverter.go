@@ -0,0 +1,288 @@
+package sc
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/joeqian10/neo-gogogo/helper"
+)
+
+// compressedPublicKey is implemented by keys.PublicKey
+type compressedPublicKey interface {
+	EncodeCompression() []byte
+}
+
+// NewContractParameter converts a go value to a ContractParameter of type t, the value
+// is stored in the form which ScriptBuilder.EmitPushParameter expects.
+// Any infers the type from the go value, slices become Array and maps become Map.
+func NewContractParameter(t ContractParameterType, value interface{}) (ContractParameter, error) {
+	if p, ok := value.(ContractParameter); ok {
+		if p.Type != t && t != Any {
+			return p, fmt.Errorf("expected %s parameter, got %s", t, p.Type)
+		}
+		return p, nil
+	}
+	var err error
+	p := ContractParameter{Type: t}
+	switch t {
+	case Signature:
+		p.Value, err = toBytes(value)
+		if err == nil && len(p.Value.([]byte)) != 64 {
+			err = fmt.Errorf("signature should be 64 bytes")
+		}
+	case Boolean:
+		b, ok := value.(bool)
+		if !ok {
+			return p, typeMismatch(t, value)
+		}
+		p.Value = b
+	case Integer:
+		var i *big.Int
+		i, err = toBigInt(value)
+		if err == nil {
+			p.Value = *i
+		}
+	case Hash160:
+		var u helper.UInt160
+		u, err = toUInt160(value)
+		p.Value = u.Bytes()
+	case Hash256:
+		var u helper.UInt256
+		u, err = toUInt256(value)
+		p.Value = u.Bytes()
+	case ByteArray:
+		p.Value, err = toBytes(value)
+	case PublicKey:
+		if k, ok := toCompressedPublicKey(value); ok {
+			p.Value = k.EncodeCompression()
+		} else {
+			p.Value, err = toBytes(value)
+			if err == nil && len(p.Value.([]byte)) != 33 {
+				err = fmt.Errorf("compressed public key should be 33 bytes")
+			}
+		}
+	case String:
+		s, ok := value.(string)
+		if !ok {
+			return p, typeMismatch(t, value)
+		}
+		p.Value = s
+	case Array:
+		p.Value, err = toParameterArray(value)
+	case Map:
+		p.Value, err = toParameterMap(value)
+	case Any:
+		return inferContractParameter(value)
+	default:
+		return p, fmt.Errorf("%s parameter is not supported", t)
+	}
+	return p, err
+}
+
+func inferContractParameter(value interface{}) (ContractParameter, error) {
+	switch value.(type) {
+	case bool:
+		return NewContractParameter(Boolean, value)
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, big.Int, *big.Int, helper.Fixed8:
+		return NewContractParameter(Integer, value)
+	case string:
+		return NewContractParameter(String, value)
+	case []byte:
+		return NewContractParameter(ByteArray, value)
+	case helper.UInt160, *helper.UInt160:
+		return NewContractParameter(Hash160, value)
+	case helper.UInt256, *helper.UInt256:
+		return NewContractParameter(Hash256, value)
+	case []ContractParameterPair:
+		return NewContractParameter(Map, value)
+	}
+	if _, ok := toCompressedPublicKey(value); ok {
+		return NewContractParameter(PublicKey, value)
+	}
+	if value != nil {
+		switch reflect.TypeOf(value).Kind() {
+		case reflect.Slice:
+			return NewContractParameter(Array, value)
+		case reflect.Map:
+			return NewContractParameter(Map, value)
+		}
+	}
+	return ContractParameter{}, fmt.Errorf("cannot infer contract parameter type of %T", value)
+}
+
+// toCompressedPublicKey accepts both keys.PublicKey and *keys.PublicKey
+func toCompressedPublicKey(value interface{}) (compressedPublicKey, bool) {
+	if value == nil {
+		return nil, false
+	}
+	rv := reflect.ValueOf(value)
+	if rv.Kind() == reflect.Ptr && rv.IsNil() {
+		return nil, false
+	}
+	if k, ok := value.(compressedPublicKey); ok {
+		return k, true
+	}
+	if rv.Kind() == reflect.Struct {
+		ptr := reflect.New(rv.Type())
+		ptr.Elem().Set(rv)
+		k, ok := ptr.Interface().(compressedPublicKey)
+		return k, ok
+	}
+	return nil, false
+}
+
+func typeMismatch(t ContractParameterType, value interface{}) error {
+	return fmt.Errorf("cannot convert %T to %s parameter", value, t)
+}
+
+func toBytes(value interface{}) ([]byte, error) {
+	switch v := value.(type) {
+	case []byte:
+		return v, nil
+	case string: // hex string
+		b := helper.HexToBytes(strings.TrimPrefix(v, "0x"))
+		if b == nil && len(v) != 0 {
+			return nil, fmt.Errorf("invalid hex string %s", v)
+		}
+		return b, nil
+	}
+	return nil, typeMismatch(ByteArray, value)
+}
+
+func toBigInt(value interface{}) (*big.Int, error) {
+	switch v := value.(type) {
+	case int:
+		return big.NewInt(int64(v)), nil
+	case int8:
+		return big.NewInt(int64(v)), nil
+	case int16:
+		return big.NewInt(int64(v)), nil
+	case int32:
+		return big.NewInt(int64(v)), nil
+	case int64:
+		return big.NewInt(v), nil
+	case uint:
+		return new(big.Int).SetUint64(uint64(v)), nil
+	case uint8:
+		return big.NewInt(int64(v)), nil
+	case uint16:
+		return big.NewInt(int64(v)), nil
+	case uint32:
+		return big.NewInt(int64(v)), nil
+	case uint64:
+		return new(big.Int).SetUint64(v), nil
+	case big.Int:
+		return &v, nil
+	case *big.Int:
+		if v == nil {
+			return nil, fmt.Errorf("nil integer")
+		}
+		return v, nil
+	case helper.Fixed8:
+		return big.NewInt(v.Value), nil
+	case string:
+		i, ok := new(big.Int).SetString(v, 10)
+		if !ok {
+			return nil, fmt.Errorf("invalid integer %s", v)
+		}
+		return i, nil
+	}
+	return nil, typeMismatch(Integer, value)
+}
+
+func toUInt160(value interface{}) (helper.UInt160, error) {
+	switch v := value.(type) {
+	case helper.UInt160:
+		return v, nil
+	case *helper.UInt160:
+		return *v, nil
+	case []byte:
+		return helper.UInt160FromBytes(v)
+	case string: // address or big endian hex string
+		if len(v) == 34 && !strings.HasPrefix(v, "0x") {
+			return helper.AddressToScriptHash(v)
+		}
+		return helper.UInt160FromString(v)
+	}
+	return helper.UInt160{}, typeMismatch(Hash160, value)
+}
+
+func toUInt256(value interface{}) (helper.UInt256, error) {
+	switch v := value.(type) {
+	case helper.UInt256:
+		return v, nil
+	case *helper.UInt256:
+		return *v, nil
+	case []byte:
+		return helper.UInt256FromBytes(v)
+	case string: // big endian hex string
+		return helper.UInt256FromString(v)
+	}
+	return helper.UInt256{}, typeMismatch(Hash256, value)
+}
+
+func toParameterArray(value interface{}) ([]ContractParameter, error) {
+	switch v := value.(type) {
+	case []ContractParameter:
+		return v, nil
+	case []byte:
+		return nil, typeMismatch(Array, value)
+	}
+	if value == nil || reflect.TypeOf(value).Kind() != reflect.Slice {
+		return nil, typeMismatch(Array, value)
+	}
+	rv := reflect.ValueOf(value)
+	result := make([]ContractParameter, rv.Len())
+	for i := 0; i < rv.Len(); i++ {
+		p, err := NewContractParameter(Any, rv.Index(i).Interface())
+		if err != nil {
+			return nil, err
+		}
+		result[i] = p
+	}
+	return result, nil
+}
+
+// toParameterMap converts a go map to the pairs sorted by the pushed keys, so that the script is deterministic
+func toParameterMap(value interface{}) ([]ContractParameterPair, error) {
+	if v, ok := value.([]ContractParameterPair); ok {
+		return v, nil
+	}
+	if value == nil || reflect.TypeOf(value).Kind() != reflect.Map {
+		return nil, typeMismatch(Map, value)
+	}
+	type entry struct {
+		pair   ContractParameterPair
+		script []byte
+	}
+	rv := reflect.ValueOf(value)
+	entries := make([]entry, 0, rv.Len())
+	iter := rv.MapRange()
+	for iter.Next() {
+		k, err := NewContractParameter(Any, iter.Key().Interface())
+		if err != nil {
+			return nil, err
+		}
+		v, err := NewContractParameter(Any, iter.Value().Interface())
+		if err != nil {
+			return nil, err
+		}
+		sb := NewScriptBuilder()
+		if err = sb.EmitPushParameter(k); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry{pair: ContractParameterPair{Key: k, Value: v}, script: sb.ToArray()})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return bytes.Compare(entries[i].script, entries[j].script) < 0
+	})
+	result := make([]ContractParameterPair, len(entries))
+	for i, e := range entries {
+		result[i] = e.pair
+	}
+	return result, nil
+}
@@ -0,0 +1,42 @@
+package sc
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/joeqian10/neo-gogogo/helper"
+)
+
+func TestNewContractParameter(t *testing.T) {
+	p, err := NewContractParameter(Integer, int64(-5))
+	assert.Nil(t, err)
+	assert.Equal(t, *big.NewInt(-5), p.Value)
+
+	p, err = NewContractParameter(Hash160, "0xb9d7ea3062e6aeeb3e8ad9548220c4ba1361d263")
+	assert.Nil(t, err)
+	assert.Equal(t, "63d26113bac4208254d98a3eebaee66230ead7b9", helper.BytesToHex(p.Value.([]byte)))
+
+	p, err = NewContractParameter(Any, []interface{}{"a", []byte{1}, helper.UInt256{}})
+	assert.Nil(t, err)
+	assert.Equal(t, Array, p.Type)
+	a := p.Value.([]ContractParameter)
+	assert.Equal(t, String, a[0].Type)
+	assert.Equal(t, ByteArray, a[1].Type)
+	assert.Equal(t, Hash256, a[2].Type)
+
+	p, err = NewContractParameter(Any, map[int64]string{2: "b", 1: "a"})
+	assert.Nil(t, err)
+	assert.Equal(t, Map, p.Type)
+	m := p.Value.([]ContractParameterPair)
+	assert.Equal(t, *big.NewInt(1), m[0].Key.Value)
+	assert.Equal(t, "b", m[1].Value.Value)
+
+	_, err = NewContractParameter(Boolean, 1)
+	assert.NotNil(t, err)
+	_, err = NewContractParameter(PublicKey, []byte{1, 2})
+	assert.NotNil(t, err)
+	_, err = NewContractParameter(Any, struct{}{})
+	assert.NotNil(t, err)
+}
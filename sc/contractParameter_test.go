@@ -0,0 +1,81 @@
+package sc
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/joeqian10/neo-gogogo/helper"
+)
+
+func TestContractParameter_MarshalJSON(t *testing.T) {
+	u, _ := helper.UInt160FromString("b9d7ea3062e6aeeb3e8ad9548220c4ba1361d263")
+	params := []ContractParameter{
+		{Type: Hash160, Value: u},
+		{Type: Integer, Value: *big.NewInt(-100)},
+		{Type: ByteArray, Value: []byte{1, 2}},
+		{Type: Boolean, Value: true},
+		{Type: Array, Value: []ContractParameter{{Type: String, Value: "a"}}},
+		{Type: Map, Value: []ContractParameterPair{{
+			Key:   ContractParameter{Type: String, Value: "k"},
+			Value: ContractParameter{Type: Integer, Value: 1},
+		}}},
+		{Type: Any},
+	}
+	b, err := json.Marshal(params)
+	assert.Nil(t, err)
+	assert.Equal(t, `[{"type":"Hash160","value":"0xb9d7ea3062e6aeeb3e8ad9548220c4ba1361d263"},`+
+		`{"type":"Integer","value":"-100"},`+
+		`{"type":"ByteArray","value":"0102"},`+
+		`{"type":"Boolean","value":true},`+
+		`{"type":"Array","value":[{"type":"String","value":"a"}]},`+
+		`{"type":"Map","value":[{"key":{"type":"String","value":"k"},"value":{"type":"Integer","value":"1"}}]},`+
+		`{"type":"Any"}]`, string(b))
+
+	var decoded []ContractParameter
+	assert.Nil(t, json.Unmarshal(b, &decoded))
+	assert.Equal(t, u.Bytes(), decoded[0].Value)
+	assert.Equal(t, *big.NewInt(-100), decoded[1].Value)
+	assert.Equal(t, []byte{1, 2}, decoded[2].Value)
+	assert.Equal(t, true, decoded[3].Value)
+	assert.Equal(t, "a", decoded[4].Value.([]ContractParameter)[0].Value)
+	m := decoded[5].Value.([]ContractParameterPair)
+	assert.Equal(t, "k", m[0].Key.Value)
+	assert.Equal(t, *big.NewInt(1), m[0].Value.Value)
+	assert.Equal(t, Any, decoded[6].Type)
+	assert.Nil(t, decoded[6].Value)
+
+	b2, err := json.Marshal(decoded)
+	assert.Nil(t, err)
+	assert.Equal(t, string(b), string(b2))
+}
+
+func TestContractParameter_UnmarshalJSON(t *testing.T) {
+	var p ContractParameter
+	assert.Nil(t, json.Unmarshal([]byte(`{"type":"Integer","value":12}`), &p))
+	assert.Equal(t, *big.NewInt(12), p.Value)
+	assert.Nil(t, json.Unmarshal([]byte(`{"type":"Boolean","value":"false"}`), &p))
+	assert.Equal(t, false, p.Value)
+	assert.Nil(t, json.Unmarshal([]byte(`{"type":"Hash256","value":"0xc56f33fc6ecfcd0c225c4ab356fee59390af8560be0e930faebe74a6daff7c9b"}`), &p))
+	assert.Equal(t, "9b7cffdaa674beae0f930ebe6085af9093e5fe56b34a5c220ccdcf6efc336fc5", helper.BytesToHex(p.Value.([]byte)))
+
+	for _, s := range []string{
+		`{"type":"Unknown","value":"1"}`,
+		`{"type":"Integer","value":"1.5"}`,
+		`{"type":"PublicKey","value":"0102"}`,
+		`{"type":"Boolean","value":"yes"}`,
+		`{"type":"Void","value":"1"}`,
+	} {
+		assert.NotNil(t, json.Unmarshal([]byte(s), &p), s)
+	}
+}
+
+func TestContractParameter_InvokeFunctionArgument(t *testing.T) {
+	// the arguments of rpc.IRpcClient.InvokeFunction
+	args := []json.Marshaler{ContractParameter{String, "a"}, ContractParameter{Integer, 1}}
+	data, err := json.Marshal(args)
+	assert.Nil(t, err)
+	assert.Equal(t, `[{"type":"String","value":"a"},{"type":"Integer","value":"1"}]`, string(data))
+}
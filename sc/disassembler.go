@@ -0,0 +1,202 @@
+package sc
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strings"
+
+	"github.com/joeqian10/neo-gogogo/helper"
+)
+
+var opCodeNames = map[OpCode]string{
+	PUSH0: "PUSH0", PUSHDATA1: "PUSHDATA1", PUSHDATA2: "PUSHDATA2", PUSHDATA4: "PUSHDATA4", PUSHM1: "PUSHM1",
+	PUSH1: "PUSH1", PUSH2: "PUSH2", PUSH3: "PUSH3", PUSH4: "PUSH4", PUSH5: "PUSH5", PUSH6: "PUSH6",
+	PUSH7: "PUSH7", PUSH8: "PUSH8", PUSH9: "PUSH9", PUSH10: "PUSH10", PUSH11: "PUSH11", PUSH12: "PUSH12",
+	PUSH13: "PUSH13", PUSH14: "PUSH14", PUSH15: "PUSH15", PUSH16: "PUSH16",
+	NOP: "NOP", JMP: "JMP", JMPIF: "JMPIF", JMPIFNOT: "JMPIFNOT", CALL: "CALL", RET: "RET",
+	APPCALL: "APPCALL", SYSCALL: "SYSCALL", TAILCALL: "TAILCALL",
+	DUPFROMALTSTACK: "DUPFROMALTSTACK", TOALTSTACK: "TOALTSTACK", FROMALTSTACK: "FROMALTSTACK",
+	XDROP: "XDROP", DUPFROMALTSTACKBOTTOM: "DUPFROMALTSTACKBOTTOM",
+	XSWAP: "XSWAP", XTUCK: "XTUCK", DEPTH: "DEPTH", DROP: "DROP", DUP: "DUP", NIP: "NIP", OVER: "OVER",
+	PICK: "PICK", ROLL: "ROLL", ROT: "ROT", SWAP: "SWAP", TUCK: "TUCK",
+	CAT: "CAT", SUBSTR: "SUBSTR", LEFT: "LEFT", RIGHT: "RIGHT", SIZE: "SIZE",
+	INVERT: "INVERT", AND: "AND", OR: "OR", XOR: "XOR", EQUAL: "EQUAL",
+	INC: "INC", DEC: "DEC", SIGN: "SIGN", NEGATE: "NEGATE", ABS: "ABS", NOT: "NOT", NZ: "NZ",
+	ADD: "ADD", SUB: "SUB", MUL: "MUL", DIV: "DIV", MOD: "MOD", SHL: "SHL", SHR: "SHR",
+	BOOLAND: "BOOLAND", BOOLOR: "BOOLOR", NUMEQUAL: "NUMEQUAL", NUMNOTEQUAL: "NUMNOTEQUAL",
+	LT: "LT", GT: "GT", LTE: "LTE", GTE: "GTE", MIN: "MIN", MAX: "MAX", WITHIN: "WITHIN",
+	SHA1: "SHA1", SHA256: "SHA256", HASH160: "HASH160", HASH256: "HASH256",
+	CHECKSIG: "CHECKSIG", VERIFY: "VERIFY", CHECKMULTISIG: "CHECKMULTISIG",
+	ARRAYSIZE: "ARRAYSIZE", PACK: "PACK", UNPACK: "UNPACK", PICKITEM: "PICKITEM", SETITEM: "SETITEM",
+	NEWARRAY: "NEWARRAY", NEWSTRUCT: "NEWSTRUCT", NEWMAP: "NEWMAP", APPEND: "APPEND", REVERSE: "REVERSE",
+	REMOVE: "REMOVE", HASKEY: "HASKEY", KEYS: "KEYS", VALUES: "VALUES",
+	CALL_I: "CALL_I", CALL_E: "CALL_E", CALL_ED: "CALL_ED", CALL_ET: "CALL_ET", CALL_EDT: "CALL_EDT",
+	THROW: "THROW", THROWIFNOT: "THROWIFNOT",
+}
+
+// String returns the mnemonic of the op code
+func (op OpCode) String() string {
+	if op >= PUSHBYTES1 && op <= PUSHBYTES75 {
+		return fmt.Sprintf("PUSHBYTES%d", op)
+	}
+	if name, ok := opCodeNames[op]; ok {
+		return name
+	}
+	return fmt.Sprintf("0x%02x", byte(op))
+}
+
+// IsValid reports whether the op code is defined in neo vm 2.x
+func (op OpCode) IsValid() bool {
+	_, ok := opCodeNames[op]
+	return ok || (op >= PUSHBYTES1 && op <= PUSHBYTES75)
+}
+
+// Instruction is a decoded op code with its operand
+type Instruction struct {
+	Offset  int
+	OpCode  OpCode
+	Operand []byte // pushed data, without the length prefix of PUSHDATA
+	Size    int    // total length in the script
+}
+
+// ReadInstruction decodes the instruction at the offset of the script
+func ReadInstruction(script []byte, offset int) (Instruction, error) {
+	if offset < 0 || offset >= len(script) {
+		return Instruction{}, fmt.Errorf("offset %d out of script range", offset)
+	}
+	op := OpCode(script[offset])
+	ins := Instruction{Offset: offset, OpCode: op}
+	prefix, size := 0, 0
+	switch {
+	case op >= PUSHBYTES1 && op <= PUSHBYTES75:
+		size = int(op)
+	case op == PUSHDATA1:
+		prefix = 1
+	case op == PUSHDATA2:
+		prefix = 2
+	case op == PUSHDATA4:
+		prefix = 4
+	case op == JMP || op == JMPIF || op == JMPIFNOT || op == CALL || op == CALL_ED || op == CALL_EDT:
+		size = 2
+	case op == APPCALL || op == TAILCALL:
+		size = 20
+	case op == SYSCALL:
+		prefix = 1
+	case op == CALL_I:
+		size = 4
+	case op == CALL_E || op == CALL_ET:
+		size = 22
+	case !op.IsValid():
+		return ins, fmt.Errorf("invalid op code 0x%02x at offset %d", byte(op), offset)
+	}
+	pos := offset + 1
+	if prefix > 0 {
+		if pos+prefix > len(script) {
+			return ins, fmt.Errorf("%s at offset %d is truncated", op, offset)
+		}
+		switch prefix {
+		case 1:
+			size = int(script[pos])
+		case 2:
+			size = int(binary.LittleEndian.Uint16(script[pos:]))
+		case 4:
+			n := binary.LittleEndian.Uint32(script[pos:])
+			if uint64(n) > uint64(len(script)) {
+				return ins, fmt.Errorf("%s at offset %d is truncated", op, offset)
+			}
+			size = int(n)
+		}
+		pos += prefix
+	}
+	if pos+size > len(script) {
+		return ins, fmt.Errorf("%s at offset %d is truncated", op, offset)
+	}
+	ins.Operand = script[pos : pos+size]
+	ins.Size = 1 + prefix + size
+	return ins, nil
+}
+
+// Disassemble decodes all the instructions of the script
+func Disassemble(script []byte) ([]Instruction, error) {
+	var result []Instruction
+	for offset := 0; offset < len(script); {
+		ins, err := ReadInstruction(script, offset)
+		if err != nil {
+			return result, err
+		}
+		result = append(result, ins)
+		offset += ins.Size
+	}
+	return result, nil
+}
+
+// DisassembleString returns the script in mnemonics, one instruction per line
+func DisassembleString(script []byte) (string, error) {
+	instructions, err := Disassemble(script)
+	var sb strings.Builder
+	for _, ins := range instructions {
+		sb.WriteString(fmt.Sprintf("%04x: %s\n", ins.Offset, ins.String()))
+	}
+	return sb.String(), err
+}
+
+// JumpTarget returns the absolute target offset of JMP, JMPIF, JMPIFNOT, CALL and CALL_I
+func (ins Instruction) JumpTarget() (int, bool) {
+	switch ins.OpCode {
+	case JMP, JMPIF, JMPIFNOT, CALL:
+		return ins.Offset + int(int16(binary.LittleEndian.Uint16(ins.Operand))), true
+	case CALL_I:
+		return ins.Offset + int(int16(binary.LittleEndian.Uint16(ins.Operand[2:]))), true
+	}
+	return 0, false
+}
+
+// String returns the instruction in mnemonic, e.g. "PUSHBYTES4 0x01020304", "JMPIFNOT 0012",
+// "APPCALL 0xb9d7ea3062e6aeeb3e8ad9548220c4ba1361d263" or "SYSCALL System.Runtime.CheckWitness",
+// a syscall by the full name instead of the compressed hash is quoted
+func (ins Instruction) String() string {
+	op := ins.OpCode
+	switch {
+	case op >= PUSHBYTES1 && op <= PUSHDATA4:
+		return op.String() + " 0x" + helper.BytesToHex(ins.Operand) + printable(ins.Operand)
+	case op == JMP || op == JMPIF || op == JMPIFNOT || op == CALL:
+		target, _ := ins.JumpTarget()
+		return fmt.Sprintf("%s %04x", op, target)
+	case op == APPCALL || op == TAILCALL:
+		return fmt.Sprintf("%s 0x%s", op, hashString(ins.Operand))
+	case op == SYSCALL:
+		if name, ok := GetInteropServiceName(ins.Operand); ok {
+			if name == string(ins.Operand) {
+				return fmt.Sprintf("%s %q", op, name)
+			}
+			return fmt.Sprintf("%s %s", op, name)
+		}
+		return fmt.Sprintf("%s 0x%s", op, helper.BytesToHex(ins.Operand))
+	case op == CALL_I:
+		target, _ := ins.JumpTarget()
+		return fmt.Sprintf("%s %d %d %04x", op, ins.Operand[0], ins.Operand[1], target)
+	case op == CALL_E || op == CALL_ET:
+		return fmt.Sprintf("%s %d %d 0x%s", op, ins.Operand[0], ins.Operand[1], hashString(ins.Operand[2:]))
+	case op == CALL_ED || op == CALL_EDT:
+		return fmt.Sprintf("%s %d %d", op, ins.Operand[0], ins.Operand[1])
+	}
+	return op.String()
+}
+
+// hashString returns the script hash in big endian
+func hashString(b []byte) string {
+	return helper.BytesToHex(helper.ReverseBytes(b))
+}
+
+// printable returns the pushed data as a quoted comment if it is a readable string
+func printable(b []byte) string {
+	if len(b) == 0 {
+		return ""
+	}
+	for _, c := range b {
+		if c < 0x20 || c > 0x7e {
+			return ""
+		}
+	}
+	return fmt.Sprintf(" # %q", string(b))
+}
@@ -0,0 +1,48 @@
+package sc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/joeqian10/neo-gogogo/helper"
+)
+
+func TestDisassembleString(t *testing.T) {
+	script := helper.HexToBytes("148f6c5be89c0cb6579e44a8bf9bfd2ecbcc11dfdc51c10962616c616e63654f666763d26113bac4208254d98a3eebaee66230ead7b9")
+	s, err := DisassembleString(script)
+	assert.Nil(t, err)
+	assert.Equal(t, "0000: PUSHBYTES20 0x8f6c5be89c0cb6579e44a8bf9bfd2ecbcc11dfdc\n"+
+		"0015: PUSH1\n"+
+		"0016: PACK\n"+
+		"0017: PUSHBYTES9 0x62616c616e63654f66 # \"balanceOf\"\n"+
+		"0021: APPCALL 0xb9d7ea3062e6aeeb3e8ad9548220c4ba1361d263\n", s)
+}
+
+func TestDisassemble(t *testing.T) {
+	sb := NewScriptBuilder()
+	sb.EmitVmSysCall("System.Runtime.CheckWitness", true)
+	sb.EmitVmSysCall("Neo.Storage.Get", false)
+	sb.EmitJump(JMPIFNOT, 5)
+	sb.EmitPushBytes(make([]byte, 256))
+	sb.Emit(SYSCALL, 4, 1, 2, 3, 4)
+	sb.Emit(CALL_I, 1, 2, 0xfe, 0xff)
+	sb.Emit(RET)
+	instructions, err := Disassemble(sb.ToArray())
+	assert.Nil(t, err)
+	assert.Equal(t, 7, len(instructions))
+	assert.Equal(t, "SYSCALL System.Runtime.CheckWitness", instructions[0].String())
+	assert.Equal(t, "SYSCALL \"Neo.Storage.Get\"", instructions[1].String())
+	assert.Equal(t, "JMPIFNOT 001c", instructions[2].String())
+	assert.Equal(t, PUSHDATA2, instructions[3].OpCode)
+	assert.Equal(t, 256, len(instructions[3].Operand))
+	assert.Equal(t, 259, instructions[3].Size)
+	assert.Equal(t, "SYSCALL 0x01020304", instructions[4].String())
+	assert.Equal(t, "CALL_I 1 2 0121", instructions[5].String())
+	assert.Equal(t, "RET", instructions[6].String())
+
+	_, err = Disassemble([]byte{byte(PUSHBYTES1 + 4), 1, 2})
+	assert.NotNil(t, err)
+	_, err = Disassemble([]byte{0x8e})
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,158 @@
+package sc
+
+import (
+	"encoding/binary"
+
+	"github.com/joeqian10/neo-gogogo/crypto"
+)
+
+// InteropServiceNames lists the interop services of neo 2.x
+var InteropServiceNames = []string{
+	"System.ExecutionEngine.GetScriptContainer",
+	"System.ExecutionEngine.GetExecutingScriptHash",
+	"System.ExecutionEngine.GetCallingScriptHash",
+	"System.ExecutionEngine.GetEntryScriptHash",
+	"System.Runtime.Platform",
+	"System.Runtime.GetTrigger",
+	"System.Runtime.CheckWitness",
+	"System.Runtime.Notify",
+	"System.Runtime.Log",
+	"System.Runtime.GetTime",
+	"System.Runtime.Serialize",
+	"System.Runtime.Deserialize",
+	"System.Runtime.GetInvocationCounter",
+	"System.Crypto.Verify",
+	"System.Blockchain.GetHeight",
+	"System.Blockchain.GetHeader",
+	"System.Blockchain.GetBlock",
+	"System.Blockchain.GetTransaction",
+	"System.Blockchain.GetTransactionHeight",
+	"System.Blockchain.GetContract",
+	"System.Header.GetIndex",
+	"System.Header.GetHash",
+	"System.Header.GetPrevHash",
+	"System.Header.GetTimestamp",
+	"System.Block.GetTransactionCount",
+	"System.Block.GetTransactions",
+	"System.Block.GetTransaction",
+	"System.Transaction.GetHash",
+	"System.Contract.Destroy",
+	"System.Contract.GetStorageContext",
+	"System.Storage.GetContext",
+	"System.Storage.GetReadOnlyContext",
+	"System.Storage.Get",
+	"System.Storage.Put",
+	"System.Storage.PutEx",
+	"System.Storage.Delete",
+	"System.StorageContext.AsReadOnly",
+	"Neo.Runtime.GetTrigger",
+	"Neo.Runtime.CheckWitness",
+	"Neo.Runtime.Notify",
+	"Neo.Runtime.Log",
+	"Neo.Runtime.GetTime",
+	"Neo.Runtime.Serialize",
+	"Neo.Runtime.Deserialize",
+	"Neo.Blockchain.GetHeight",
+	"Neo.Blockchain.GetHeader",
+	"Neo.Blockchain.GetBlock",
+	"Neo.Blockchain.GetTransaction",
+	"Neo.Blockchain.GetAccount",
+	"Neo.Blockchain.GetValidators",
+	"Neo.Blockchain.GetAsset",
+	"Neo.Blockchain.GetContract",
+	"Neo.Header.GetHash",
+	"Neo.Header.GetVersion",
+	"Neo.Header.GetPrevHash",
+	"Neo.Header.GetMerkleRoot",
+	"Neo.Header.GetTimestamp",
+	"Neo.Header.GetIndex",
+	"Neo.Header.GetConsensusData",
+	"Neo.Header.GetNextConsensus",
+	"Neo.Block.GetTransactionCount",
+	"Neo.Block.GetTransactions",
+	"Neo.Block.GetTransaction",
+	"Neo.Transaction.GetHash",
+	"Neo.Transaction.GetType",
+	"Neo.Transaction.GetAttributes",
+	"Neo.Transaction.GetInputs",
+	"Neo.Transaction.GetOutputs",
+	"Neo.Transaction.GetReferences",
+	"Neo.Transaction.GetUnspentCoins",
+	"Neo.Transaction.GetWitnesses",
+	"Neo.InvocationTransaction.GetScript",
+	"Neo.Witness.GetVerificationScript",
+	"Neo.Attribute.GetUsage",
+	"Neo.Attribute.GetData",
+	"Neo.Input.GetHash",
+	"Neo.Input.GetIndex",
+	"Neo.Output.GetAssetId",
+	"Neo.Output.GetValue",
+	"Neo.Output.GetScriptHash",
+	"Neo.Account.GetScriptHash",
+	"Neo.Account.GetVotes",
+	"Neo.Account.GetBalance",
+	"Neo.Account.IsStandard",
+	"Neo.Asset.Create",
+	"Neo.Asset.Renew",
+	"Neo.Asset.GetAssetId",
+	"Neo.Asset.GetAssetType",
+	"Neo.Asset.GetAmount",
+	"Neo.Asset.GetAvailable",
+	"Neo.Asset.GetPrecision",
+	"Neo.Asset.GetOwner",
+	"Neo.Asset.GetAdmin",
+	"Neo.Asset.GetIssuer",
+	"Neo.Contract.Create",
+	"Neo.Contract.Migrate",
+	"Neo.Contract.Destroy",
+	"Neo.Contract.GetScript",
+	"Neo.Contract.IsPayable",
+	"Neo.Contract.GetStorageContext",
+	"Neo.Storage.GetContext",
+	"Neo.Storage.GetReadOnlyContext",
+	"Neo.Storage.Get",
+	"Neo.Storage.Put",
+	"Neo.Storage.Delete",
+	"Neo.Storage.Find",
+	"Neo.StorageContext.AsReadOnly",
+	"Neo.Enumerator.Create",
+	"Neo.Enumerator.Next",
+	"Neo.Enumerator.Value",
+	"Neo.Enumerator.Concat",
+	"Neo.Iterator.Create",
+	"Neo.Iterator.Key",
+	"Neo.Iterator.Keys",
+	"Neo.Iterator.Values",
+	"Neo.Iterator.Concat",
+	"Neo.Iterator.Next",
+	"Neo.Iterator.Value",
+}
+
+var interopServiceHashes = func() map[uint32]string {
+	m := make(map[uint32]string, len(InteropServiceNames))
+	for _, name := range InteropServiceNames {
+		m[InteropServiceHash(name)] = name
+	}
+	return m
+}()
+
+// InteropServiceHash gets the compressed 4 bytes hash of the interop service name, as a little endian uint32
+func InteropServiceHash(name string) uint32 {
+	return binary.LittleEndian.Uint32(crypto.Sha256([]byte(name))[:4])
+}
+
+// GetInteropServiceName gets the name of a SYSCALL operand, which is either the name itself,
+// or the compressed 4 bytes hash of a known interop service
+func GetInteropServiceName(operand []byte) (string, bool) {
+	if len(operand) == 4 {
+		if name, ok := interopServiceHashes[binary.LittleEndian.Uint32(operand)]; ok {
+			return name, true
+		}
+	}
+	for _, b := range operand {
+		if b < 0x20 || b > 0x7e {
+			return "", false
+		}
+	}
+	return string(operand), len(operand) > 0
+}
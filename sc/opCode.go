@@ -44,22 +44,23 @@ const (
 	TAILCALL OpCode = 0x69
 
 	// Stack
-	DUPFROMALTSTACK OpCode = 0x6A
-	TOALTSTACK      OpCode = 0x6B // Puts the input onto the top of the alt stack. Removes it from the main stack.
-	FROMALTSTACK    OpCode = 0x6C // Puts the input onto the top of the main stack. Removes it from the alt stack.
-	XDROP           OpCode = 0x6D
-	XSWAP           OpCode = 0x72
-	XTUCK           OpCode = 0x73
-	DEPTH           OpCode = 0x74 // Puts the number of stack items onto the stack.
-	DROP            OpCode = 0x75 // Removes the top stack item.
-	DUP             OpCode = 0x76 // Duplicates the top stack item.
-	NIP             OpCode = 0x77 // Removes the second-to-top stack item.
-	OVER            OpCode = 0x78 // Copies the second-to-top stack item to the top.
-	PICK            OpCode = 0x79 // The item n back in the stack is copied to the top.
-	ROLL            OpCode = 0x7A // The item n back in the stack is moved to the top.
-	ROT             OpCode = 0x7B // The top three items on the stack are rotated to the left.
-	SWAP            OpCode = 0x7C // The top two items on the stack are swapped.
-	TUCK            OpCode = 0x7D // The item at the top of the stack is copied and inserted before the second-to-top item.
+	DUPFROMALTSTACK       OpCode = 0x6A
+	TOALTSTACK            OpCode = 0x6B // Puts the input onto the top of the alt stack. Removes it from the main stack.
+	FROMALTSTACK          OpCode = 0x6C // Puts the input onto the top of the main stack. Removes it from the alt stack.
+	XDROP                 OpCode = 0x6D
+	DUPFROMALTSTACKBOTTOM OpCode = 0x6E
+	XSWAP                 OpCode = 0x72
+	XTUCK                 OpCode = 0x73
+	DEPTH                 OpCode = 0x74 // Puts the number of stack items onto the stack.
+	DROP                  OpCode = 0x75 // Removes the top stack item.
+	DUP                   OpCode = 0x76 // Duplicates the top stack item.
+	NIP                   OpCode = 0x77 // Removes the second-to-top stack item.
+	OVER                  OpCode = 0x78 // Copies the second-to-top stack item to the top.
+	PICK                  OpCode = 0x79 // The item n back in the stack is copied to the top.
+	ROLL                  OpCode = 0x7A // The item n back in the stack is moved to the top.
+	ROT                   OpCode = 0x7B // The top three items on the stack are rotated to the left.
+	SWAP                  OpCode = 0x7C // The top two items on the stack are swapped.
+	TUCK                  OpCode = 0x7D // The item at the top of the stack is copied and inserted before the second-to-top item.
 
 	// Splice
 	CAT    OpCode = 0x7E // Concatenates two strings.
@@ -113,6 +114,7 @@ const (
 	HASH160       OpCode = 0xA9
 	HASH256       OpCode = 0xAA
 	CHECKSIG      OpCode = 0xAC
+	VERIFY        OpCode = 0xAD
 	CHECKMULTISIG OpCode = 0xAE
 
 	// Array
@@ -123,9 +125,20 @@ const (
 	SETITEM   OpCode = 0xC4
 	NEWARRAY  OpCode = 0xC5 //用作引用類型
 	NEWSTRUCT OpCode = 0xC6 //用作值類型
+	NEWMAP    OpCode = 0xC7
 	APPEND    OpCode = 0xC8
 	REVERSE   OpCode = 0xC9
 	REMOVE    OpCode = 0xCA
+	HASKEY    OpCode = 0xCB
+	KEYS      OpCode = 0xCC
+	VALUES    OpCode = 0xCD
+
+	// Stack isolation
+	CALL_I   OpCode = 0xE0
+	CALL_E   OpCode = 0xE1
+	CALL_ED  OpCode = 0xE2
+	CALL_ET  OpCode = 0xE3
+	CALL_EDT OpCode = 0xE4
 
 	// Exceptions
 	THROW      OpCode = 0xF0
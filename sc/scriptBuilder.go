@@ -22,27 +22,33 @@ func (sb *ScriptBuilder) ToArray() []byte {
 	return sb.buff.Bytes()
 }
 
-func (sb *ScriptBuilder) MakeInvocationScript(scriptHash []byte, operation string, args []ContractParameter) {
+// MakeInvocationScript emits the app call of the operation, it returns the error of the first parameter
+// which cannot be pushed
+func (sb *ScriptBuilder) MakeInvocationScript(scriptHash []byte, operation string, args []ContractParameter) error {
 	if len(operation) == 0 { // Neo.VM.Helper.cs: Line 28
 		l := len(args)
 		for i := l - 1; i >= 0; i-- {
-			sb.EmitPushParameter(args[i])
+			if err := sb.EmitPushParameter(args[i]); err != nil {
+				return err
+			}
 		}
-		sb.EmitAppCall(scriptHash, false)
+		return sb.EmitAppCall(scriptHash, false)
 	} else {
 		if args != nil { // Neo.VM.Helper.cs: Line 43
 			l := len(args)
 			for i := l - 1; i >= 0; i-- {
-				sb.EmitPushParameter(args[i])
+				if err := sb.EmitPushParameter(args[i]); err != nil {
+					return err
+				}
 			}
 			sb.EmitPushInt(l)
 			sb.Emit(PACK)
 			sb.EmitPushString(operation)
-			sb.EmitAppCall(scriptHash, false)
+			return sb.EmitAppCall(scriptHash, false)
 		} else { // Neo.VM.Helper.cs: Line 35
 			sb.EmitPushBool(false)
 			sb.EmitPushString(operation)
-			sb.EmitAppCall(scriptHash, false)
+			return sb.EmitAppCall(scriptHash, false)
 		}
 	}
 }
@@ -142,62 +148,73 @@ func (sb *ScriptBuilder) EmitPushString(data string) error {
 	return sb.EmitPushBytes([]byte(data))
 }
 
+// EmitPushParameter pushes the parameter, the value can be in the form NewContractParameter produces
+// or any go value NewContractParameter accepts for the type. Any without a value pushes an empty byte array,
+// InteropInterface and Void cannot be pushed by a script.
 func (sb *ScriptBuilder) EmitPushParameter(data ContractParameter) error {
-	var err error
 	switch data.Type {
-	case Signature:
-	case ByteArray:
-		err = sb.EmitPushBytes(data.Value.([]byte))
+	case Any:
+		if data.Value == nil {
+			return sb.Emit(PUSH0)
+		}
+	case InteropInterface, Void:
+		return fmt.Errorf("%s parameter cannot be pushed", data.Type)
+	}
+	p, err := NewContractParameter(data.Type, data.Value)
+	if err != nil {
+		return err
+	}
+	switch p.Type {
+	case Signature, ByteArray, Hash160, Hash256, PublicKey:
+		return sb.EmitPushBytes(p.Value.([]byte))
 	case Boolean:
-		err = sb.EmitPushBool(data.Value.(bool))
+		return sb.EmitPushBool(p.Value.(bool))
 	case Integer:
-		num := data.Value.(big.Int)
-		err = sb.EmitPushBigInt(num)
-	case Hash160:
-		u, e := helper.UInt160FromBytes(data.Value.([]byte))
-		if e != nil {
-			return e
-		}
-		err = sb.EmitPushBytes(u.Bytes())
-	case Hash256:
-		u, e := helper.UInt256FromBytes(data.Value.([]byte))
-		if e != nil {
-			return e
-		}
-		err = sb.EmitPushBytes(u.Bytes())
-	case PublicKey:
-		err = sb.EmitPushBytes(data.Value.([]byte))
+		return sb.EmitPushBigInt(p.Value.(big.Int))
 	case String:
-		s := string(data.Value.(string))
-		err = sb.EmitPushString(s)
+		return sb.EmitPushString(p.Value.(string))
 	case Array:
-		a := data.Value.([]ContractParameter)
+		a := p.Value.([]ContractParameter)
 		for i := len(a) - 1; i >= 0; i-- {
-			e := sb.EmitPushParameter(a[i])
-			if e != nil {
-				return e
+			if err = sb.EmitPushParameter(a[i]); err != nil {
+				return err
 			}
 		}
-		err = sb.EmitPushInt(len(a))
-		if err != nil {
+		if err = sb.EmitPushInt(len(a)); err != nil {
 			return err
 		}
-		err = sb.Emit(PACK)
-	}
-	if err != nil {
-		return err
+		return sb.Emit(PACK)
+	case Map:
+		if err = sb.Emit(NEWMAP); err != nil {
+			return err
+		}
+		for _, pair := range p.Value.([]ContractParameterPair) {
+			if pair.Key.Type == Array || pair.Key.Type == Map {
+				return fmt.Errorf("%s cannot be the key of a Map parameter", pair.Key.Type)
+			}
+			if err = sb.Emit(DUP); err != nil {
+				return err
+			}
+			if err = sb.EmitPushParameter(pair.Key); err != nil {
+				return err
+			}
+			if err = sb.EmitPushParameter(pair.Value); err != nil {
+				return err
+			}
+			if err = sb.Emit(SETITEM); err != nil {
+				return err
+			}
+		}
+		return nil
 	}
-	return nil
+	return fmt.Errorf("%s parameter cannot be pushed", p.Type)
 }
 
 func (sb *ScriptBuilder) EmitSysCall(api string, args []ContractParameter) error {
-	var err error
-	l := len(args)
-	for i := l - 1; i >= 0; i-- {
-		err = sb.EmitPushParameter(args[i])
-	}
-	if err != nil {
-		return err
+	for i := len(args) - 1; i >= 0; i-- {
+		if err := sb.EmitPushParameter(args[i]); err != nil {
+			return err
+		}
 	}
 	return sb.EmitVmSysCall(api, true)
 }
@@ -211,3 +211,55 @@ func TestScriptBuilder_ToArray(t *testing.T) {
 	b := sb.ToArray()
 	assert.Equal(t, "0c48656c6c6f20576f726c6421", helper.BytesToHex(b))
 }
+
+func TestScriptBuilder_EmitPushParameter_Map(t *testing.T) {
+	sb := NewScriptBuilder()
+	err := sb.EmitPushParameter(ContractParameter{Type: Map, Value: map[string]interface{}{
+		"b": []interface{}{int64(1), true},
+		"a": big.NewInt(-1),
+	}})
+	assert.Nil(t, err)
+	script, _ := DisassembleString(sb.ToArray())
+	assert.Equal(t, "0000: NEWMAP\n"+
+		"0001: DUP\n"+
+		"0002: PUSHBYTES1 0x61 # \"a\"\n"+
+		"0004: PUSHM1\n"+
+		"0005: SETITEM\n"+
+		"0006: DUP\n"+
+		"0007: PUSHBYTES1 0x62 # \"b\"\n"+
+		"0009: PUSH1\n"+
+		"000a: PUSH1\n"+
+		"000b: PUSH2\n"+
+		"000c: PACK\n"+
+		"000d: SETITEM\n", script)
+}
+
+func TestScriptBuilder_EmitPushParameter_Native(t *testing.T) {
+	u, _ := helper.UInt160FromString("b9d7ea3062e6aeeb3e8ad9548220c4ba1361d263")
+	sb := NewScriptBuilder()
+	assert.Nil(t, sb.EmitPushParameter(ContractParameter{Type: Hash160, Value: u}))
+	assert.Nil(t, sb.EmitPushParameter(ContractParameter{Type: Integer, Value: int64(100)}))
+	assert.Nil(t, sb.EmitPushParameter(ContractParameter{Type: Signature, Value: make([]byte, 64)}))
+	assert.Nil(t, sb.EmitPushParameter(ContractParameter{Type: Any, Value: nil}))
+	assert.Nil(t, sb.EmitPushParameter(ContractParameter{Type: Any, Value: [][]byte{{1}}}))
+	assert.Equal(t, "1463d26113bac4208254d98a3eebaee66230ead7b90164"+"40"+helper.BytesToHex(make([]byte, 64))+"00"+"010151c1",
+		helper.BytesToHex(sb.ToArray()))
+}
+
+func TestScriptBuilder_EmitPushParameter_Invalid(t *testing.T) {
+	for _, p := range []ContractParameter{
+		{Type: Boolean, Value: "true"},
+		{Type: Integer, Value: 1.5},
+		{Type: String, Value: []byte("abc")},
+		{Type: Hash160, Value: []byte{1, 2}},
+		{Type: Array, Value: 1},
+		{Type: Map, Value: []int{1}},
+		{Type: Map, Value: map[string]interface{}{"a": struct{}{}}},
+		{Type: Map, Value: []ContractParameterPair{{Key: ContractParameter{Type: Array, Value: []int{}}, Value: ContractParameter{Type: Boolean, Value: true}}}},
+		{Type: Signature, Value: []byte{1}},
+		{Type: InteropInterface, Value: nil},
+	} {
+		sb := NewScriptBuilder()
+		assert.NotNil(t, sb.EmitPushParameter(p), p.Type.String())
+	}
+}
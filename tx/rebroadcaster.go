@@ -0,0 +1,296 @@
+package tx
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/joeqian10/neo-gogogo/helper"
+	"github.com/joeqian10/neo-gogogo/helper/io"
+	"github.com/joeqian10/neo-gogogo/rpc"
+)
+
+// SignedTransaction is a transaction which can be hashed and sent as a raw transaction
+type SignedTransaction interface {
+	ITransaction
+	HashString() string
+	RawTransactionString() string
+}
+
+// FeeBump describes how a stuck transaction is replaced by one paying a higher network fee.
+// The replacement spends the same inputs, so the original and the replacement can't both confirm.
+type FeeBump struct {
+	// the extra fee is taken from the GAS output paid to this script hash
+	ChangeScriptHash helper.UInt160
+	// network fee added on each bump
+	Step helper.Fixed8
+	// total extra network fee will never exceed this value, zero means no limit
+	MaxFee helper.Fixed8
+	// number of resends before the fee is bumped
+	After int
+	// Sign re-signs the rebuilt transaction, whose witnesses have been cleared
+	Sign func(t ITransaction) error
+}
+
+// PendingTransaction is a transaction kept by the Rebroadcaster until it is confirmed
+type PendingTransaction struct {
+	Tx SignedTransaction
+	// hashes of the original transaction and all its replacements, the last one is the current
+	Hashes []string
+	// raw transaction of the current hash
+	RawTransaction string
+	Attempts       int
+	ExtraFee       helper.Fixed8
+	// hash of the transaction which is confirmed, empty if not confirmed yet
+	Confirmed string
+}
+
+// Rebroadcaster keeps signed raw transactions and resends them while they are
+// missing from the mem pool and unconfirmed.
+type Rebroadcaster struct {
+	Client   rpc.IRpcClient
+	Interval time.Duration
+	// nil disables fee bump
+	FeeBump *FeeBump
+	// OnConfirmed is called when any version of a tracked transaction is confirmed
+	OnConfirmed func(p *PendingTransaction)
+
+	// mu guards the pending set and the fields of the pending transactions, checkMu serializes Check,
+	// which is the only writer of the fields
+	mu      sync.Mutex
+	checkMu sync.Mutex
+	pending []*PendingTransaction
+}
+
+func NewRebroadcaster(client rpc.IRpcClient, interval time.Duration) *Rebroadcaster {
+	return &Rebroadcaster{
+		Client:   client,
+		Interval: interval,
+		pending:  []*PendingTransaction{},
+	}
+}
+
+// Track adds a signed transaction to the rebroadcaster, the transaction should have been sent once
+func (r *Rebroadcaster) Track(t SignedTransaction) *PendingTransaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	hash := t.HashString()
+	for _, p := range r.pending {
+		if p.Hashes[len(p.Hashes)-1] == hash {
+			return p
+		}
+	}
+	p := &PendingTransaction{
+		Tx:             t,
+		Hashes:         []string{hash},
+		RawTransaction: t.RawTransactionString(),
+		ExtraFee:       helper.Zero,
+	}
+	r.pending = append(r.pending, p)
+	return p
+}
+
+// Pending returns the transactions which are not confirmed yet
+func (r *Rebroadcaster) Pending() []*PendingTransaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	result := make([]*PendingTransaction, len(r.pending))
+	copy(result, r.pending)
+	return result
+}
+
+// Check checks all the pending transactions once, removes the confirmed ones,
+// and resends or replaces the ones missing from the mem pool. The rpc calls are made without holding
+// the lock of the pending set, so Track and Pending are not blocked by a slow node.
+func (r *Rebroadcaster) Check() error {
+	r.checkMu.Lock()
+	defer r.checkMu.Unlock()
+	pending := r.Pending()
+	if len(pending) == 0 {
+		return nil
+	}
+	response := r.Client.GetRawMemPool()
+	if response.HasError() {
+		return fmt.Errorf(response.GetErrorInfo())
+	}
+	memPool := make(map[string]bool, len(response.Result))
+	for _, h := range response.Result {
+		memPool[normalizeHash(h)] = true
+	}
+
+	var confirmed []*PendingTransaction
+	var errs []string
+	for _, p := range pending {
+		if memPool[p.Hashes[len(p.Hashes)-1]] {
+			continue
+		}
+		if r.isConfirmed(p) {
+			confirmed = append(confirmed, p)
+			continue
+		}
+		if err := r.resend(p); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if len(confirmed) != 0 {
+		r.remove(confirmed)
+		if r.OnConfirmed != nil {
+			for _, p := range confirmed {
+				r.OnConfirmed(p)
+			}
+		}
+	}
+	if len(errs) != 0 {
+		return fmt.Errorf(strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// remove removes the transactions from the pending set, those tracked meanwhile are kept
+func (r *Rebroadcaster) remove(removed []*PendingTransaction) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var remaining []*PendingTransaction
+	for _, p := range r.pending {
+		found := false
+		for _, c := range removed {
+			if p == c {
+				found = true
+				break
+			}
+		}
+		if !found {
+			remaining = append(remaining, p)
+		}
+	}
+	r.pending = remaining
+}
+
+// Run checks the pending transactions every Interval until stop is closed
+func (r *Rebroadcaster) Run(stop <-chan struct{}) {
+	ticker := time.NewTicker(r.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			_ = r.Check()
+		}
+	}
+}
+
+// any version of the transaction can be confirmed, since they spend the same inputs
+func (r *Rebroadcaster) isConfirmed(p *PendingTransaction) bool {
+	for _, hash := range p.Hashes {
+		response := r.Client.GetRawTransaction(hash)
+		if !response.HasError() && response.Result.Confirmations > 0 {
+			r.mu.Lock()
+			p.Confirmed = hash
+			r.mu.Unlock()
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Rebroadcaster) resend(p *PendingTransaction) error {
+	if r.FeeBump != nil && p.Attempts >= r.FeeBump.After {
+		if err := r.bump(p); err != nil {
+			return err
+		}
+	}
+	r.mu.Lock()
+	p.Attempts++
+	raw, hash := p.RawTransaction, p.Hashes[len(p.Hashes)-1]
+	r.mu.Unlock()
+	response := r.Client.SendRawTransaction(raw)
+	if response.HasError() {
+		return fmt.Errorf("resend %s failed: %s", hash, response.GetErrorInfo())
+	}
+	return nil
+}
+
+// bump replaces the transaction by a copy paying more fee, p is left as it was if the bump or the signature fails
+func (r *Rebroadcaster) bump(p *PendingTransaction) error {
+	f := r.FeeBump
+	extra := p.ExtraFee.Add(f.Step)
+	if !f.MaxFee.Equal(helper.Zero) && extra.GreaterThan(f.MaxFee) {
+		return nil // keep resending the current version
+	}
+	if f.Sign == nil {
+		return fmt.Errorf("no signer for fee bump")
+	}
+	t, err := copyTransaction(p.Tx)
+	if err != nil {
+		return err
+	}
+	err = BumpNetworkFee(t, f.ChangeScriptHash, f.Step)
+	if err != nil {
+		return err
+	}
+	err = f.Sign(t)
+	if err != nil {
+		return err
+	}
+	hash, raw := t.HashString(), t.RawTransactionString()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	p.Tx = t
+	p.ExtraFee = extra
+	p.Hashes = append(p.Hashes, hash)
+	p.RawTransaction = raw
+	p.Attempts = 0
+	return nil
+}
+
+// copyTransaction decodes a copy of the transaction from its raw transaction
+func copyTransaction(t SignedTransaction) (SignedTransaction, error) {
+	var c interface {
+		SignedTransaction
+		Deserialize(br *io.BinaryReader)
+	}
+	switch t.(type) {
+	case *ContractTransaction:
+		c = &ContractTransaction{Transaction: NewTransaction()}
+	case *InvocationTransaction:
+		c = &InvocationTransaction{Transaction: NewTransaction()}
+	case *ClaimTransaction:
+		c = &ClaimTransaction{Transaction: NewTransaction()}
+	default:
+		return nil, fmt.Errorf("cannot copy %T for fee bump", t)
+	}
+	br := io.NewBinaryReaderFromBuf(helper.HexToBytes(t.RawTransactionString()))
+	c.Deserialize(br)
+	if br.Err != nil {
+		return nil, br.Err
+	}
+	return c, nil
+}
+
+// BumpNetworkFee rebuilds the transaction from the same inputs, paying fee more network fee by
+// reducing the GAS output sent to changeScriptHash, and clears the witnesses so the transaction can be signed again
+func BumpNetworkFee(t ITransaction, changeScriptHash helper.UInt160, fee helper.Fixed8) error {
+	tx := t.GetTransaction()
+	for i, output := range tx.Outputs {
+		if output.AssetId != GasToken || output.ScriptHash != changeScriptHash {
+			continue
+		}
+		if output.Value.LessThan(fee) {
+			return fmt.Errorf("not enough change to pay the fee")
+		}
+		if output.Value.Equal(fee) {
+			tx.Outputs = append(tx.Outputs[:i], tx.Outputs[i+1:]...)
+		} else {
+			tx.Outputs[i] = NewTransactionOutput(GasToken, output.Value.Sub(fee), changeScriptHash)
+		}
+		tx.Witnesses = []*Witness{}
+		return nil
+	}
+	return fmt.Errorf("no GAS change output to %s", changeScriptHash.String())
+}
+
+func normalizeHash(hash string) string {
+	return strings.ToLower(strings.TrimPrefix(hash, "0x"))
+}
@@ -0,0 +1,171 @@
+package tx
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/joeqian10/neo-gogogo/helper"
+	"github.com/joeqian10/neo-gogogo/rpc"
+	"github.com/joeqian10/neo-gogogo/rpc/models"
+	"github.com/joeqian10/neo-gogogo/wallet/keys"
+)
+
+func newRebroadcastTestTx(t *testing.T) (*ContractTransaction, *keys.KeyPair) {
+	pair, err := keys.NewKeyPairFromWIF("L1caMUAsHr2dKwhqbMpYRcCzmzvZTfYZSCBefgARhz9iimAFRn1z")
+	assert.Nil(t, err)
+	from := pair.PublicKey.ScriptHash()
+	to, _ := helper.AddressToScriptHash("AKeLhhHm4hEUfLWVBCYRNjio9xhGJAom5G")
+	prevHash, _ := helper.UInt256FromString("4ee4af75d5aa60598fbae40ce86fb9a23ffec5a75dfa8b59d259d15f9e304319")
+	ctx := NewContractTransaction()
+	ctx.Inputs = []*CoinReference{{PrevHash: prevHash, PrevIndex: 0}}
+	ctx.Outputs = []*TransactionOutput{
+		NewTransactionOutput(GasToken, helper.Fixed8FromInt64(1), to),
+		NewTransactionOutput(GasToken, helper.Fixed8FromInt64(9), from),
+	}
+	err = AddSignature(ctx, pair)
+	assert.Nil(t, err)
+	return ctx, pair
+}
+
+func TestBumpNetworkFee(t *testing.T) {
+	ctx, pair := newRebroadcastTestTx(t)
+	hash := ctx.HashString()
+	err := BumpNetworkFee(ctx, pair.PublicKey.ScriptHash(), helper.Fixed8FromFloat64(0.001))
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(ctx.Witnesses))
+	assert.Equal(t, helper.Fixed8FromFloat64(8.999), ctx.Outputs[1].Value)
+	assert.NotEqual(t, hash, ctx.HashString())
+
+	err = BumpNetworkFee(ctx, pair.PublicKey.ScriptHash(), helper.Fixed8FromInt64(10))
+	assert.NotNil(t, err)
+
+	err = BumpNetworkFee(ctx, helper.UInt160{}, helper.Fixed8FromFloat64(0.001))
+	assert.NotNil(t, err)
+}
+
+func TestRebroadcaster_Check(t *testing.T) {
+	var clientMock = new(rpc.RpcClientMock)
+	ctx, _ := newRebroadcastTestTx(t)
+	r := NewRebroadcaster(clientMock, time.Second)
+	r.Track(ctx)
+
+	// in mem pool, nothing to do
+	clientMock.On("GetRawMemPool").Return(rpc.GetRawMemPoolResponse{
+		Result: []string{"0x" + ctx.HashString()},
+	}).Once()
+	err := r.Check()
+	assert.Nil(t, err)
+	clientMock.AssertNotCalled(t, "SendRawTransaction", mock.Anything)
+
+	// dropped from mem pool and unconfirmed, resend
+	clientMock.On("GetRawMemPool").Return(rpc.GetRawMemPoolResponse{Result: []string{}}).Once()
+	clientMock.On("GetRawTransaction", ctx.HashString()).Return(rpc.GetRawTransactionResponse{
+		ErrorResponse: rpc.ErrorResponse{Error: rpc.RpcError{Code: -100, Message: "Unknown transaction"}},
+	}).Once()
+	clientMock.On("SendRawTransaction", ctx.RawTransactionString()).Return(rpc.SendRawTransactionResponse{Result: true}).Once()
+	err = r.Check()
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(r.Pending()))
+	assert.Equal(t, 1, r.Pending()[0].Attempts)
+
+	// confirmed
+	var confirmed string
+	r.OnConfirmed = func(p *PendingTransaction) { confirmed = p.Confirmed }
+	clientMock.On("GetRawMemPool").Return(rpc.GetRawMemPoolResponse{Result: []string{}}).Once()
+	clientMock.On("GetRawTransaction", ctx.HashString()).Return(rpc.GetRawTransactionResponse{
+		Result: models.RpcTransaction{Confirmations: 1},
+	}).Once()
+	err = r.Check()
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(r.Pending()))
+	assert.Equal(t, ctx.HashString(), confirmed)
+}
+
+func TestRebroadcaster_TrackDuringCheck(t *testing.T) {
+	var clientMock = new(rpc.RpcClientMock)
+	ctx, _ := newRebroadcastTestTx(t)
+	r := NewRebroadcaster(clientMock, time.Second)
+	r.Track(ctx)
+	other := NewContractTransaction()
+
+	// the rpc calls do not hold the lock, and a transaction tracked meanwhile is kept
+	clientMock.On("GetRawMemPool").Return(rpc.GetRawMemPoolResponse{Result: []string{}}).Run(func(mock.Arguments) {
+		r.Track(other)
+	}).Once()
+	clientMock.On("GetRawTransaction", ctx.HashString()).Return(rpc.GetRawTransactionResponse{
+		Result: models.RpcTransaction{Confirmations: 1},
+	}).Once()
+	err := r.Check()
+	assert.Nil(t, err)
+	pending := r.Pending()
+	assert.Equal(t, 1, len(pending))
+	assert.Equal(t, other.HashString(), pending[0].Hashes[0])
+}
+
+func TestRebroadcaster_CheckWithFeeBump(t *testing.T) {
+	var clientMock = new(rpc.RpcClientMock)
+	ctx, pair := newRebroadcastTestTx(t)
+	original := ctx.HashString()
+	r := NewRebroadcaster(clientMock, time.Second)
+	r.FeeBump = &FeeBump{
+		ChangeScriptHash: pair.PublicKey.ScriptHash(),
+		Step:             helper.Fixed8FromFloat64(0.001),
+		After:            0,
+		Sign: func(t ITransaction) error {
+			return AddSignature(t, pair)
+		},
+	}
+	p := r.Track(ctx)
+
+	clientMock.On("GetRawMemPool").Return(rpc.GetRawMemPoolResponse{Result: []string{}})
+	clientMock.On("GetRawTransaction", mock.Anything).Return(rpc.GetRawTransactionResponse{
+		ErrorResponse: rpc.ErrorResponse{Error: rpc.RpcError{Code: -100, Message: "Unknown transaction"}},
+	})
+	clientMock.On("SendRawTransaction", mock.Anything).Return(rpc.SendRawTransactionResponse{Result: true})
+	err := r.Check()
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(p.Hashes))
+	assert.Equal(t, original, p.Hashes[0])
+	bumped := p.Tx.(*ContractTransaction)
+	assert.Equal(t, bumped.HashString(), p.Hashes[1])
+	assert.Equal(t, helper.Fixed8FromFloat64(0.001), p.ExtraFee)
+	assert.Equal(t, 1, len(bumped.Witnesses))
+	assert.True(t, VerifySignatureWitness(bumped.UnsignedRawTransaction(), bumped.Witnesses[0]))
+	clientMock.AssertCalled(t, "SendRawTransaction", bumped.RawTransactionString())
+	// the tracked transaction is not modified
+	assert.Equal(t, original, ctx.HashString())
+	assert.Equal(t, 1, len(ctx.Witnesses))
+}
+
+func TestRebroadcaster_CheckWithFailedFeeBump(t *testing.T) {
+	var clientMock = new(rpc.RpcClientMock)
+	ctx, pair := newRebroadcastTestTx(t)
+	original := ctx.HashString()
+	raw := ctx.RawTransactionString()
+	r := NewRebroadcaster(clientMock, time.Second)
+	r.FeeBump = &FeeBump{
+		ChangeScriptHash: pair.PublicKey.ScriptHash(),
+		Step:             helper.Fixed8FromFloat64(0.001),
+		Sign: func(t ITransaction) error {
+			return fmt.Errorf("signer unavailable")
+		},
+	}
+	p := r.Track(ctx)
+
+	clientMock.On("GetRawMemPool").Return(rpc.GetRawMemPoolResponse{Result: []string{}})
+	clientMock.On("GetRawTransaction", mock.Anything).Return(rpc.GetRawTransactionResponse{
+		ErrorResponse: rpc.ErrorResponse{Error: rpc.RpcError{Code: -100, Message: "Unknown transaction"}},
+	})
+	err := r.Check()
+	assert.EqualError(t, err, "signer unavailable")
+	assert.Equal(t, []string{original}, p.Hashes)
+	assert.Equal(t, raw, p.RawTransaction)
+	assert.Equal(t, helper.Zero, p.ExtraFee)
+	assert.Equal(t, raw, ctx.RawTransactionString())
+	assert.Equal(t, 1, len(r.Pending()))
+	clientMock.AssertNotCalled(t, "SendRawTransaction", mock.Anything)
+}
@@ -138,12 +138,12 @@ type ITransaction interface {
 	UnsignedRawTransaction() []byte
 }
 
-// add signature for ITransaction
-func AddSignature(transaction ITransaction, key *keys.KeyPair) error {
-	scriptHash := key.PublicKey.ScriptHash()
+// add signature for ITransaction, the signer can be a *keys.KeyPair or any other keys.Signer
+func AddSignature(transaction ITransaction, signer keys.Signer) error {
+	scriptHash := signer.Public().ScriptHash()
 	tx := transaction.GetTransaction()
 	for _, witness := range tx.Witnesses {
-		// the transaction has been signed with this KeyPair
+		// the transaction has been signed with this key
 		if witness.scriptHash == scriptHash {
 			return nil
 		}
@@ -155,7 +155,7 @@ func AddSignature(transaction ITransaction, key *keys.KeyPair) error {
 	}
 
 	// create witness
-	witness, err := CreateSignatureWitness(transaction.UnsignedRawTransaction(), key)
+	witness, err := CreateSignatureWitness(transaction.UnsignedRawTransaction(), signer)
 	if err != nil {
 		return err
 	}
@@ -164,8 +164,8 @@ func AddSignature(transaction ITransaction, key *keys.KeyPair) error {
 	return nil
 }
 
-// add multi-signature for ITransaction
-func AddMultiSignature(transaction ITransaction, pairs []*keys.KeyPair, m int, publicKeys []*keys.PublicKey) error {
+// add multi-signature for ITransaction, use keys.KeyPairsToSigners for key pairs
+func AddMultiSignature(transaction ITransaction, signers []keys.Signer, m int, publicKeys []*keys.PublicKey) error {
 	tx := transaction.GetTransaction()
 	script, err := keys.CreateMultiSigRedeemScript(m, publicKeys...)
 	if err != nil {
@@ -178,7 +178,7 @@ func AddMultiSignature(transaction ITransaction, pairs []*keys.KeyPair, m int, p
 	}
 
 	for _, witness := range tx.Witnesses {
-		// the transaction has been signed with this KeyPair
+		// the transaction has been signed with this multi-signature contract
 		if witness.scriptHash == scriptHash {
 			return nil
 		}
@@ -190,7 +190,7 @@ func AddMultiSignature(transaction ITransaction, pairs []*keys.KeyPair, m int, p
 	}
 
 	// create witness
-	witness, err := CreateMultiSignatureWitness(transaction.UnsignedRawTransaction(), pairs, m, publicKeys)
+	witness, err := CreateMultiSignatureWitness(transaction.UnsignedRawTransaction(), signers, m, publicKeys)
 	if err != nil {
 		return err
 	}
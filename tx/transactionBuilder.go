@@ -152,14 +152,20 @@ func (tb *TransactionBuilder) GetBalance(account helper.UInt160, assetId helper.
 
 // this is a general api for invoking smart contract and creating an invocation transaction, including transferring nep-5 assets
 func (tb *TransactionBuilder) MakeInvocationTransaction(script []byte, from helper.UInt160, attributes []*TransactionAttribute, changeAddress helper.UInt160, sysFee helper.Fixed8, netFee helper.Fixed8) (*InvocationTransaction, error) {
-	if changeAddress.String() == "0000000000000000000000000000000000000000" {
-		changeAddress = from
-	}
 	// use rpc to get gas consumed
 	gasConsumed, err := tb.GetGasConsumed(script, from.String())
 	if err != nil {
 		return nil, err
 	}
+	return tb.MakeInvocationTransactionWithGas(script, from, attributes, changeAddress, *gasConsumed, sysFee, netFee)
+}
+
+// MakeInvocationTransactionWithGas builds the invocation transaction with the gas of InvocationGas,
+// for a script which has been run with InvokeScript already
+func (tb *TransactionBuilder) MakeInvocationTransactionWithGas(script []byte, from helper.UInt160, attributes []*TransactionAttribute, changeAddress helper.UInt160, gasConsumed helper.Fixed8, sysFee helper.Fixed8, netFee helper.Fixed8) (*InvocationTransaction, error) {
+	if changeAddress.String() == "0000000000000000000000000000000000000000" {
+		changeAddress = from
+	}
 	itx := NewInvocationTransaction(script)
 	if attributes != nil {
 		itx.Attributes = attributes
@@ -187,25 +193,32 @@ func (tb *TransactionBuilder) GetGasConsumed(script []byte, checkWitnessHashes s
 	if response.HasError() {
 		return nil, fmt.Errorf(response.ErrorResponse.Error.Message)
 	}
-	if response.Result.State == "FAULT" { // use ScriptContainer in contract will cause engine fault
-		result := helper.Fixed8FromInt64(0)
-		return &result, nil
+	gas, err := InvocationGas(response.Result)
+	if err != nil {
+		return nil, err
+	}
+	return &gas, nil
+}
+
+// InvocationGas returns the gas an invocation transaction pays for the result of InvokeScript,
+// the gas consumed above the free gas rounded up, zero if the script faulted
+func InvocationGas(result models.InvokeResult) (helper.Fixed8, error) {
+	if result.State == "FAULT" { // use ScriptContainer in contract will cause engine fault
+		return helper.Zero, nil
 	}
 	// transfer script will return "FAULT" when checking witness, so comment error for this issue https://github.com/neo-project/neo/pull/335
 	//if response.Result.State == "FAULT" {
 	//	return nil, fmt.Errorf("engine faulted")
 	//}
-	gasConsumed, err := helper.Fixed8FromString(response.Result.GasConsumed)
+	gasConsumed, err := helper.Fixed8FromString(result.GasConsumed)
 	if err != nil {
-		return nil, err
+		return helper.Zero, err
 	}
 	gas := gasConsumed.Sub(helper.Fixed8FromInt64(50)) // now gas free limit is 50
 	if gas.LessThan(helper.Zero) || gas.Equal(helper.Zero) {
-		return &helper.Zero, nil
-	} else {
-		g := gas.Ceiling()
-		return &g, nil
+		return helper.Zero, nil
 	}
+	return gas.Ceiling(), nil
 }
 
 func (tb *TransactionBuilder) MakeClaimTransaction(from helper.UInt160, changeAddress helper.UInt160, attributes []*TransactionAttribute) (*ClaimTransaction, error) {
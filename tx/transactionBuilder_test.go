@@ -110,6 +110,17 @@ func TestTransactionBuilder_GetGasConsumed(t *testing.T) {
 	assert.Equal(t, helper.Fixed8FromFloat64(0).Value, f.Value) // 10 gas free limit
 }
 
+func TestInvocationGas(t *testing.T) {
+	gas, err := InvocationGas(models.InvokeResult{State: "HALT", GasConsumed: "51.2"})
+	assert.Nil(t, err)
+	assert.Equal(t, helper.Fixed8FromInt64(2), gas)
+	gas, err = InvocationGas(models.InvokeResult{State: "FAULT", GasConsumed: "60"})
+	assert.Nil(t, err)
+	assert.Equal(t, helper.Zero, gas)
+	_, err = InvocationGas(models.InvokeResult{State: "HALT", GasConsumed: "x"})
+	assert.NotNil(t, err)
+}
+
 func TestTransactionBuilder_GetClaimables(t *testing.T) {
 	var clientMock = new(rpc.RpcClientMock)
 	var tb = TransactionBuilder{
@@ -48,12 +48,15 @@ func TestAddMultiSignature(t *testing.T) {
 	sort.Sort(sort.Reverse(keys.KeyPairSlice(pairs)))
 
 	ctx.Witnesses = make([]*Witness, 0)
-	err = AddMultiSignature(ctx, pairs, 2, []*keys.PublicKey{key.PublicKey, key2.PublicKey, key3.PublicKey})
+	err = AddMultiSignature(ctx, keys.KeyPairsToSigners(pairs), 2, []*keys.PublicKey{key.PublicKey, key2.PublicKey, key3.PublicKey})
 	assert.Nil(t, err)
 	assert.Equal(t, 1, len(ctx.Attributes))
+	// the signers are not reordered, the first signature is of the smallest key
+	assert.True(t, pairs[0].PublicKey.Compare(pairs[1].PublicKey) > 0)
+	sort.Sort(keys.KeyPairSlice(pairs))
 	assert.True(t, keys.VerifySignature(ctx.UnsignedRawTransaction(), ctx.Witnesses[0].InvocationScript[1:], pairs[0].PublicKey))
 
-	err = AddMultiSignature(ctx, pairs, 2, []*keys.PublicKey{key.PublicKey, key2.PublicKey, key3.PublicKey})
+	err = AddMultiSignature(ctx, keys.KeyPairsToSigners(pairs), 2, []*keys.PublicKey{key.PublicKey, key2.PublicKey, key3.PublicKey})
 	assert.Nil(t, err)
 	assert.Equal(t, 1, len(ctx.Witnesses))
 
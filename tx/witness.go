@@ -64,9 +64,9 @@ func CreateWitnessWithScriptHash(scriptHash helper.UInt160, invocationScript []b
 }
 
 // create single signature witness
-func CreateSignatureWitness(msg []byte, pair *keys.KeyPair) (witness *Witness, err error) {
+func CreateSignatureWitness(msg []byte, signer keys.Signer) (witness *Witness, err error) {
 	// 	invocationScript: push signature
-	signature, err := pair.Sign(msg)
+	signature, err := signer.Sign(msg)
 	if err != nil {
 		return
 	}
@@ -75,22 +75,23 @@ func CreateSignatureWitness(msg []byte, pair *keys.KeyPair) (witness *Witness, e
 	invocationScript := builder.ToArray() // length 65
 
 	// verificationScript: SignatureRedeemScript
-	verificationScript := keys.CreateSignatureRedeemScript(pair.PublicKey)
+	verificationScript := keys.CreateSignatureRedeemScript(signer.Public())
 	return CreateWitness(invocationScript, verificationScript)
 }
 
 // create multi-signature witness
-func CreateMultiSignatureWitness(msg []byte, pairs []*keys.KeyPair, least int, publicKeys []*keys.PublicKey) (witness *Witness, err error) {
-	if len(pairs) < least {
+func CreateMultiSignatureWitness(msg []byte, signers []keys.Signer, least int, publicKeys []*keys.PublicKey) (witness *Witness, err error) {
+	if len(signers) < least {
 		return witness, fmt.Errorf("the multi-signature contract needs least %v signatures", least)
 	}
 	// invocationScript: push signature
-	keyPairs := keys.KeyPairSlice(pairs)
-	sort.Sort(keyPairs) // ascending
+	sorted := make(keys.SignerSlice, len(signers))
+	copy(sorted, signers)
+	sort.Sort(sorted) // ascending
 
 	builder := sc.NewScriptBuilder()
-	for _, pair := range keyPairs {
-		signature, err := pair.Sign(msg)
+	for _, signer := range sorted {
+		signature, err := signer.Sign(msg)
 		if err != nil {
 			return witness, err
 		}
@@ -106,54 +107,46 @@ func CreateMultiSignatureWitness(msg []byte, pairs []*keys.KeyPair, least int, p
 	return CreateWitness(invocationScript, verificationScript)
 }
 
+// VerifySignatureWitness verifies the witness of a single signature verification script
 func VerifySignatureWitness(msg []byte, witness *Witness) bool {
-	invocationScript := witness.InvocationScript
-	length := invocationScript[0]
-	if int(length) != len(invocationScript[1:]) {
+	vs, err := keys.ParseVerificationScript(witness.VerificationScript)
+	if err != nil || vs.Kind != keys.SignatureScript {
 		return false
 	}
-	signature := invocationScript[1:]
-	verificationScript := witness.VerificationScript
-	if len(verificationScript) != 35 {
+	signatures, ok := parseSignatures(witness.InvocationScript)
+	if !ok || len(signatures) != 1 {
 		return false
 	}
-	data := verificationScript[:34] // length 34
-	publicKey, _ := keys.NewPublicKey(data[1:])
-	return keys.VerifySignature(msg, signature, publicKey)
+	return keys.VerifySignature(msg, signatures[0], vs.PublicKeys[0])
 }
 
+// VerifyMultiSignatureWitness verifies the witness of an m-of-n multi-signature verification script
 func VerifyMultiSignatureWitness(msg []byte, witness *Witness) bool {
-	invocationScript := witness.InvocationScript
-	lenInvoScript := len(invocationScript)
-	if lenInvoScript%65 != 0 {
+	vs, err := keys.ParseVerificationScript(witness.VerificationScript)
+	if err != nil || vs.Kind != keys.MultiSigScript {
 		return false
 	}
-	m := lenInvoScript / 65 // m signatures
-
-	verificationScript := witness.VerificationScript
-	least := verificationScript[0] - byte(sc.PUSH1) + 1 // least required signatures, usually 4
-
-	if m < int(least) {
+	signatures, ok := parseSignatures(witness.InvocationScript)
+	if !ok || len(signatures) < vs.M || len(signatures) > len(vs.PublicKeys) {
 		return false
-	} // not enough signatures
-	var signatures = make([][]byte, m)
-	for i := 0; i < m; i++ {
-		signatures[i] = invocationScript[i*65+1 : i*65+65] // signature length is 64
 	}
+	return keys.VerifyMultiSig(msg, signatures, vs.PublicKeys)
+}
 
-	lenVeriScript := len(verificationScript)
-	n := verificationScript[lenVeriScript-2] - byte(sc.PUSH1) + 1 // public keys, usually 7
-	if m > int(n) {
-		return false
-	} // too many signatures
-
-	var pubKeys = make([]*keys.PublicKey, n)
-	for i := 0; i < int(n); i++ {
-		data := verificationScript[i*34+1 : i*34+35] // length 34
-		publicKey, _ := keys.NewPublicKey(data[1:])
-		pubKeys[i] = publicKey
+// parseSignatures reads the 64 bytes signatures pushed by the invocation script
+func parseSignatures(invocationScript []byte) ([][]byte, bool) {
+	instructions, err := sc.Disassemble(invocationScript)
+	if err != nil || len(instructions) == 0 {
+		return nil, false
+	}
+	signatures := make([][]byte, len(instructions))
+	for i, ins := range instructions {
+		if len(ins.Operand) != 64 || ins.OpCode > sc.PUSHDATA4 {
+			return nil, false
+		}
+		signatures[i] = ins.Operand
 	}
-	return keys.VerifyMultiSig(msg, signatures, pubKeys)
+	return signatures, true
 }
 
 type WitnessSlice []*Witness
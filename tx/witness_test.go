@@ -79,7 +79,7 @@ func TestCreateMultiSignatureWitness(t *testing.T) {
 		pubKeys[i] = pair.PublicKey
 	}
 
-	witness, err := CreateMultiSignatureWitness(msg, pairs[:3], 3, pubKeys)
+	witness, err := CreateMultiSignatureWitness(msg, keys.KeyPairsToSigners(pairs[:3]), 3, pubKeys)
 	assert.Nil(t, err)
 	assert.Equal(t, 65*3, len(witness.InvocationScript))
 	assert.Equal(t, 1+34*4+1+1, len(witness.VerificationScript))
@@ -109,8 +109,26 @@ func TestVerifyMultiSignatureWitness(t *testing.T) {
 		pairs[i] = pair
 		pubKeys[i] = pair.PublicKey
 	}
-	witness, err := CreateMultiSignatureWitness(msg, pairs[:3], 3, pubKeys)
+	witness, err := CreateMultiSignatureWitness(msg, keys.KeyPairsToSigners(pairs[:3]), 3, pubKeys)
 	b := VerifyMultiSignatureWitness(msg, witness)
 	assert.Nil(t, err)
 	assert.Equal(t, true, b)
 }
+
+func TestVerifyMultiSignatureWitness_MoreThan16Keys(t *testing.T) {
+	msg := []byte("message")
+	pairs := make([]*keys.KeyPair, 20)
+	pubKeys := make([]*keys.PublicKey, 20)
+	for i := range pairs {
+		pairs[i], _ = keys.GenerateKeyPair()
+		pubKeys[i] = pairs[i].PublicKey
+	}
+	witness, err := CreateMultiSignatureWitness(msg, keys.KeyPairsToSigners(pairs[:17]), 17, pubKeys)
+	assert.Nil(t, err)
+	assert.Equal(t, true, VerifyMultiSignatureWitness(msg, witness))
+	assert.Equal(t, false, VerifySignatureWitness(msg, witness))
+
+	witness, err = CreateMultiSignatureWitness(msg, keys.KeyPairsToSigners(pairs[:17]), 17, pubKeys)
+	witness.InvocationScript = witness.InvocationScript[65:]
+	assert.Equal(t, false, VerifyMultiSignatureWitness(msg, witness))
+}
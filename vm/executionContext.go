@@ -0,0 +1,32 @@
+package vm
+
+import (
+	"github.com/joeqian10/neo-gogogo/crypto"
+)
+
+// ExecutionContext is a script loaded in the invocation stack
+type ExecutionContext struct {
+	Script             []byte
+	InstructionPointer int
+	RVCount            int // number of items returned to the caller, -1 returns all the items
+	EvaluationStack    *RandomAccessStack
+	AltStack           *RandomAccessStack
+	scriptHash         []byte
+}
+
+func NewExecutionContext(script []byte, rvcount int) *ExecutionContext {
+	return &ExecutionContext{
+		Script:          script,
+		RVCount:         rvcount,
+		EvaluationStack: NewRandomAccessStack(),
+		AltStack:        NewRandomAccessStack(),
+	}
+}
+
+// ScriptHash returns the little endian script hash of the script
+func (c *ExecutionContext) ScriptHash() []byte {
+	if c.scriptHash == nil {
+		c.scriptHash = crypto.Hash160(c.Script)
+	}
+	return c.scriptHash
+}
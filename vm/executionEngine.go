@@ -0,0 +1,604 @@
+package vm
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/joeqian10/neo-gogogo/helper"
+	"github.com/joeqian10/neo-gogogo/rpc/models"
+	"github.com/joeqian10/neo-gogogo/sc"
+)
+
+// limits of neo vm 2.x
+const (
+	MaxSizeForBigInteger   = 32
+	MaxShift               = 256
+	MaxItemSize            = 1024 * 1024
+	MaxArraySize           = 1024
+	MaxStackSize           = 2 * 1024
+	MaxInvocationStackSize = 1024
+)
+
+// GasRatio converts the op code prices to GAS, one price unit is 0.001 GAS
+const GasRatio = 100000
+
+// TestModeGas is the GAS free for InvokeScript, it is the default gas limit of the engine
+var TestModeGas = helper.Fixed8FromInt64(10)
+
+type VMState byte
+
+const (
+	NONE  VMState = 0
+	HALT  VMState = 1 << 0
+	FAULT VMState = 1 << 1
+	BREAK VMState = 1 << 2
+)
+
+func (s VMState) String() string {
+	if s == NONE {
+		return "NONE"
+	}
+	var names []string
+	if s&HALT != 0 {
+		names = append(names, "HALT")
+	}
+	if s&FAULT != 0 {
+		names = append(names, "FAULT")
+	}
+	if s&BREAK != 0 {
+		names = append(names, "BREAK")
+	}
+	return strings.Join(names, ", ")
+}
+
+// InteropService executes the SYSCALL instructions
+type InteropService interface {
+	// Invoke executes the interop service, an error faults the engine
+	Invoke(name string, engine *ExecutionEngine) error
+	// GetPrice returns the price of the interop service, in 0.001 GAS
+	GetPrice(name string, engine *ExecutionEngine) int64
+}
+
+// ScriptTable provides the scripts of the contracts called by APPCALL and TAILCALL
+type ScriptTable interface {
+	GetScript(scriptHash []byte) []byte
+}
+
+// ScriptContainer provides the message signed for CHECKSIG and CHECKMULTISIG, usually a transaction
+type ScriptContainer interface {
+	GetMessage() []byte
+}
+
+// MemoryScriptTable is a ScriptTable keyed by the script hash
+type MemoryScriptTable map[helper.UInt160][]byte
+
+func (t MemoryScriptTable) GetScript(scriptHash []byte) []byte {
+	u, err := helper.UInt160FromBytes(scriptHash)
+	if err != nil {
+		return nil
+	}
+	return t[u]
+}
+
+// Add adds the script under its script hash
+func (t MemoryScriptTable) Add(script []byte) helper.UInt160 {
+	u, _ := helper.UInt160FromBytes(NewExecutionContext(script, -1).ScriptHash())
+	t[u] = script
+	return u
+}
+
+// ExecutionEngine is a neo vm 2.x interpreter with GAS metering
+type ExecutionEngine struct {
+	ScriptContainer ScriptContainer
+	Table           ScriptTable
+	Service         InteropService
+	GasLimit        helper.Fixed8 // zero means no limit
+
+	State           VMState
+	InvocationStack []*ExecutionContext // the current context is the last one
+	ResultStack     *RandomAccessStack
+	GasConsumed     helper.Fixed8
+	FaultError      error
+
+	entryScript []byte
+}
+
+func NewExecutionEngine(container ScriptContainer, table ScriptTable, service InteropService) *ExecutionEngine {
+	return &ExecutionEngine{
+		ScriptContainer: container,
+		Table:           table,
+		Service:         service,
+		GasLimit:        TestModeGas,
+		ResultStack:     NewRandomAccessStack(),
+	}
+}
+
+// CurrentContext returns the executing context, or nil
+func (e *ExecutionEngine) CurrentContext() *ExecutionContext {
+	if len(e.InvocationStack) == 0 {
+		return nil
+	}
+	return e.InvocationStack[len(e.InvocationStack)-1]
+}
+
+// CallingContext returns the context which called the current context, or nil
+func (e *ExecutionEngine) CallingContext() *ExecutionContext {
+	if len(e.InvocationStack) < 2 {
+		return nil
+	}
+	return e.InvocationStack[len(e.InvocationStack)-2]
+}
+
+// EntryContext returns the first loaded context, or nil
+func (e *ExecutionEngine) EntryContext() *ExecutionContext {
+	if len(e.InvocationStack) == 0 {
+		return nil
+	}
+	return e.InvocationStack[0]
+}
+
+// LoadScript pushes the script onto the invocation stack
+func (e *ExecutionEngine) LoadScript(script []byte) *ExecutionContext {
+	if e.entryScript == nil {
+		e.entryScript = script
+	}
+	return e.loadScript(script, -1)
+}
+
+func (e *ExecutionEngine) loadScript(script []byte, rvcount int) *ExecutionContext {
+	if len(e.InvocationStack) >= MaxInvocationStackSize {
+		panic(fmt.Errorf("invocation stack overflow"))
+	}
+	c := NewExecutionContext(script, rvcount)
+	e.InvocationStack = append(e.InvocationStack, c)
+	return c
+}
+
+// Execute runs until HALT or FAULT
+func (e *ExecutionEngine) Execute() VMState {
+	e.State &^= BREAK
+	for e.State&(HALT|FAULT|BREAK) == 0 {
+		e.StepInto()
+	}
+	return e.State
+}
+
+// StepInto executes the next instruction of the current context
+func (e *ExecutionEngine) StepInto() {
+	if len(e.InvocationStack) == 0 {
+		e.State |= HALT
+	}
+	if e.State&(HALT|FAULT) != 0 {
+		return
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			if err, ok := r.(error); ok {
+				e.FaultError = err
+			} else {
+				e.FaultError = fmt.Errorf("%v", r)
+			}
+			e.State |= FAULT
+		}
+	}()
+	context := e.CurrentContext()
+	var ins sc.Instruction
+	if context.InstructionPointer >= len(context.Script) {
+		ins = sc.Instruction{Offset: context.InstructionPointer, OpCode: sc.RET, Size: 1}
+	} else {
+		var err error
+		ins, err = sc.ReadInstruction(context.Script, context.InstructionPointer)
+		if err != nil {
+			panic(err)
+		}
+	}
+	gas := helper.NewFixed8(e.getPrice(context, ins) * GasRatio)
+	e.GasConsumed = e.GasConsumed.Add(gas)
+	if e.GasLimit.Value > 0 && e.GasConsumed.GreaterThan(e.GasLimit) {
+		panic(fmt.Errorf("gas limit exceeded"))
+	}
+	context.InstructionPointer += ins.Size
+	e.executeOp(context, ins)
+	if e.State&HALT == 0 && e.stackSize() > MaxStackSize {
+		panic(fmt.Errorf("stack overflow"))
+	}
+}
+
+// InvokeResult returns the state, the gas consumed and the result stack, in the shape of InvokeScript
+func (e *ExecutionEngine) InvokeResult() models.InvokeResult {
+	return models.InvokeResult{
+		Script:      helper.BytesToHex(e.entryScript),
+		State:       e.State.String(),
+		GasConsumed: e.GasConsumed.String(),
+		Stack:       toInvokeStacks(e.ResultStack.Items()),
+	}
+}
+
+func (e *ExecutionEngine) stackSize() int {
+	size := e.ResultStack.Count()
+	for _, c := range e.InvocationStack {
+		size += c.EvaluationStack.Count() + c.AltStack.Count()
+	}
+	return size
+}
+
+// getPrice returns the price of the instruction in 0.001 GAS
+func (e *ExecutionEngine) getPrice(context *ExecutionContext, ins sc.Instruction) int64 {
+	switch ins.OpCode {
+	case sc.NOP:
+		return 0
+	case sc.APPCALL, sc.TAILCALL, sc.CALL_E, sc.CALL_ED, sc.CALL_ET, sc.CALL_EDT:
+		return 10
+	case sc.SYSCALL:
+		if e.Service == nil {
+			return 1
+		}
+		return e.Service.GetPrice(syscallName(ins.Operand), e)
+	case sc.SHA1, sc.SHA256:
+		return 10
+	case sc.HASH160, sc.HASH256:
+		return 20
+	case sc.CHECKSIG, sc.VERIFY:
+		return 100
+	case sc.CHECKMULTISIG:
+		if context.EvaluationStack.Count() == 0 {
+			return 1
+		}
+		item := context.EvaluationStack.Peek(0)
+		if a, ok := item.(arrayItem); ok {
+			return 100 * int64(len(a.Items()))
+		}
+		n, err := item.GetBigInteger()
+		if err != nil || !n.IsInt64() || n.Int64() < 1 {
+			return 1
+		}
+		return 100 * n.Int64()
+	}
+	if ins.OpCode <= sc.PUSH16 {
+		return 0
+	}
+	return 1
+}
+
+// syscallName returns the interop service name of the SYSCALL operand, unknown compressed hashes are returned in hex.
+// Like neo 2.x, a 4 bytes operand is always a compressed hash, even if its bytes happen to be printable.
+func syscallName(operand []byte) string {
+	if len(operand) == 4 {
+		// the names of the known services are longer than 4, a name of 4 is the operand itself
+		if name, ok := sc.GetInteropServiceName(operand); ok && len(name) != 4 {
+			return name
+		}
+		return helper.BytesToHex(operand)
+	}
+	if name, ok := sc.GetInteropServiceName(operand); ok {
+		return name
+	}
+	return helper.BytesToHex(operand)
+}
+
+func (e *ExecutionEngine) executeOp(context *ExecutionContext, ins sc.Instruction) {
+	op := ins.OpCode
+	stack := context.EvaluationStack
+	if op >= sc.PUSHBYTES1 && op <= sc.PUSHDATA4 {
+		stack.Push(NewByteArray(ins.Operand))
+		return
+	}
+	if op >= sc.PUSH1 && op <= sc.PUSH16 {
+		stack.Push(NewIntegerFromInt64(int64(op-sc.PUSH1) + 1))
+		return
+	}
+	switch op {
+	case sc.PUSH0:
+		stack.Push(NewByteArray([]byte{}))
+	case sc.PUSHM1:
+		stack.Push(NewIntegerFromInt64(-1))
+
+	// Flow control
+	case sc.NOP:
+	case sc.JMP, sc.JMPIF, sc.JMPIFNOT:
+		target, _ := ins.JumpTarget()
+		checkJumpTarget(context, target)
+		jump := true
+		if op != sc.JMP {
+			jump = stack.Pop().GetBoolean()
+			if op == sc.JMPIFNOT {
+				jump = !jump
+			}
+		}
+		if jump {
+			context.InstructionPointer = target
+		}
+	case sc.CALL:
+		target, _ := ins.JumpTarget()
+		checkJumpTarget(context, target)
+		callee := e.loadScript(context.Script, -1)
+		stack.CopyTo(callee.EvaluationStack, -1)
+		stack.Clear()
+		callee.InstructionPointer = target
+	case sc.RET:
+		e.ret()
+	case sc.APPCALL, sc.TAILCALL:
+		hash := ins.Operand
+		if isZero(hash) {
+			hash = popBytes(stack)
+		}
+		callee := e.loadScript(e.getScript(hash), -1)
+		stack.CopyTo(callee.EvaluationStack, -1)
+		if op == sc.TAILCALL {
+			e.removeContext(context)
+		} else {
+			stack.Clear()
+		}
+	case sc.SYSCALL:
+		if e.Service == nil {
+			panic(fmt.Errorf("no interop service for %s", syscallName(ins.Operand)))
+		}
+		if err := e.Service.Invoke(syscallName(ins.Operand), e); err != nil {
+			panic(err)
+		}
+	case sc.CALL_I, sc.CALL_E, sc.CALL_ED, sc.CALL_ET, sc.CALL_EDT:
+		rvcount, pcount := int(ins.Operand[0]), int(ins.Operand[1])
+		if stack.Count() < pcount {
+			panic(fmt.Errorf("%s expects %d parameters", op, pcount))
+		}
+		if (op == sc.CALL_ET || op == sc.CALL_EDT) && context.RVCount != rvcount {
+			panic(fmt.Errorf("%s return value count mismatch", op))
+		}
+		var callee *ExecutionContext
+		switch op {
+		case sc.CALL_I:
+			target, _ := ins.JumpTarget()
+			checkJumpTarget(context, target)
+			callee = e.loadScript(context.Script, rvcount)
+			callee.InstructionPointer = target
+		case sc.CALL_E, sc.CALL_ET:
+			callee = e.loadScript(e.getScript(ins.Operand[2:]), rvcount)
+		default:
+			hash := popBytes(stack)
+			if stack.Count() < pcount {
+				panic(fmt.Errorf("%s expects %d parameters", op, pcount))
+			}
+			callee = e.loadScript(e.getScript(hash), rvcount)
+		}
+		stack.CopyTo(callee.EvaluationStack, pcount)
+		for i := 0; i < pcount; i++ {
+			stack.Pop()
+		}
+		if op == sc.CALL_ET || op == sc.CALL_EDT {
+			e.removeContext(context)
+		}
+
+	// Stack
+	case sc.DUPFROMALTSTACK:
+		stack.Push(context.AltStack.Peek(0))
+	case sc.DUPFROMALTSTACKBOTTOM:
+		stack.Push(context.AltStack.Peek(context.AltStack.Count() - 1))
+	case sc.TOALTSTACK:
+		context.AltStack.Push(stack.Pop())
+	case sc.FROMALTSTACK:
+		stack.Push(context.AltStack.Pop())
+	case sc.XDROP:
+		n := popIndex(stack)
+		stack.Remove(n)
+	case sc.XSWAP:
+		n := popIndex(stack)
+		if n > 0 {
+			top := stack.Peek(0)
+			stack.Set(0, stack.Peek(n))
+			stack.Set(n, top)
+		}
+	case sc.XTUCK:
+		n := popIndex(stack)
+		if n == 0 {
+			panic(fmt.Errorf("XTUCK index should be positive"))
+		}
+		stack.Insert(n, stack.Peek(0))
+	case sc.DEPTH:
+		stack.Push(NewIntegerFromInt64(int64(stack.Count())))
+	case sc.DROP:
+		stack.Pop()
+	case sc.DUP:
+		stack.Push(stack.Peek(0))
+	case sc.NIP:
+		stack.Remove(1)
+	case sc.OVER:
+		stack.Push(stack.Peek(1))
+	case sc.PICK:
+		n := popIndex(stack)
+		stack.Push(stack.Peek(n))
+	case sc.ROLL:
+		n := popIndex(stack)
+		if n > 0 {
+			stack.Push(stack.Remove(n))
+		}
+	case sc.ROT:
+		stack.Push(stack.Remove(2))
+	case sc.SWAP:
+		stack.Push(stack.Remove(1))
+	case sc.TUCK:
+		stack.Insert(2, stack.Peek(0))
+
+	// Splice
+	case sc.CAT:
+		x2 := popBytes(stack)
+		x1 := popBytes(stack)
+		if len(x1)+len(x2) > MaxItemSize {
+			panic(fmt.Errorf("CAT result exceeds the max item size"))
+		}
+		r := make([]byte, 0, len(x1)+len(x2))
+		stack.Push(NewByteArray(append(append(r, x1...), x2...)))
+	case sc.SUBSTR:
+		count := popIndex(stack)
+		index := popIndex(stack)
+		x := popBytes(stack)
+		if index > len(x) {
+			index = len(x)
+		}
+		if index+count > len(x) {
+			count = len(x) - index
+		}
+		stack.Push(NewByteArray(x[index : index+count]))
+	case sc.LEFT:
+		count := popIndex(stack)
+		x := popBytes(stack)
+		if count < len(x) {
+			x = x[:count]
+		}
+		stack.Push(NewByteArray(x))
+	case sc.RIGHT:
+		count := popIndex(stack)
+		x := popBytes(stack)
+		if count > len(x) {
+			panic(fmt.Errorf("RIGHT count exceeds the length"))
+		}
+		stack.Push(NewByteArray(x[len(x)-count:]))
+	case sc.SIZE:
+		stack.Push(NewIntegerFromInt64(int64(len(popBytes(stack)))))
+
+	// Bitwise logic
+	case sc.EQUAL:
+		x2 := stack.Pop()
+		x1 := stack.Pop()
+		stack.Push(NewBoolean(x1.Equals(x2)))
+
+	// Crypto
+	case sc.CHECKSIG:
+		pubKey := popBytes(stack)
+		signature := popBytes(stack)
+		stack.Push(NewBoolean(e.ScriptContainer != nil && verifySignature(e.ScriptContainer.GetMessage(), signature, pubKey)))
+	case sc.VERIFY:
+		pubKey := popBytes(stack)
+		signature := popBytes(stack)
+		message := popBytes(stack)
+		stack.Push(NewBoolean(verifySignature(message, signature, pubKey)))
+	case sc.CHECKMULTISIG:
+		pubKeys := popByteArrays(stack)
+		signatures := popByteArrays(stack)
+		if len(signatures) > len(pubKeys) {
+			panic(fmt.Errorf("CHECKMULTISIG has more signatures than public keys"))
+		}
+		stack.Push(NewBoolean(e.ScriptContainer != nil && verifyMultiSignature(e.ScriptContainer.GetMessage(), signatures, pubKeys)))
+
+	// Exceptions
+	case sc.THROW:
+		panic(fmt.Errorf("THROW at offset %d", ins.Offset))
+	case sc.THROWIFNOT:
+		if !stack.Pop().GetBoolean() {
+			panic(fmt.Errorf("THROWIFNOT at offset %d", ins.Offset))
+		}
+
+	default:
+		if !executeArithmetic(stack, op) && !executeCrypto(stack, op) && !executeArray(stack, op) {
+			panic(fmt.Errorf("invalid op code %s at offset %d", op, ins.Offset))
+		}
+	}
+}
+
+func (e *ExecutionEngine) ret() {
+	callee := e.CurrentContext()
+	e.InvocationStack = e.InvocationStack[:len(e.InvocationStack)-1]
+	rvcount := callee.RVCount
+	if rvcount == -1 {
+		rvcount = callee.EvaluationStack.Count()
+	}
+	if rvcount > 0 {
+		if callee.EvaluationStack.Count() < rvcount {
+			panic(fmt.Errorf("RET expects %d return values", rvcount))
+		}
+		target := e.ResultStack
+		if caller := e.CurrentContext(); caller != nil {
+			target = caller.EvaluationStack
+		}
+		callee.EvaluationStack.CopyTo(target, rvcount)
+	}
+	if callee.RVCount == -1 && len(e.InvocationStack) > 0 {
+		callee.AltStack.CopyTo(e.CurrentContext().AltStack, -1)
+	}
+	if len(e.InvocationStack) == 0 {
+		e.State |= HALT
+	}
+}
+
+func (e *ExecutionEngine) removeContext(context *ExecutionContext) {
+	for i, c := range e.InvocationStack {
+		if c == context {
+			e.InvocationStack = append(e.InvocationStack[:i], e.InvocationStack[i+1:]...)
+			return
+		}
+	}
+}
+
+func (e *ExecutionEngine) getScript(hash []byte) []byte {
+	if len(hash) != 20 {
+		panic(fmt.Errorf("invalid script hash %x", hash))
+	}
+	var script []byte
+	if e.Table != nil {
+		script = e.Table.GetScript(hash)
+	}
+	if script == nil {
+		panic(fmt.Errorf("contract %s not found", helper.BytesToHex(helper.ReverseBytes(hash))))
+	}
+	return script
+}
+
+func checkJumpTarget(context *ExecutionContext, target int) {
+	if target < 0 || target > len(context.Script) {
+		panic(fmt.Errorf("jump target %d out of script range", target))
+	}
+}
+
+func isZero(b []byte) bool {
+	return bytes.Equal(b, make([]byte, len(b)))
+}
+
+func popBytes(stack *RandomAccessStack) []byte {
+	b, err := stack.Pop().GetByteArray()
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+// popIndex pops a non negative int
+func popIndex(stack *RandomAccessStack) int {
+	n, err := stack.Pop().GetBigInteger()
+	if err != nil {
+		panic(err)
+	}
+	if n.Sign() < 0 || !n.IsInt64() || n.Int64() > MaxItemSize {
+		panic(fmt.Errorf("invalid index %s", n))
+	}
+	return int(n.Int64())
+}
+
+// popByteArrays pops an array of byte arrays, or a count followed by the items
+func popByteArrays(stack *RandomAccessStack) [][]byte {
+	var result [][]byte
+	item := stack.Pop()
+	if a, ok := item.(arrayItem); ok {
+		for _, i := range a.Items() {
+			b, err := i.GetByteArray()
+			if err != nil {
+				panic(err)
+			}
+			result = append(result, b)
+		}
+	} else {
+		n, err := item.GetBigInteger()
+		if err != nil {
+			panic(err)
+		}
+		if n.Sign() < 1 || n.Cmp(bigInt(stack.Count())) > 0 {
+			panic(fmt.Errorf("invalid item count %s", n))
+		}
+		for i := int64(0); i < n.Int64(); i++ {
+			result = append(result, popBytes(stack))
+		}
+	}
+	if len(result) == 0 {
+		panic(fmt.Errorf("empty item array"))
+	}
+	return result
+}
@@ -0,0 +1,164 @@
+package vm
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/joeqian10/neo-gogogo/helper"
+	"github.com/joeqian10/neo-gogogo/sc"
+	"github.com/joeqian10/neo-gogogo/wallet/keys"
+)
+
+type messageContainer []byte
+
+func (m messageContainer) GetMessage() []byte {
+	return m
+}
+
+type platformService struct{}
+
+func (platformService) Invoke(name string, engine *ExecutionEngine) error {
+	if name != "System.Runtime.Platform" {
+		return fmt.Errorf("%s not supported", name)
+	}
+	engine.CurrentContext().EvaluationStack.Push(NewByteArray([]byte("NEO")))
+	return nil
+}
+
+func (platformService) GetPrice(name string, engine *ExecutionEngine) int64 {
+	return 1
+}
+
+func run(script []byte, table ScriptTable) *ExecutionEngine {
+	e := NewExecutionEngine(nil, table, platformService{})
+	e.LoadScript(script)
+	e.Execute()
+	return e
+}
+
+func TestExecutionEngine_Arithmetic(t *testing.T) {
+	sb := sc.NewScriptBuilder()
+	sb.EmitPushInt(100)
+	sb.EmitPushInt(-7)
+	sb.Emit(sc.DIV)
+	sb.EmitPushInt(3)
+	sb.Emit(sc.MUL)
+	e := run(sb.ToArray(), nil)
+	assert.Equal(t, HALT, e.State)
+	r := e.InvokeResult()
+	assert.Equal(t, "HALT", r.State)
+	assert.Equal(t, "0.003", r.GasConsumed)
+	assert.Equal(t, 1, len(r.Stack))
+	i, err := r.Stack[0].AsBigInt()
+	assert.Nil(t, err)
+	assert.Equal(t, int64(-42), i.Int64())
+}
+
+func TestExecutionEngine_CircularResult(t *testing.T) {
+	e := run([]byte{byte(sc.PUSH0), byte(sc.NEWARRAY), byte(sc.DUP), byte(sc.DUP), byte(sc.APPEND), byte(sc.RET)}, nil)
+	assert.Equal(t, HALT, e.State)
+	r := e.InvokeResult()
+	assert.Equal(t, 1, len(r.Stack))
+	items, err := r.Stack[0].AsArray()
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(items))
+	assert.Equal(t, "Array", items[0].Type)
+	assert.Equal(t, CircularReference, items[0].Value)
+}
+
+func TestExecutionEngine_Map(t *testing.T) {
+	sb := sc.NewScriptBuilder()
+	sb.Emit(sc.NEWMAP)
+	sb.Emit(sc.DUP)
+	sb.EmitPushString("a")
+	sb.EmitPushInt(5)
+	sb.Emit(sc.SETITEM)
+	sb.Emit(sc.DUP)
+	sb.EmitPushString("a")
+	sb.Emit(sc.PICKITEM)
+	e := run(sb.ToArray(), nil)
+	assert.Equal(t, HALT, e.State)
+	r := e.InvokeResult()
+	assert.Equal(t, 2, len(r.Stack))
+	entries, err := r.Stack[0].AsMap()
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(entries))
+	key, _ := entries[0].Key.AsString()
+	assert.Equal(t, "a", key)
+	assert.Equal(t, "5", r.Stack[1].Value)
+}
+
+func TestExecutionEngine_Call(t *testing.T) {
+	// PUSH2 CALL +4 RET INC RET
+	script := []byte{byte(sc.PUSH2), byte(sc.CALL), 4, 0, byte(sc.RET), byte(sc.INC), byte(sc.RET)}
+	e := run(script, nil)
+	assert.Equal(t, HALT, e.State)
+	assert.Equal(t, "3", e.InvokeResult().Stack[0].Value)
+}
+
+func TestExecutionEngine_AppCall(t *testing.T) {
+	table := MemoryScriptTable{}
+	hash := table.Add([]byte{byte(sc.ADD)})
+
+	sb := sc.NewScriptBuilder()
+	sb.EmitPushInt(2)
+	sb.EmitPushInt(3)
+	sb.EmitAppCall(hash.Bytes(), false)
+	sb.EmitVmSysCall("System.Runtime.Platform", true)
+	e := run(sb.ToArray(), table)
+	assert.Equal(t, HALT, e.State)
+	r := e.InvokeResult()
+	assert.Equal(t, "5", r.Stack[0].Value)
+	s, _ := r.Stack[1].AsString()
+	assert.Equal(t, "NEO", s)
+
+	e = run(sb.ToArray(), MemoryScriptTable{})
+	assert.Equal(t, FAULT, e.State)
+	assert.NotNil(t, e.FaultError)
+}
+
+func TestExecutionEngine_Fault(t *testing.T) {
+	for _, script := range [][]byte{
+		{byte(sc.PUSH1), byte(sc.PUSH0), byte(sc.DIV)},
+		{byte(sc.THROW)},
+		{byte(sc.PUSH0), byte(sc.THROWIFNOT)},
+		{byte(sc.DROP)},
+		{byte(sc.PUSH1), byte(sc.PUSH5), byte(sc.PICKITEM)},
+		{0xff},
+	} {
+		e := run(script, nil)
+		assert.Equal(t, FAULT, e.State, helper.BytesToHex(script))
+		assert.Equal(t, "FAULT", e.InvokeResult().State)
+	}
+
+	e := NewExecutionEngine(nil, nil, nil)
+	e.GasLimit = helper.NewFixed8(GasRatio)
+	e.LoadScript([]byte{byte(sc.PUSH1), byte(sc.INC), byte(sc.INC)})
+	assert.Equal(t, FAULT, e.Execute())
+}
+
+func TestExecutionEngine_CheckSig(t *testing.T) {
+	message := []byte("hello")
+	key, _ := keys.NewKeyPairFromWIF("L1caMUAsHr2dKwhqbMpYRcCzmzvZTfYZSCBefgARhz9iimAFRn1z")
+	signature, err := key.Sign(message)
+	assert.Nil(t, err)
+
+	sb := sc.NewScriptBuilder()
+	sb.EmitPushBytes(signature)
+	sb.EmitPushBytes(key.PublicKey.EncodeCompression())
+	sb.Emit(sc.CHECKSIG)
+	script := sb.ToArray()
+
+	e := NewExecutionEngine(messageContainer(message), nil, nil)
+	e.LoadScript(script)
+	assert.Equal(t, HALT, e.Execute())
+	assert.Equal(t, true, e.InvokeResult().Stack[0].Value)
+	assert.Equal(t, "0.101", e.InvokeResult().GasConsumed)
+
+	e = NewExecutionEngine(messageContainer("other"), nil, nil)
+	e.LoadScript(script)
+	assert.Equal(t, HALT, e.Execute())
+	assert.Equal(t, false, e.InvokeResult().Stack[0].Value)
+}
@@ -0,0 +1,538 @@
+package vm
+
+import (
+	"fmt"
+
+	"github.com/joeqian10/neo-gogogo/blockchain"
+	"github.com/joeqian10/neo-gogogo/crypto"
+	"github.com/joeqian10/neo-gogogo/helper"
+	"github.com/joeqian10/neo-gogogo/rpc/models"
+	"github.com/joeqian10/neo-gogogo/sc"
+)
+
+type TriggerType byte
+
+const (
+	Verification  TriggerType = 0x00
+	VerificationR TriggerType = 0x01
+	Application   TriggerType = 0x10
+	ApplicationR  TriggerType = 0x11
+)
+
+const MaxStorageKeySize = 1024
+
+// InteropHandler executes an interop service, it reads the arguments from and pushes the results onto
+// the evaluation stack of the current context
+type InteropHandler func(engine *ExecutionEngine) error
+
+// InteropPriceFunc computes the price of an interop service from the arguments on the evaluation stack
+type InteropPriceFunc func(engine *ExecutionEngine) int64
+
+// InteropPrices is the price table of the interop services in 0.001 GAS, the services not listed cost 1
+var InteropPrices = map[string]int64{
+	"System.Runtime.CheckWitness":            200,
+	"Neo.Runtime.CheckWitness":               200,
+	"System.Blockchain.GetHeader":            100,
+	"Neo.Blockchain.GetHeader":               100,
+	"System.Blockchain.GetBlock":             200,
+	"Neo.Blockchain.GetBlock":                200,
+	"System.Blockchain.GetTransaction":       100,
+	"Neo.Blockchain.GetTransaction":          100,
+	"System.Blockchain.GetTransactionHeight": 100,
+	"Neo.Blockchain.GetAccount":              100,
+	"Neo.Blockchain.GetValidators":           200,
+	"Neo.Blockchain.GetAsset":                100,
+	"System.Blockchain.GetContract":          100,
+	"Neo.Blockchain.GetContract":             100,
+	"Neo.Transaction.GetReferences":          200,
+	"Neo.Transaction.GetUnspentCoins":        200,
+	"Neo.Transaction.GetWitnesses":           200,
+	"Neo.Witness.GetVerificationScript":      100,
+	"Neo.Account.IsStandard":                 100,
+	"Neo.Asset.Create":                       5000 * 1000,
+	"Neo.Contract.Create":                    100 * 1000,
+	"Neo.Contract.Migrate":                   100 * 1000,
+	"System.Storage.Get":                     100,
+	"Neo.Storage.Get":                        100,
+	"System.Storage.Delete":                  100,
+	"Neo.Storage.Delete":                     100,
+}
+
+type interopEntry struct {
+	handler InteropHandler
+	price   InteropPriceFunc
+}
+
+// InteropRegistry is an InteropService keyed by the names EmitVmSysCall emits, both the full names
+// and the compressed hashes are resolved. The blockchain services which need chain data are not
+// registered by default, register them with Register.
+type InteropRegistry struct {
+	Trigger TriggerType
+	Store   Store
+	Time    uint32 // returned by Runtime.GetTime
+	Height  uint32 // returned by Blockchain.GetHeight
+
+	// CheckWitness reports whether the script hash signed the script container, nil returns false
+	CheckWitness func(scriptHash helper.UInt160) bool
+	// OnNotify is called by Runtime.Notify
+	OnNotify func(scriptHash helper.UInt160, state StackItem)
+	// OnLog is called by Runtime.Log
+	OnLog func(scriptHash helper.UInt160, message string)
+
+	// Notifications collects the notifications, which can be decoded by nep5.EventRegistry
+	Notifications []models.RpcNotification
+
+	services map[string]interopEntry
+}
+
+// NewInteropRegistry creates the registry with the runtime, execution engine, blockchain and storage services
+func NewInteropRegistry(store Store) *InteropRegistry {
+	r := &InteropRegistry{
+		Trigger:  Application,
+		Store:    store,
+		services: map[string]interopEntry{},
+	}
+	r.registerDefaults()
+	return r
+}
+
+// Register adds or replaces an interop service with a fixed price in 0.001 GAS
+func (r *InteropRegistry) Register(name string, handler InteropHandler, price int64) {
+	r.RegisterWithPriceFunc(name, handler, func(*ExecutionEngine) int64 { return price })
+}
+
+// RegisterWithPriceFunc adds or replaces an interop service whose price depends on its arguments
+func (r *InteropRegistry) RegisterWithPriceFunc(name string, handler InteropHandler, price InteropPriceFunc) {
+	entry := interopEntry{handler: handler, price: price}
+	r.services[name] = entry
+	// the engine passes unknown compressed hashes in hex
+	r.services[helper.BytesToHex(crypto.Sha256([]byte(name))[:4])] = entry
+}
+
+func (r *InteropRegistry) Invoke(name string, engine *ExecutionEngine) error {
+	entry, ok := r.services[name]
+	if !ok {
+		return fmt.Errorf("interop service %s not found", name)
+	}
+	return entry.handler(engine)
+}
+
+func (r *InteropRegistry) GetPrice(name string, engine *ExecutionEngine) int64 {
+	entry, ok := r.services[name]
+	if !ok {
+		return 1
+	}
+	return entry.price(engine)
+}
+
+func (r *InteropRegistry) register(handler InteropHandler, names ...string) {
+	for _, name := range names {
+		price, ok := InteropPrices[name]
+		if !ok {
+			price = 1
+		}
+		r.Register(name, handler, price)
+	}
+}
+
+func (r *InteropRegistry) registerDefaults() {
+	r.register(r.getScriptContainer, "System.ExecutionEngine.GetScriptContainer")
+	r.register(r.getExecutingScriptHash, "System.ExecutionEngine.GetExecutingScriptHash")
+	r.register(r.getCallingScriptHash, "System.ExecutionEngine.GetCallingScriptHash")
+	r.register(r.getEntryScriptHash, "System.ExecutionEngine.GetEntryScriptHash")
+
+	r.register(r.platform, "System.Runtime.Platform")
+	r.register(r.getTrigger, "System.Runtime.GetTrigger", "Neo.Runtime.GetTrigger")
+	r.register(r.checkWitness, "System.Runtime.CheckWitness", "Neo.Runtime.CheckWitness")
+	r.register(r.notify, "System.Runtime.Notify", "Neo.Runtime.Notify")
+	r.register(r.log, "System.Runtime.Log", "Neo.Runtime.Log")
+	r.register(r.getTime, "System.Runtime.GetTime", "Neo.Runtime.GetTime")
+	r.register(r.serialize, "System.Runtime.Serialize", "Neo.Runtime.Serialize")
+	r.register(r.deserialize, "System.Runtime.Deserialize", "Neo.Runtime.Deserialize")
+
+	r.register(r.getHeight, "System.Blockchain.GetHeight", "Neo.Blockchain.GetHeight")
+	r.register(r.getContract, "System.Blockchain.GetContract", "Neo.Blockchain.GetContract")
+	r.register(r.getContractScript, "Neo.Contract.GetScript")
+
+	r.register(r.getStorageContext, "System.Storage.GetContext", "Neo.Storage.GetContext")
+	r.register(r.getReadOnlyStorageContext, "System.Storage.GetReadOnlyContext", "Neo.Storage.GetReadOnlyContext")
+	r.register(r.storageContextAsReadOnly, "System.StorageContext.AsReadOnly", "Neo.StorageContext.AsReadOnly")
+	r.register(r.storageGet, "System.Storage.Get", "Neo.Storage.Get")
+	r.register(r.storageDelete, "System.Storage.Delete", "Neo.Storage.Delete")
+	r.register(r.storageFind, "Neo.Storage.Find")
+	r.RegisterWithPriceFunc("System.Storage.Put", r.storagePut, storagePutPrice)
+	r.RegisterWithPriceFunc("Neo.Storage.Put", r.storagePut, storagePutPrice)
+	r.RegisterWithPriceFunc("System.Storage.PutEx", r.storagePutEx, storagePutPrice)
+	r.register(r.iteratorNext, "Neo.Iterator.Next", "Neo.Enumerator.Next")
+	r.register(r.iteratorKey, "Neo.Iterator.Key")
+	r.register(r.iteratorValue, "Neo.Iterator.Value", "Neo.Enumerator.Value")
+}
+
+func currentStack(engine *ExecutionEngine) *RandomAccessStack {
+	return engine.CurrentContext().EvaluationStack
+}
+
+func currentScriptHash(engine *ExecutionEngine) helper.UInt160 {
+	u, _ := helper.UInt160FromBytes(engine.CurrentContext().ScriptHash())
+	return u
+}
+
+func popInterop(engine *ExecutionEngine) (interface{}, error) {
+	item, ok := currentStack(engine).Pop().(*InteropInterface)
+	if !ok {
+		return nil, fmt.Errorf("expected an interop interface")
+	}
+	return item.Value, nil
+}
+
+// ExecutionEngine
+
+func (r *InteropRegistry) getScriptContainer(engine *ExecutionEngine) error {
+	currentStack(engine).Push(NewInteropInterface(engine.ScriptContainer))
+	return nil
+}
+
+func (r *InteropRegistry) getExecutingScriptHash(engine *ExecutionEngine) error {
+	currentStack(engine).Push(NewByteArray(engine.CurrentContext().ScriptHash()))
+	return nil
+}
+
+func (r *InteropRegistry) getCallingScriptHash(engine *ExecutionEngine) error {
+	hash := []byte{}
+	if c := engine.CallingContext(); c != nil {
+		hash = c.ScriptHash()
+	}
+	currentStack(engine).Push(NewByteArray(hash))
+	return nil
+}
+
+func (r *InteropRegistry) getEntryScriptHash(engine *ExecutionEngine) error {
+	currentStack(engine).Push(NewByteArray(engine.EntryContext().ScriptHash()))
+	return nil
+}
+
+// Runtime
+
+func (r *InteropRegistry) platform(engine *ExecutionEngine) error {
+	currentStack(engine).Push(NewByteArray([]byte("NEO")))
+	return nil
+}
+
+func (r *InteropRegistry) getTrigger(engine *ExecutionEngine) error {
+	currentStack(engine).Push(NewIntegerFromInt64(int64(r.Trigger)))
+	return nil
+}
+
+func (r *InteropRegistry) checkWitness(engine *ExecutionEngine) error {
+	b := popBytes(currentStack(engine))
+	var hash helper.UInt160
+	switch len(b) {
+	case 20:
+		hash, _ = helper.UInt160FromBytes(b)
+	case 33:
+		// the signature contract of the public key
+		script := append(append([]byte{byte(sc.PUSHBYTES1) + 32}, b...), byte(sc.CHECKSIG))
+		hash, _ = helper.UInt160FromBytes(crypto.Hash160(script))
+	default:
+		return fmt.Errorf("invalid witness %x", b)
+	}
+	currentStack(engine).Push(NewBoolean(r.CheckWitness != nil && r.CheckWitness(hash)))
+	return nil
+}
+
+func (r *InteropRegistry) notify(engine *ExecutionEngine) error {
+	state := currentStack(engine).Pop()
+	hash := currentScriptHash(engine)
+	r.Notifications = append(r.Notifications, models.RpcNotification{
+		Contract: "0x" + hash.String(),
+		State:    state.ToInvokeStack(),
+	})
+	if r.OnNotify != nil {
+		r.OnNotify(hash, state)
+	}
+	return nil
+}
+
+func (r *InteropRegistry) log(engine *ExecutionEngine) error {
+	message := popBytes(currentStack(engine))
+	if r.OnLog != nil {
+		r.OnLog(currentScriptHash(engine), string(message))
+	}
+	return nil
+}
+
+func (r *InteropRegistry) getTime(engine *ExecutionEngine) error {
+	currentStack(engine).Push(NewIntegerFromInt64(int64(r.Time)))
+	return nil
+}
+
+func (r *InteropRegistry) serialize(engine *ExecutionEngine) error {
+	b, err := SerializeStackItem(currentStack(engine).Pop())
+	if err != nil {
+		return err
+	}
+	if len(b) > MaxItemSize {
+		return fmt.Errorf("serialized item exceeds the max item size")
+	}
+	currentStack(engine).Push(NewByteArray(b))
+	return nil
+}
+
+func (r *InteropRegistry) deserialize(engine *ExecutionEngine) error {
+	item, err := DeserializeStackItem(popBytes(currentStack(engine)))
+	if err != nil {
+		return err
+	}
+	currentStack(engine).Push(item)
+	return nil
+}
+
+// Blockchain
+
+func (r *InteropRegistry) getHeight(engine *ExecutionEngine) error {
+	currentStack(engine).Push(NewIntegerFromInt64(int64(r.Height)))
+	return nil
+}
+
+// getContract pushes the script of the contract as an interop interface, or an empty byte array if not found
+func (r *InteropRegistry) getContract(engine *ExecutionEngine) error {
+	hash := popBytes(currentStack(engine))
+	var script []byte
+	if engine.Table != nil && len(hash) == 20 {
+		script = engine.Table.GetScript(hash)
+	}
+	if script == nil {
+		currentStack(engine).Push(NewByteArray([]byte{}))
+	} else {
+		currentStack(engine).Push(NewInteropInterface(&sc.Contract{Script: script}))
+	}
+	return nil
+}
+
+func (r *InteropRegistry) getContractScript(engine *ExecutionEngine) error {
+	v, err := popInterop(engine)
+	if err != nil {
+		return err
+	}
+	c, ok := v.(*sc.Contract)
+	if !ok {
+		return fmt.Errorf("expected a contract")
+	}
+	currentStack(engine).Push(NewByteArray(c.Script))
+	return nil
+}
+
+// Storage
+
+func (r *InteropRegistry) getStorageContext(engine *ExecutionEngine) error {
+	currentStack(engine).Push(NewInteropInterface(&StorageContext{ScriptHash: currentScriptHash(engine)}))
+	return nil
+}
+
+func (r *InteropRegistry) getReadOnlyStorageContext(engine *ExecutionEngine) error {
+	currentStack(engine).Push(NewInteropInterface(&StorageContext{ScriptHash: currentScriptHash(engine), IsReadOnly: true}))
+	return nil
+}
+
+func (r *InteropRegistry) storageContextAsReadOnly(engine *ExecutionEngine) error {
+	context, err := popStorageContext(engine)
+	if err != nil {
+		return err
+	}
+	currentStack(engine).Push(NewInteropInterface(&StorageContext{ScriptHash: context.ScriptHash, IsReadOnly: true}))
+	return nil
+}
+
+func popStorageContext(engine *ExecutionEngine) (*StorageContext, error) {
+	v, err := popInterop(engine)
+	if err != nil {
+		return nil, err
+	}
+	context, ok := v.(*StorageContext)
+	if !ok {
+		return nil, fmt.Errorf("expected a storage context")
+	}
+	return context, nil
+}
+
+func (r *InteropRegistry) checkStorage() error {
+	if r.Store == nil {
+		return fmt.Errorf("no storage")
+	}
+	return nil
+}
+
+func (r *InteropRegistry) checkWritable(context *StorageContext) error {
+	if r.Trigger != Application && r.Trigger != ApplicationR {
+		return fmt.Errorf("storage is read only in verification")
+	}
+	if context.IsReadOnly {
+		return fmt.Errorf("storage context is read only")
+	}
+	return r.checkStorage()
+}
+
+func (r *InteropRegistry) storageGet(engine *ExecutionEngine) error {
+	context, err := popStorageContext(engine)
+	if err != nil {
+		return err
+	}
+	key := popBytes(currentStack(engine))
+	if err = r.checkStorage(); err != nil {
+		return err
+	}
+	item, err := GetStorageItem(r.Store, blockchain.StorageKey{ScriptHash: context.ScriptHash, Key: key})
+	if err != nil {
+		return err
+	}
+	value := []byte{}
+	if item != nil {
+		value = item.Value
+	}
+	currentStack(engine).Push(NewByteArray(value))
+	return nil
+}
+
+func storagePutPrice(engine *ExecutionEngine) int64 {
+	stack := currentStack(engine)
+	if stack.Count() < 3 {
+		return 1
+	}
+	key, err1 := stack.Peek(1).GetByteArray()
+	value, err2 := stack.Peek(2).GetByteArray()
+	if err1 != nil || err2 != nil {
+		return 1
+	}
+	return int64((len(key)+len(value)-1)/1024+1) * 1000
+}
+
+func (r *InteropRegistry) storagePut(engine *ExecutionEngine) error {
+	return r.put(engine, false)
+}
+
+// storagePutEx takes the flags as the last argument, 0x01 makes the item constant
+func (r *InteropRegistry) storagePutEx(engine *ExecutionEngine) error {
+	return r.put(engine, true)
+}
+
+func (r *InteropRegistry) put(engine *ExecutionEngine, withFlags bool) error {
+	context, err := popStorageContext(engine)
+	if err != nil {
+		return err
+	}
+	stack := currentStack(engine)
+	key := popBytes(stack)
+	value := popBytes(stack)
+	isConstant := false
+	if withFlags {
+		flags, err := stack.Pop().GetBigInteger()
+		if err != nil {
+			return err
+		}
+		isConstant = flags.Bit(0) == 1
+	}
+	if err = r.checkWritable(context); err != nil {
+		return err
+	}
+	if len(key) > MaxStorageKeySize {
+		return fmt.Errorf("storage key exceeds %d bytes", MaxStorageKeySize)
+	}
+	storageKey := blockchain.StorageKey{ScriptHash: context.ScriptHash, Key: key}
+	item, err := GetStorageItem(r.Store, storageKey)
+	if err != nil {
+		return err
+	}
+	if item != nil && item.IsConstant {
+		return fmt.Errorf("storage item is constant")
+	}
+	return PutStorageItem(r.Store, storageKey, &blockchain.StorageItem{Value: value, IsConstant: isConstant})
+}
+
+func (r *InteropRegistry) storageDelete(engine *ExecutionEngine) error {
+	context, err := popStorageContext(engine)
+	if err != nil {
+		return err
+	}
+	key := popBytes(currentStack(engine))
+	if err = r.checkWritable(context); err != nil {
+		return err
+	}
+	storageKey := blockchain.StorageKey{ScriptHash: context.ScriptHash, Key: key}
+	item, err := GetStorageItem(r.Store, storageKey)
+	if err != nil {
+		return err
+	}
+	if item != nil && item.IsConstant {
+		return fmt.Errorf("storage item is constant")
+	}
+	return DeleteStorageItem(r.Store, storageKey)
+}
+
+func (r *InteropRegistry) storageFind(engine *ExecutionEngine) error {
+	context, err := popStorageContext(engine)
+	if err != nil {
+		return err
+	}
+	prefix := popBytes(currentStack(engine))
+	if err = r.checkStorage(); err != nil {
+		return err
+	}
+	keys, items, err := FindStorageItems(r.Store, context.ScriptHash, prefix)
+	if err != nil {
+		return err
+	}
+	it := &storageIterator{index: -1}
+	for i := range keys {
+		it.keys = append(it.keys, NewByteArray(keys[i].Key))
+		it.values = append(it.values, NewByteArray(items[i].Value))
+	}
+	currentStack(engine).Push(NewInteropInterface(it))
+	return nil
+}
+
+func popIterator(engine *ExecutionEngine) (*storageIterator, error) {
+	v, err := popInterop(engine)
+	if err != nil {
+		return nil, err
+	}
+	it, ok := v.(*storageIterator)
+	if !ok {
+		return nil, fmt.Errorf("expected an iterator")
+	}
+	return it, nil
+}
+
+func (r *InteropRegistry) iteratorNext(engine *ExecutionEngine) error {
+	it, err := popIterator(engine)
+	if err != nil {
+		return err
+	}
+	currentStack(engine).Push(NewBoolean(it.next()))
+	return nil
+}
+
+func (r *InteropRegistry) iteratorKey(engine *ExecutionEngine) error {
+	it, err := popIterator(engine)
+	if err != nil {
+		return err
+	}
+	key, _, ok := it.current()
+	if !ok {
+		return fmt.Errorf("iterator is not positioned")
+	}
+	currentStack(engine).Push(key)
+	return nil
+}
+
+func (r *InteropRegistry) iteratorValue(engine *ExecutionEngine) error {
+	it, err := popIterator(engine)
+	if err != nil {
+		return err
+	}
+	_, value, ok := it.current()
+	if !ok {
+		return fmt.Errorf("iterator is not positioned")
+	}
+	currentStack(engine).Push(value)
+	return nil
+}
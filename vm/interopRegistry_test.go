@@ -0,0 +1,145 @@
+package vm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/joeqian10/neo-gogogo/blockchain"
+	"github.com/joeqian10/neo-gogogo/crypto"
+	"github.com/joeqian10/neo-gogogo/helper"
+	"github.com/joeqian10/neo-gogogo/sc"
+)
+
+func TestInteropRegistry_Storage(t *testing.T) {
+	store := NewMemoryStore()
+	registry := NewInteropRegistry(store)
+	sb := sc.NewScriptBuilder()
+	sb.EmitPushString("value")
+	sb.EmitPushString("key")
+	sb.EmitVmSysCall("Neo.Storage.GetContext", true)
+	sb.EmitVmSysCall("Neo.Storage.Put", true)
+	sb.EmitPushString("key")
+	sb.EmitVmSysCall("System.Storage.GetContext", false)
+	sb.EmitVmSysCall("System.Storage.Get", true)
+	sb.EmitPushString("k")
+	sb.EmitVmSysCall("Neo.Storage.GetContext", true)
+	sb.EmitVmSysCall("Neo.Storage.Find", true)
+	sb.Emit(sc.DUP)
+	sb.EmitVmSysCall("Neo.Iterator.Next", true)
+	sb.Emit(sc.DROP)
+	sb.EmitVmSysCall("Neo.Iterator.Key", true)
+	script := sb.ToArray()
+
+	e := NewExecutionEngine(nil, nil, registry)
+	e.LoadScript(script)
+	assert.Equal(t, HALT, e.Execute())
+	r := e.InvokeResult()
+	assert.Equal(t, 2, len(r.Stack))
+	value, _ := r.Stack[0].AsString()
+	assert.Equal(t, "value", value)
+	key, _ := r.Stack[1].AsString()
+	assert.Equal(t, "key", key)
+	// Storage.Put costs 1 GAS per KB
+	assert.True(t, e.GasConsumed.GreaterThan(helper.Fixed8FromInt64(1)))
+
+	hash, _ := helper.UInt160FromBytes(crypto.Hash160(script))
+	item, err := GetStorageItem(store, blockchain.StorageKey{ScriptHash: hash, Key: []byte("key")})
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("value"), item.Value)
+
+	registry.Trigger = Verification
+	e = NewExecutionEngine(nil, nil, registry)
+	e.LoadScript(script)
+	assert.Equal(t, FAULT, e.Execute())
+}
+
+func TestInteropRegistry_Runtime(t *testing.T) {
+	registry := NewInteropRegistry(nil)
+	witness, _ := helper.UInt160FromString("b9d7ea3062e6aeeb3e8ad9548220c4ba1361d263")
+	registry.CheckWitness = func(hash helper.UInt160) bool {
+		return hash == witness
+	}
+	var logs []string
+	registry.OnLog = func(hash helper.UInt160, message string) {
+		logs = append(logs, message)
+	}
+
+	sb := sc.NewScriptBuilder()
+	sb.EmitPushBytes(witness.Bytes())
+	sb.EmitVmSysCall("Neo.Runtime.CheckWitness", true)
+	sb.EmitPushString("hello")
+	sb.EmitVmSysCall("Neo.Runtime.Log", true)
+	sb.EmitPushInt(100)
+	sb.EmitPushString("transfer")
+	sb.EmitPushInt(2)
+	sb.Emit(sc.PACK)
+	sb.EmitVmSysCall("Neo.Runtime.Notify", true)
+	sb.EmitVmSysCall("System.ExecutionEngine.GetExecutingScriptHash", true)
+	script := sb.ToArray()
+
+	e := NewExecutionEngine(nil, nil, registry)
+	e.LoadScript(script)
+	assert.Equal(t, HALT, e.Execute())
+	r := e.InvokeResult()
+	assert.Equal(t, true, r.Stack[0].Value)
+	assert.Equal(t, helper.BytesToHex(crypto.Hash160(script)), r.Stack[1].Value)
+	assert.Equal(t, []string{"hello"}, logs)
+	assert.Equal(t, 1, len(registry.Notifications))
+	items, err := registry.Notifications[0].State.AsArray()
+	assert.Nil(t, err)
+	name, _ := items[0].AsString()
+	assert.Equal(t, "transfer", name)
+
+	sb = sc.NewScriptBuilder()
+	sb.EmitVmSysCall("Neo.Blockchain.GetHeader", true)
+	e = NewExecutionEngine(nil, nil, registry)
+	e.LoadScript(sb.ToArray())
+	assert.Equal(t, FAULT, e.Execute())
+
+	registry.Register("Neo.Blockchain.GetHeader", func(engine *ExecutionEngine) error {
+		engine.CurrentContext().EvaluationStack.Push(NewByteArray([]byte{}))
+		return nil
+	}, 100)
+	e = NewExecutionEngine(nil, nil, registry)
+	e.LoadScript(sb.ToArray())
+	assert.Equal(t, HALT, e.Execute())
+	assert.Equal(t, "0.101", e.InvokeResult().GasConsumed)
+}
+
+func TestInteropRegistry_CompressedHash(t *testing.T) {
+	registry := NewInteropRegistry(nil)
+	// the compressed hash of the name is printable, 683b543a is "h;T:"
+	registry.Register("My.Service1985", func(engine *ExecutionEngine) error {
+		engine.CurrentContext().EvaluationStack.Push(NewIntegerFromInt64(1985))
+		return nil
+	}, 10)
+	for _, compressed := range []bool{false, true} {
+		sb := sc.NewScriptBuilder()
+		sb.EmitVmSysCall("My.Service1985", compressed)
+		e := NewExecutionEngine(nil, nil, registry)
+		e.LoadScript(sb.ToArray())
+		assert.Equal(t, HALT, e.Execute())
+		assert.Equal(t, "1985", e.InvokeResult().Stack[0].Value)
+		assert.Equal(t, "0.011", e.InvokeResult().GasConsumed)
+	}
+}
+
+func TestSerializeStackItem(t *testing.T) {
+	m := NewMap()
+	m.Set(NewByteArray([]byte("a")), NewIntegerFromInt64(-1))
+	item := NewArray([]StackItem{NewBoolean(true), NewStruct([]StackItem{NewByteArray([]byte{1, 2})}), m})
+	b, err := SerializeStackItem(item)
+	assert.Nil(t, err)
+	assert.Equal(t, "8003010181010002010282010001610201ff", helper.BytesToHex(b))
+
+	d, err := DeserializeStackItem(b)
+	assert.Nil(t, err)
+	assert.Equal(t, item.ToInvokeStack(), d.ToInvokeStack())
+
+	item.items = append(item.items, item)
+	_, err = SerializeStackItem(item)
+	assert.NotNil(t, err)
+	_, err = DeserializeStackItem([]byte{0x80, 0x01})
+	assert.NotNil(t, err)
+}
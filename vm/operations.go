@@ -0,0 +1,407 @@
+package vm
+
+import (
+	"crypto/sha1"
+	"fmt"
+	"math/big"
+
+	"github.com/joeqian10/neo-gogogo/crypto"
+	"github.com/joeqian10/neo-gogogo/helper"
+	"github.com/joeqian10/neo-gogogo/sc"
+	"github.com/joeqian10/neo-gogogo/wallet/keys"
+)
+
+func bigInt(i int) *big.Int {
+	return big.NewInt(int64(i))
+}
+
+func popInt(stack *RandomAccessStack) *big.Int {
+	n, err := stack.Pop().GetBigInteger()
+	if err != nil {
+		panic(err)
+	}
+	return n
+}
+
+func pushInt(stack *RandomAccessStack, n *big.Int) {
+	if len(helper.BigIntToNeoBytes(n)) > MaxSizeForBigInteger {
+		panic(fmt.Errorf("integer exceeds %d bytes", MaxSizeForBigInteger))
+	}
+	stack.Push(NewInteger(n))
+}
+
+// executeArithmetic executes the bitwise logic and arithmetic op codes, it returns false for other op codes
+func executeArithmetic(stack *RandomAccessStack, op sc.OpCode) bool {
+	switch op {
+	case sc.INVERT:
+		pushInt(stack, new(big.Int).Not(popInt(stack)))
+	case sc.INC:
+		pushInt(stack, new(big.Int).Add(popInt(stack), big.NewInt(1)))
+	case sc.DEC:
+		pushInt(stack, new(big.Int).Sub(popInt(stack), big.NewInt(1)))
+	case sc.SIGN:
+		stack.Push(NewIntegerFromInt64(int64(popInt(stack).Sign())))
+	case sc.NEGATE:
+		pushInt(stack, new(big.Int).Neg(popInt(stack)))
+	case sc.ABS:
+		pushInt(stack, new(big.Int).Abs(popInt(stack)))
+	case sc.NOT:
+		stack.Push(NewBoolean(!stack.Pop().GetBoolean()))
+	case sc.NZ:
+		stack.Push(NewBoolean(popInt(stack).Sign() != 0))
+	case sc.SHL, sc.SHR:
+		shift := popInt(stack)
+		if shift.CmpAbs(bigInt(MaxShift)) > 0 {
+			panic(fmt.Errorf("shift %s out of range", shift))
+		}
+		s := int(shift.Int64())
+		if s == 0 {
+			return true
+		}
+		if op == sc.SHR {
+			s = -s
+		}
+		x := popInt(stack)
+		if s > 0 {
+			pushInt(stack, new(big.Int).Lsh(x, uint(s)))
+		} else {
+			pushInt(stack, new(big.Int).Rsh(x, uint(-s)))
+		}
+	case sc.BOOLAND, sc.BOOLOR:
+		x2 := stack.Pop().GetBoolean()
+		x1 := stack.Pop().GetBoolean()
+		if op == sc.BOOLAND {
+			stack.Push(NewBoolean(x1 && x2))
+		} else {
+			stack.Push(NewBoolean(x1 || x2))
+		}
+	case sc.AND, sc.OR, sc.XOR, sc.ADD, sc.SUB, sc.MUL, sc.DIV, sc.MOD, sc.MIN, sc.MAX:
+		x2 := popInt(stack)
+		x1 := popInt(stack)
+		r := new(big.Int)
+		switch op {
+		case sc.AND:
+			r.And(x1, x2)
+		case sc.OR:
+			r.Or(x1, x2)
+		case sc.XOR:
+			r.Xor(x1, x2)
+		case sc.ADD:
+			r.Add(x1, x2)
+		case sc.SUB:
+			r.Sub(x1, x2)
+		case sc.MUL:
+			r.Mul(x1, x2)
+		case sc.DIV, sc.MOD:
+			if x2.Sign() == 0 {
+				panic(fmt.Errorf("division by zero"))
+			}
+			if op == sc.DIV {
+				r.Quo(x1, x2)
+			} else {
+				r.Rem(x1, x2)
+			}
+		case sc.MIN:
+			r.Set(x1)
+			if x2.Cmp(x1) < 0 {
+				r.Set(x2)
+			}
+		case sc.MAX:
+			r.Set(x1)
+			if x2.Cmp(x1) > 0 {
+				r.Set(x2)
+			}
+		}
+		pushInt(stack, r)
+	case sc.NUMEQUAL, sc.NUMNOTEQUAL, sc.LT, sc.GT, sc.LTE, sc.GTE:
+		x2 := popInt(stack)
+		x1 := popInt(stack)
+		c := x1.Cmp(x2)
+		var r bool
+		switch op {
+		case sc.NUMEQUAL:
+			r = c == 0
+		case sc.NUMNOTEQUAL:
+			r = c != 0
+		case sc.LT:
+			r = c < 0
+		case sc.GT:
+			r = c > 0
+		case sc.LTE:
+			r = c <= 0
+		case sc.GTE:
+			r = c >= 0
+		}
+		stack.Push(NewBoolean(r))
+	case sc.WITHIN:
+		b := popInt(stack)
+		a := popInt(stack)
+		x := popInt(stack)
+		stack.Push(NewBoolean(a.Cmp(x) <= 0 && x.Cmp(b) < 0))
+	default:
+		return false
+	}
+	return true
+}
+
+// executeCrypto executes the hash op codes, it returns false for other op codes
+func executeCrypto(stack *RandomAccessStack, op sc.OpCode) bool {
+	switch op {
+	case sc.SHA1:
+		h := sha1.Sum(popBytes(stack))
+		stack.Push(NewByteArray(h[:]))
+	case sc.SHA256:
+		stack.Push(NewByteArray(crypto.Sha256(popBytes(stack))))
+	case sc.HASH160:
+		stack.Push(NewByteArray(crypto.Hash160(popBytes(stack))))
+	case sc.HASH256:
+		stack.Push(NewByteArray(crypto.Hash256(popBytes(stack))))
+	default:
+		return false
+	}
+	return true
+}
+
+func verifySignature(message []byte, signature []byte, pubKey []byte) bool {
+	if len(signature) != 64 {
+		return false
+	}
+	p, err := keys.NewPublicKey(pubKey)
+	if err != nil {
+		return false
+	}
+	return keys.VerifySignature(message, signature, p)
+}
+
+// verifyMultiSignature checks the signatures are made by the public keys in the same order
+func verifyMultiSignature(message []byte, signatures [][]byte, pubKeys [][]byte) bool {
+	m, n := len(signatures), len(pubKeys)
+	for i, j := 0, 0; i < m; {
+		if m-i > n-j {
+			return false
+		}
+		if verifySignature(message, signatures[i], pubKeys[j]) {
+			i++
+		}
+		j++
+	}
+	return true
+}
+
+// arrayItem is implemented by Array and Struct
+type arrayItem interface {
+	StackItem
+	Items() []StackItem
+}
+
+// keyItem pops a map key or an array index, which should be primitive
+func popKey(stack *RandomAccessStack) StackItem {
+	key := stack.Pop()
+	switch key.(type) {
+	case *Array, *Struct, *Map:
+		panic(fmt.Errorf("%T cannot be a key", key))
+	}
+	return key
+}
+
+func arrayIndex(key StackItem, length int) int {
+	i, err := key.GetBigInteger()
+	if err != nil {
+		panic(err)
+	}
+	if i.Sign() < 0 || i.Cmp(bigInt(length)) >= 0 {
+		panic(fmt.Errorf("index %s out of range", i))
+	}
+	return int(i.Int64())
+}
+
+// valueOf clones structs, which have value semantics
+func valueOf(item StackItem) StackItem {
+	if s, ok := item.(*Struct); ok {
+		return s.Clone()
+	}
+	return item
+}
+
+func setItems(a arrayItem, items []StackItem) {
+	switch v := a.(type) {
+	case *Array:
+		v.items = items
+	case *Struct:
+		v.items = items
+	}
+}
+
+// executeArray executes the array and map op codes, it returns false for other op codes
+func executeArray(stack *RandomAccessStack, op sc.OpCode) bool {
+	switch op {
+	case sc.ARRAYSIZE:
+		item := stack.Pop()
+		switch v := item.(type) {
+		case arrayItem:
+			stack.Push(NewIntegerFromInt64(int64(len(v.Items()))))
+		case *Map:
+			stack.Push(NewIntegerFromInt64(int64(v.Count())))
+		default:
+			b, err := item.GetByteArray()
+			if err != nil {
+				panic(err)
+			}
+			stack.Push(NewIntegerFromInt64(int64(len(b))))
+		}
+	case sc.PACK:
+		n := popIndex(stack)
+		if n > stack.Count() || n > MaxArraySize {
+			panic(fmt.Errorf("PACK count %d out of range", n))
+		}
+		items := make([]StackItem, n)
+		for i := range items {
+			items[i] = stack.Pop()
+		}
+		stack.Push(NewArray(items))
+	case sc.UNPACK:
+		a, ok := stack.Pop().(arrayItem)
+		if !ok {
+			panic(fmt.Errorf("UNPACK expects an array"))
+		}
+		items := a.Items()
+		for i := len(items) - 1; i >= 0; i-- {
+			stack.Push(items[i])
+		}
+		stack.Push(NewIntegerFromInt64(int64(len(items))))
+	case sc.PICKITEM:
+		key := popKey(stack)
+		switch v := stack.Pop().(type) {
+		case arrayItem:
+			stack.Push(v.Items()[arrayIndex(key, len(v.Items()))])
+		case *Map:
+			value, ok := v.Get(key)
+			if !ok {
+				panic(fmt.Errorf("key not found in map"))
+			}
+			stack.Push(value)
+		default:
+			panic(fmt.Errorf("PICKITEM expects an array or a map"))
+		}
+	case sc.SETITEM:
+		value := valueOf(stack.Pop())
+		key := popKey(stack)
+		switch v := stack.Pop().(type) {
+		case arrayItem:
+			v.Items()[arrayIndex(key, len(v.Items()))] = value
+		case *Map:
+			if _, ok := v.Get(key); !ok && v.Count() >= MaxArraySize {
+				panic(fmt.Errorf("map size exceeds %d", MaxArraySize))
+			}
+			v.Set(key, value)
+		default:
+			panic(fmt.Errorf("SETITEM expects an array or a map"))
+		}
+	case sc.NEWARRAY, sc.NEWSTRUCT:
+		item := stack.Pop()
+		var items []StackItem
+		if a, ok := item.(arrayItem); ok {
+			items = append([]StackItem{}, a.Items()...)
+		} else {
+			n, err := item.GetBigInteger()
+			if err != nil {
+				panic(err)
+			}
+			if n.Sign() < 0 || n.Cmp(bigInt(MaxArraySize)) > 0 {
+				panic(fmt.Errorf("array size %s out of range", n))
+			}
+			items = make([]StackItem, n.Int64())
+			for i := range items {
+				items[i] = NewBoolean(false)
+			}
+		}
+		if op == sc.NEWARRAY {
+			if a, ok := item.(*Array); ok {
+				stack.Push(a)
+			} else {
+				stack.Push(NewArray(items))
+			}
+		} else {
+			if s, ok := item.(*Struct); ok {
+				stack.Push(s)
+			} else {
+				stack.Push(NewStruct(items))
+			}
+		}
+	case sc.NEWMAP:
+		stack.Push(NewMap())
+	case sc.APPEND:
+		item := valueOf(stack.Pop())
+		a, ok := stack.Pop().(arrayItem)
+		if !ok {
+			panic(fmt.Errorf("APPEND expects an array"))
+		}
+		if len(a.Items()) >= MaxArraySize {
+			panic(fmt.Errorf("array size exceeds %d", MaxArraySize))
+		}
+		setItems(a, append(a.Items(), item))
+	case sc.REVERSE:
+		a, ok := stack.Pop().(arrayItem)
+		if !ok {
+			panic(fmt.Errorf("REVERSE expects an array"))
+		}
+		items := a.Items()
+		for i, j := 0, len(items)-1; i < j; i, j = i+1, j-1 {
+			items[i], items[j] = items[j], items[i]
+		}
+	case sc.REMOVE:
+		key := popKey(stack)
+		switch v := stack.Pop().(type) {
+		case arrayItem:
+			items := v.Items()
+			i := arrayIndex(key, len(items))
+			setItems(v, append(items[:i:i], items[i+1:]...))
+		case *Map:
+			v.Remove(key)
+		default:
+			panic(fmt.Errorf("REMOVE expects an array or a map"))
+		}
+	case sc.HASKEY:
+		key := popKey(stack)
+		switch v := stack.Pop().(type) {
+		case arrayItem:
+			i, err := key.GetBigInteger()
+			if err != nil {
+				panic(err)
+			}
+			if i.Sign() < 0 {
+				panic(fmt.Errorf("negative index %s", i))
+			}
+			stack.Push(NewBoolean(i.Cmp(bigInt(len(v.Items()))) < 0))
+		case *Map:
+			_, ok := v.Get(key)
+			stack.Push(NewBoolean(ok))
+		default:
+			panic(fmt.Errorf("HASKEY expects an array or a map"))
+		}
+	case sc.KEYS:
+		m, ok := stack.Pop().(*Map)
+		if !ok {
+			panic(fmt.Errorf("KEYS expects a map"))
+		}
+		stack.Push(NewArray(append([]StackItem{}, m.Keys()...)))
+	case sc.VALUES:
+		var values []StackItem
+		switch v := stack.Pop().(type) {
+		case arrayItem:
+			values = v.Items()
+		case *Map:
+			values = v.Values()
+		default:
+			panic(fmt.Errorf("VALUES expects an array or a map"))
+		}
+		items := make([]StackItem, len(values))
+		for i, value := range values {
+			items[i] = valueOf(value)
+		}
+		stack.Push(NewArray(items))
+	default:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,86 @@
+package vm
+
+// RandomAccessStack is the evaluation stack of neo vm, index 0 is the top of the stack.
+// The methods panic if the index is out of range, the engine turns the panic into FAULT.
+type RandomAccessStack struct {
+	items []StackItem
+}
+
+func NewRandomAccessStack() *RandomAccessStack {
+	return &RandomAccessStack{}
+}
+
+// Count returns the number of items
+func (s *RandomAccessStack) Count() int {
+	return len(s.items)
+}
+
+// Push pushes the item onto the top of the stack
+func (s *RandomAccessStack) Push(item StackItem) {
+	s.items = append(s.items, item)
+}
+
+// Pop removes and returns the top item
+func (s *RandomAccessStack) Pop() StackItem {
+	return s.Remove(0)
+}
+
+// Peek returns the item at the index without removing it
+func (s *RandomAccessStack) Peek(index int) StackItem {
+	return s.items[s.position(index)]
+}
+
+// Set replaces the item at the index
+func (s *RandomAccessStack) Set(index int, item StackItem) {
+	s.items[s.position(index)] = item
+}
+
+// Insert inserts the item at the index, Insert(0, item) equals Push(item)
+func (s *RandomAccessStack) Insert(index int, item StackItem) {
+	if index < 0 || index > len(s.items) {
+		panic(stackError{})
+	}
+	p := len(s.items) - index
+	s.items = append(s.items, nil)
+	copy(s.items[p+1:], s.items[p:])
+	s.items[p] = item
+}
+
+// Remove removes and returns the item at the index
+func (s *RandomAccessStack) Remove(index int) StackItem {
+	p := s.position(index)
+	item := s.items[p]
+	s.items = append(s.items[:p], s.items[p+1:]...)
+	return item
+}
+
+// CopyTo pushes the bottom count items onto the other stack in order, -1 copies all the items
+func (s *RandomAccessStack) CopyTo(other *RandomAccessStack, count int) {
+	if count < 0 || count > len(s.items) {
+		count = len(s.items)
+	}
+	other.items = append(other.items, s.items[len(s.items)-count:]...)
+}
+
+// Clear removes all the items
+func (s *RandomAccessStack) Clear() {
+	s.items = nil
+}
+
+// Items returns the items from the bottom to the top
+func (s *RandomAccessStack) Items() []StackItem {
+	return s.items
+}
+
+func (s *RandomAccessStack) position(index int) int {
+	if index < 0 || index >= len(s.items) {
+		panic(stackError{})
+	}
+	return len(s.items) - 1 - index
+}
+
+type stackError struct{}
+
+func (stackError) Error() string {
+	return "stack index out of range"
+}
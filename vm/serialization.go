@@ -0,0 +1,142 @@
+package vm
+
+import (
+	"fmt"
+
+	"github.com/joeqian10/neo-gogogo/helper"
+	"github.com/joeqian10/neo-gogogo/helper/io"
+)
+
+// stack item types of Runtime.Serialize
+const (
+	byteArrayType byte = 0x00
+	booleanType   byte = 0x01
+	integerType   byte = 0x02
+	arrayType     byte = 0x80
+	structType    byte = 0x81
+	mapType       byte = 0x82
+)
+
+// SerializeStackItem serializes the stack item in the format of Runtime.Serialize
+func SerializeStackItem(item StackItem) ([]byte, error) {
+	w := io.NewBufBinaryWriter()
+	if err := serializeStackItem(w.BinaryWriter, item, map[StackItem]bool{}); err != nil {
+		return nil, err
+	}
+	if w.Err != nil {
+		return nil, w.Err
+	}
+	return w.Bytes(), nil
+}
+
+func serializeStackItem(w *io.BinaryWriter, item StackItem, path map[StackItem]bool) error {
+	switch v := item.(type) {
+	case *ByteArray:
+		w.WriteLE(byteArrayType)
+		w.WriteVarBytes(v.value)
+	case *Boolean:
+		w.WriteLE(booleanType)
+		w.WriteLE(v.value)
+	case *Integer:
+		w.WriteLE(integerType)
+		w.WriteVarBytes(helper.BigIntToNeoBytes(v.value))
+	case *Array, *Struct:
+		if path[item] {
+			return fmt.Errorf("cannot serialize circular reference")
+		}
+		path[item] = true
+		if _, ok := item.(*Struct); ok {
+			w.WriteLE(structType)
+		} else {
+			w.WriteLE(arrayType)
+		}
+		items := item.(arrayItem).Items()
+		w.WriteVarUint(uint64(len(items)))
+		for _, i := range items {
+			if err := serializeStackItem(w, i, path); err != nil {
+				return err
+			}
+		}
+		delete(path, item)
+	case *Map:
+		if path[item] {
+			return fmt.Errorf("cannot serialize circular reference")
+		}
+		path[item] = true
+		w.WriteLE(mapType)
+		w.WriteVarUint(uint64(v.Count()))
+		for i := range v.keys {
+			if err := serializeStackItem(w, v.keys[i], path); err != nil {
+				return err
+			}
+			if err := serializeStackItem(w, v.values[i], path); err != nil {
+				return err
+			}
+		}
+		delete(path, item)
+	default:
+		return fmt.Errorf("cannot serialize %T", item)
+	}
+	return nil
+}
+
+// DeserializeStackItem deserializes the stack item serialized by Runtime.Serialize
+func DeserializeStackItem(data []byte) (StackItem, error) {
+	r := io.NewBinaryReaderFromBuf(data)
+	item, err := deserializeStackItem(r)
+	if err != nil {
+		return nil, err
+	}
+	return item, r.Err
+}
+
+func deserializeStackItem(r *io.BinaryReader) (StackItem, error) {
+	var t byte
+	r.ReadLE(&t)
+	if r.Err != nil {
+		return nil, r.Err
+	}
+	switch t {
+	case byteArrayType:
+		return NewByteArray(r.ReadVarBytes()), nil
+	case booleanType:
+		var b bool
+		r.ReadLE(&b)
+		return NewBoolean(b), nil
+	case integerType:
+		return NewInteger(helper.BigIntFromNeoBytes(r.ReadVarBytes())), nil
+	case arrayType, structType, mapType:
+		count := r.ReadVarUint()
+		if count > MaxArraySize {
+			return nil, fmt.Errorf("array size %d exceeds %d", count, MaxArraySize)
+		}
+		if t == mapType {
+			m := NewMap()
+			for i := uint64(0); i < count; i++ {
+				key, err := deserializeStackItem(r)
+				if err != nil {
+					return nil, err
+				}
+				value, err := deserializeStackItem(r)
+				if err != nil {
+					return nil, err
+				}
+				m.Set(key, value)
+			}
+			return m, nil
+		}
+		items := make([]StackItem, count)
+		for i := range items {
+			item, err := deserializeStackItem(r)
+			if err != nil {
+				return nil, err
+			}
+			items[i] = item
+		}
+		if t == structType {
+			return NewStruct(items), nil
+		}
+		return NewArray(items), nil
+	}
+	return nil, fmt.Errorf("invalid stack item type 0x%02x", t)
+}
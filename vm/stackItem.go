@@ -0,0 +1,466 @@
+package vm
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"reflect"
+
+	"github.com/joeqian10/neo-gogogo/helper"
+	"github.com/joeqian10/neo-gogogo/rpc/models"
+)
+
+// CircularReference is the value of an Array, Struct or Map which contains itself in ToInvokeStack
+const CircularReference = "circular reference"
+
+// StackItem is an item on the evaluation stack of neo vm 2.x
+type StackItem interface {
+	GetBigInteger() (*big.Int, error)
+	GetByteArray() ([]byte, error)
+	GetBoolean() bool
+	Equals(other StackItem) bool
+	ToInvokeStack() models.InvokeStack
+}
+
+// ByteArray is a byte array stack item
+type ByteArray struct {
+	value []byte
+}
+
+// Integer is an integer stack item
+type Integer struct {
+	value *big.Int
+}
+
+// Boolean is a boolean stack item
+type Boolean struct {
+	value bool
+}
+
+// Array is a reference type array stack item
+type Array struct {
+	items []StackItem
+}
+
+// Struct is a value type array stack item, it is cloned when stored in another container
+type Struct struct {
+	Array
+}
+
+// Map is a reference type map stack item, the keys are primitive items
+type Map struct {
+	keys   []StackItem
+	values []StackItem
+}
+
+// InteropInterface wraps an object provided by the interop service
+type InteropInterface struct {
+	Value interface{}
+}
+
+func NewByteArray(value []byte) *ByteArray {
+	return &ByteArray{value: value}
+}
+
+func NewInteger(value *big.Int) *Integer {
+	return &Integer{value: value}
+}
+
+func NewIntegerFromInt64(value int64) *Integer {
+	return &Integer{value: big.NewInt(value)}
+}
+
+func NewBoolean(value bool) *Boolean {
+	return &Boolean{value: value}
+}
+
+func NewArray(items []StackItem) *Array {
+	return &Array{items: items}
+}
+
+func NewStruct(items []StackItem) *Struct {
+	return &Struct{Array{items: items}}
+}
+
+func NewMap() *Map {
+	return &Map{}
+}
+
+func NewInteropInterface(value interface{}) *InteropInterface {
+	return &InteropInterface{Value: value}
+}
+
+// NewStackItem converts a go value to a stack item
+func NewStackItem(value interface{}) (StackItem, error) {
+	switch v := value.(type) {
+	case StackItem:
+		return v, nil
+	case []byte:
+		return NewByteArray(v), nil
+	case string:
+		return NewByteArray([]byte(v)), nil
+	case bool:
+		return NewBoolean(v), nil
+	case int:
+		return NewIntegerFromInt64(int64(v)), nil
+	case int64:
+		return NewIntegerFromInt64(v), nil
+	case uint32:
+		return NewIntegerFromInt64(int64(v)), nil
+	case *big.Int:
+		return NewInteger(v), nil
+	case helper.UInt160:
+		return NewByteArray(v.Bytes()), nil
+	case helper.UInt256:
+		return NewByteArray(v.Bytes()), nil
+	case []StackItem:
+		return NewArray(v), nil
+	}
+	return nil, fmt.Errorf("cannot convert %T to stack item", value)
+}
+
+// ByteArray
+
+func (b *ByteArray) GetBigInteger() (*big.Int, error) {
+	if len(b.value) > MaxSizeForBigInteger {
+		return nil, fmt.Errorf("byte array of %d bytes is too long for an integer", len(b.value))
+	}
+	return helper.BigIntFromNeoBytes(b.value), nil
+}
+
+func (b *ByteArray) GetByteArray() ([]byte, error) {
+	return b.value, nil
+}
+
+func (b *ByteArray) GetBoolean() bool {
+	if len(b.value) > MaxSizeForBigInteger {
+		return true
+	}
+	for _, v := range b.value {
+		if v != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func (b *ByteArray) Equals(other StackItem) bool {
+	if b == other {
+		return true
+	}
+	o, err := other.GetByteArray()
+	if err != nil {
+		return false
+	}
+	return bytes.Equal(b.value, o)
+}
+
+func (b *ByteArray) ToInvokeStack() models.InvokeStack {
+	return models.InvokeStack{Type: models.StackTypeByteArray, Value: helper.BytesToHex(b.value)}
+}
+
+// Integer
+
+func (i *Integer) GetBigInteger() (*big.Int, error) {
+	return i.value, nil
+}
+
+func (i *Integer) GetByteArray() ([]byte, error) {
+	return helper.BigIntToNeoBytes(i.value), nil
+}
+
+func (i *Integer) GetBoolean() bool {
+	return i.value.Sign() != 0
+}
+
+func (i *Integer) Equals(other StackItem) bool {
+	if i == other {
+		return true
+	}
+	if o, ok := other.(*Integer); ok {
+		return i.value.Cmp(o.value) == 0
+	}
+	o, err := other.GetByteArray()
+	if err != nil {
+		return false
+	}
+	b, _ := i.GetByteArray()
+	return bytes.Equal(b, o)
+}
+
+func (i *Integer) ToInvokeStack() models.InvokeStack {
+	return models.InvokeStack{Type: models.StackTypeInteger, Value: i.value.String()}
+}
+
+// Boolean
+
+func (b *Boolean) GetBigInteger() (*big.Int, error) {
+	if b.value {
+		return big.NewInt(1), nil
+	}
+	return big.NewInt(0), nil
+}
+
+func (b *Boolean) GetByteArray() ([]byte, error) {
+	if b.value {
+		return []byte{1}, nil
+	}
+	return []byte{}, nil
+}
+
+func (b *Boolean) GetBoolean() bool {
+	return b.value
+}
+
+func (b *Boolean) Equals(other StackItem) bool {
+	if b == other {
+		return true
+	}
+	if o, ok := other.(*Boolean); ok {
+		return b.value == o.value
+	}
+	o, err := other.GetByteArray()
+	if err != nil {
+		return false
+	}
+	v, _ := b.GetByteArray()
+	return bytes.Equal(v, o)
+}
+
+func (b *Boolean) ToInvokeStack() models.InvokeStack {
+	return models.InvokeStack{Type: models.StackTypeBoolean, Value: b.value}
+}
+
+// Array
+
+// Items returns the items of the array
+func (a *Array) Items() []StackItem {
+	return a.items
+}
+
+func (a *Array) GetBigInteger() (*big.Int, error) {
+	return nil, fmt.Errorf("cannot convert array to integer")
+}
+
+func (a *Array) GetByteArray() ([]byte, error) {
+	return nil, fmt.Errorf("cannot convert array to byte array")
+}
+
+func (a *Array) GetBoolean() bool {
+	return true
+}
+
+func (a *Array) Equals(other StackItem) bool {
+	return StackItem(a) == other
+}
+
+func (a *Array) ToInvokeStack() models.InvokeStack {
+	return toInvokeStack(a, map[StackItem]bool{})
+}
+
+func toInvokeStacks(items []StackItem) []models.InvokeStack {
+	result := make([]models.InvokeStack, len(items))
+	for i, item := range items {
+		result[i] = toInvokeStack(item, map[StackItem]bool{})
+	}
+	return result
+}
+
+// toInvokeStack converts the item with the containers on the path from the root, a container which
+// contains itself is converted to its type with the value CircularReference instead of its items
+func toInvokeStack(item StackItem, path map[StackItem]bool) models.InvokeStack {
+	var stackType string
+	switch item.(type) {
+	case *Array:
+		stackType = models.StackTypeArray
+	case *Struct:
+		stackType = models.StackTypeStruct
+	case *Map:
+		stackType = models.StackTypeMap
+	default:
+		return item.ToInvokeStack()
+	}
+	if path[item] {
+		return models.InvokeStack{Type: stackType, Value: CircularReference}
+	}
+	path[item] = true
+	defer delete(path, item)
+	if m, ok := item.(*Map); ok {
+		entries := make([]models.StackMapEntry, len(m.keys))
+		for i := range m.keys {
+			entries[i] = models.StackMapEntry{Key: toInvokeStack(m.keys[i], path), Value: toInvokeStack(m.values[i], path)}
+		}
+		return models.InvokeStack{Type: stackType, Value: entries}
+	}
+	items := item.(arrayItem).Items()
+	result := make([]models.InvokeStack, len(items))
+	for i := range items {
+		result[i] = toInvokeStack(items[i], path)
+	}
+	return models.InvokeStack{Type: stackType, Value: result}
+}
+
+// Struct
+
+// Clone copies the struct and its nested structs, a struct which contains itself is cloned to a clone
+// which contains the clone
+func (s *Struct) Clone() *Struct {
+	return s.clone(map[*Struct]*Struct{})
+}
+
+func (s *Struct) clone(clones map[*Struct]*Struct) *Struct {
+	if c, ok := clones[s]; ok {
+		return c
+	}
+	c := NewStruct(make([]StackItem, len(s.items)))
+	clones[s] = c
+	for i, item := range s.items {
+		if inner, ok := item.(*Struct); ok {
+			c.items[i] = inner.clone(clones)
+		} else {
+			c.items[i] = item
+		}
+	}
+	return c
+}
+
+func (s *Struct) Equals(other StackItem) bool {
+	return s.equals(other, map[[2]*Struct]bool{})
+}
+
+// equals compares the items, a pair of structs compared again on the path is assumed equal, so the
+// structs which contain themselves are equal if they have the same shape
+func (s *Struct) equals(other StackItem, path map[[2]*Struct]bool) bool {
+	if StackItem(s) == other {
+		return true
+	}
+	o, ok := other.(*Struct)
+	if !ok || len(s.items) != len(o.items) {
+		return false
+	}
+	pair := [2]*Struct{s, o}
+	if path[pair] {
+		return true
+	}
+	path[pair] = true
+	defer delete(path, pair)
+	for i := range s.items {
+		if inner, ok := s.items[i].(*Struct); ok {
+			if !inner.equals(o.items[i], path) {
+				return false
+			}
+		} else if !s.items[i].Equals(o.items[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *Struct) ToInvokeStack() models.InvokeStack {
+	return toInvokeStack(s, map[StackItem]bool{})
+}
+
+// Map
+
+// Count returns the number of entries
+func (m *Map) Count() int {
+	return len(m.keys)
+}
+
+// Get returns the value of the key
+func (m *Map) Get(key StackItem) (StackItem, bool) {
+	i := m.indexOf(key)
+	if i < 0 {
+		return nil, false
+	}
+	return m.values[i], true
+}
+
+// Set adds or replaces the value of the key
+func (m *Map) Set(key StackItem, value StackItem) {
+	if i := m.indexOf(key); i >= 0 {
+		m.values[i] = value
+		return
+	}
+	m.keys = append(m.keys, key)
+	m.values = append(m.values, value)
+}
+
+// Remove removes the key
+func (m *Map) Remove(key StackItem) {
+	if i := m.indexOf(key); i >= 0 {
+		m.keys = append(m.keys[:i], m.keys[i+1:]...)
+		m.values = append(m.values[:i], m.values[i+1:]...)
+	}
+}
+
+// Keys returns the keys in insertion order
+func (m *Map) Keys() []StackItem {
+	return m.keys
+}
+
+// Values returns the values in insertion order
+func (m *Map) Values() []StackItem {
+	return m.values
+}
+
+func (m *Map) indexOf(key StackItem) int {
+	for i, k := range m.keys {
+		if k.Equals(key) {
+			return i
+		}
+	}
+	return -1
+}
+
+func (m *Map) GetBigInteger() (*big.Int, error) {
+	return nil, fmt.Errorf("cannot convert map to integer")
+}
+
+func (m *Map) GetByteArray() ([]byte, error) {
+	return nil, fmt.Errorf("cannot convert map to byte array")
+}
+
+func (m *Map) GetBoolean() bool {
+	return true
+}
+
+func (m *Map) Equals(other StackItem) bool {
+	return StackItem(m) == other
+}
+
+func (m *Map) ToInvokeStack() models.InvokeStack {
+	return toInvokeStack(m, map[StackItem]bool{})
+}
+
+// InteropInterface
+
+func (i *InteropInterface) GetBigInteger() (*big.Int, error) {
+	return nil, fmt.Errorf("cannot convert interop interface to integer")
+}
+
+func (i *InteropInterface) GetByteArray() ([]byte, error) {
+	return nil, fmt.Errorf("cannot convert interop interface to byte array")
+}
+
+func (i *InteropInterface) GetBoolean() bool {
+	return i.Value != nil
+}
+
+func (i *InteropInterface) Equals(other StackItem) bool {
+	if StackItem(i) == other {
+		return true
+	}
+	o, ok := other.(*InteropInterface)
+	if !ok || i.Value == nil || o.Value == nil {
+		return ok && i.Value == o.Value
+	}
+	if !reflect.TypeOf(i.Value).Comparable() || !reflect.TypeOf(o.Value).Comparable() {
+		return false
+	}
+	return i.Value == o.Value
+}
+
+func (i *InteropInterface) ToInvokeStack() models.InvokeStack {
+	return models.InvokeStack{Type: models.StackTypeInteropInterface}
+}
@@ -0,0 +1,69 @@
+package vm
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStackItem_Equals(t *testing.T) {
+	assert.True(t, NewIntegerFromInt64(1).Equals(NewBoolean(true)))
+	assert.True(t, NewByteArray([]byte{0xff}).Equals(NewIntegerFromInt64(-1)))
+	assert.False(t, NewByteArray([]byte{}).Equals(NewArray(nil)))
+
+	a := NewArray([]StackItem{NewIntegerFromInt64(1)})
+	assert.True(t, a.Equals(a))
+	assert.False(t, a.Equals(NewArray([]StackItem{NewIntegerFromInt64(1)})))
+
+	s := NewStruct([]StackItem{NewIntegerFromInt64(1), NewStruct([]StackItem{NewBoolean(true)})})
+	c := s.Clone()
+	assert.True(t, s.Equals(c))
+	c.items[1].(*Struct).items[0] = NewBoolean(false)
+	assert.False(t, s.Equals(c))
+}
+
+func TestStackItem_CircularReference(t *testing.T) {
+	// a struct which contains itself, e.g. built through the shared items of NewStruct
+	items := []StackItem{NewIntegerFromInt64(1), nil}
+	s := NewStruct(items)
+	items[1] = s
+	c := s.Clone()
+	assert.True(t, c.items[1] == c)
+	assert.True(t, s.Equals(c))
+	c.items[0] = NewIntegerFromInt64(2)
+	assert.False(t, s.Equals(c))
+
+	r := s.ToInvokeStack()
+	assert.Equal(t, "Struct", r.Type)
+	inner, err := r.AsArray()
+	assert.Nil(t, err)
+	assert.Equal(t, "Struct", inner[1].Type)
+	assert.Equal(t, CircularReference, inner[1].Value)
+	_, err = inner[1].AsArray()
+	assert.NotNil(t, err)
+
+	m := NewMap()
+	m.Set(NewByteArray([]byte("self")), m)
+	r = m.ToInvokeStack()
+	entries, err := r.AsMap()
+	assert.Nil(t, err)
+	assert.Equal(t, CircularReference, entries[0].Value.Value)
+
+	// the same array twice is not circular
+	a := NewArray([]StackItem{NewIntegerFromInt64(1)})
+	r = NewArray([]StackItem{a, a}).ToInvokeStack()
+	outer, _ := r.AsArray()
+	first, err := outer[1].AsArray()
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(first))
+}
+
+func TestByteArray_GetBigInteger(t *testing.T) {
+	i, err := NewByteArray([]byte{0x00, 0x80}).GetBigInteger()
+	assert.Nil(t, err)
+	assert.Equal(t, big.NewInt(-32768), i)
+
+	_, err = NewByteArray(make([]byte, 33)).GetBigInteger()
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,167 @@
+package vm
+
+import (
+	"bytes"
+	"sort"
+	"sync"
+
+	"github.com/joeqian10/neo-gogogo/blockchain"
+	"github.com/joeqian10/neo-gogogo/helper"
+	"github.com/joeqian10/neo-gogogo/helper/io"
+)
+
+// Store is the key value store behind the contract storage,
+// the keys are serialized blockchain.StorageKey and the values are serialized blockchain.StorageItem
+type Store interface {
+	// Get returns nil if the key is not found
+	Get(key []byte) ([]byte, error)
+	Put(key []byte, value []byte) error
+	Delete(key []byte) error
+	// Seek calls f with the entries whose key starts with the prefix in key order, until f returns false
+	Seek(prefix []byte, f func(key []byte, value []byte) bool) error
+}
+
+// MemoryStore is a Store in memory
+type MemoryStore struct {
+	mu   sync.RWMutex
+	data map[string][]byte
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{data: map[string][]byte{}}
+}
+
+func (s *MemoryStore) Get(key []byte) ([]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.data[string(key)], nil
+}
+
+func (s *MemoryStore) Put(key []byte, value []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data[string(key)] = append([]byte{}, value...)
+	return nil
+}
+
+func (s *MemoryStore) Delete(key []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.data, string(key))
+	return nil
+}
+
+func (s *MemoryStore) Seek(prefix []byte, f func(key []byte, value []byte) bool) error {
+	s.mu.RLock()
+	var keys []string
+	for k := range s.data {
+		if bytes.HasPrefix([]byte(k), prefix) {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	values := make([][]byte, len(keys))
+	for i, k := range keys {
+		values[i] = s.data[k]
+	}
+	s.mu.RUnlock()
+	for i, k := range keys {
+		if !f([]byte(k), values[i]) {
+			break
+		}
+	}
+	return nil
+}
+
+// StorageContext is the value of the InteropInterface returned by Storage.GetContext
+type StorageContext struct {
+	ScriptHash helper.UInt160
+	IsReadOnly bool
+}
+
+func serializeStorageKey(key blockchain.StorageKey) []byte {
+	w := io.NewBufBinaryWriter()
+	key.Serialize(w.BinaryWriter)
+	return w.Bytes()
+}
+
+// GetStorageItem reads the storage item from the store, it returns nil if the key is not found
+func GetStorageItem(store Store, key blockchain.StorageKey) (*blockchain.StorageItem, error) {
+	b, err := store.Get(serializeStorageKey(key))
+	if err != nil || b == nil {
+		return nil, err
+	}
+	item := &blockchain.StorageItem{}
+	r := io.NewBinaryReaderFromBuf(b)
+	item.Deserialize(r)
+	return item, r.Err
+}
+
+// PutStorageItem writes the storage item to the store
+func PutStorageItem(store Store, key blockchain.StorageKey, item *blockchain.StorageItem) error {
+	w := io.NewBufBinaryWriter()
+	item.Serialize(w.BinaryWriter)
+	if w.Err != nil {
+		return w.Err
+	}
+	return store.Put(serializeStorageKey(key), w.Bytes())
+}
+
+// DeleteStorageItem removes the storage item from the store
+func DeleteStorageItem(store Store, key blockchain.StorageKey) error {
+	return store.Delete(serializeStorageKey(key))
+}
+
+// FindStorageItems returns the storage items of the contract whose key starts with the prefix
+func FindStorageItems(store Store, scriptHash helper.UInt160, prefix []byte) ([]blockchain.StorageKey, []*blockchain.StorageItem, error) {
+	var keys []blockchain.StorageKey
+	var items []*blockchain.StorageItem
+	var err error
+	e := store.Seek(scriptHash.Bytes(), func(k []byte, v []byte) bool {
+		key := blockchain.StorageKey{}
+		r := io.NewBinaryReaderFromBuf(k)
+		key.Deserialize(r)
+		if r.Err != nil {
+			err = r.Err
+			return false
+		}
+		if !bytes.HasPrefix(key.Key, prefix) {
+			return true
+		}
+		item := &blockchain.StorageItem{}
+		r = io.NewBinaryReaderFromBuf(v)
+		item.Deserialize(r)
+		if r.Err != nil {
+			err = r.Err
+			return false
+		}
+		keys = append(keys, key)
+		items = append(items, item)
+		return true
+	})
+	if e != nil {
+		return nil, nil, e
+	}
+	return keys, items, err
+}
+
+// storageIterator is the value of the InteropInterface returned by Storage.Find
+type storageIterator struct {
+	keys   []StackItem
+	values []StackItem
+	index  int
+}
+
+func (it *storageIterator) next() bool {
+	if it.index < len(it.keys) {
+		it.index++
+	}
+	return it.index < len(it.keys)
+}
+
+func (it *storageIterator) current() (StackItem, StackItem, bool) {
+	if it.index < 0 || it.index >= len(it.keys) {
+		return nil, nil, false
+	}
+	return it.keys[it.index], it.values[it.index], true
+}
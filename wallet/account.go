@@ -1,6 +1,14 @@
 package wallet
 
 import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/joeqian10/neo-gogogo/helper"
+	"github.com/joeqian10/neo-gogogo/sc"
 	"github.com/joeqian10/neo-gogogo/wallet/keys"
 )
 
@@ -10,6 +18,10 @@ type Account struct {
 	// NEO  KeyPair.
 	KeyPair *keys.KeyPair `json:"-"`
 
+	// Signer signs for the account instead of the KeyPair when it is not nil, e.g. a keys.RemoteSigner
+	// of a hsm, so that the private key never enters the process.
+	Signer keys.Signer `json:"-"`
+
 	// NEO public address.
 	Address string `json:"address"`
 
@@ -32,31 +44,81 @@ type Account struct {
 
 	// This field can be empty.
 	Extra interface{} `json:"extra"`
+
+	// whether the label was null and the key was "" in the loaded json, so that they are saved back as they were
+	nullLabel bool
+	emptyKey  bool
+
+	// keyMu guards KeyPair against Lock of the wallet, which clears it while the account may be signing
+	keyMu sync.RWMutex
+}
+
+// accountJson is the NEP-6 json form of Account, in which the label and the key can be null
+type accountJson struct {
+	Address  string      `json:"address"`
+	Label    *string     `json:"label"`
+	Default  bool        `json:"isDefault"`
+	Locked   bool        `json:"lock"`
+	Nep2Key  *string     `json:"key"`
+	Contract *Contract   `json:"contract"`
+	Extra    interface{} `json:"extra"`
 }
 
 // Contract represents a subset of the smart contract to embed in the
 // Account so it's NEP-6 compliant.
 type Contract struct {
-	// Script hash of the contract deployed on the block chain.
+	// Hex string of the verification script.
 	Script string `json:"script"`
 
-	// A list of parameters used deploying this contract.
-	Parameters []interface{} `json:"parameters"`
+	// A list of parameters of the verification script.
+	Parameters []Parameter `json:"parameters"`
 
 	// Indicates whether the contract has been deployed to the block chain.
 	Deployed bool `json:"deployed"`
 }
 
+// Parameter is a named parameter of the verification script
+type Parameter struct {
+	Name string                   `json:"name"`
+	Type sc.ContractParameterType `json:"type"`
+}
+
 // NewAccountFromKeyPair created a wallet from the given PrivateKey.
 func NewAccountFromKeyPair(p *keys.KeyPair) *Account {
 	pubAddr := p.PublicKey.Address()
 	a := &Account{
 		KeyPair: p,
 		Address: pubAddr,
+		Contract: &Contract{
+			Script:     helper.BytesToHex(keys.CreateSignatureRedeemScript(p.PublicKey)),
+			Parameters: []Parameter{{Name: "signature", Type: sc.Signature}},
+		},
 	}
 	return a
 }
 
+// NewAccountFromSigner creates a signature account of the public key of the signer
+func NewAccountFromSigner(signer keys.Signer) *Account {
+	p := signer.Public()
+	return &Account{
+		Signer:  signer,
+		Address: p.Address(),
+		Contract: &Contract{
+			Script:     helper.BytesToHex(keys.CreateSignatureRedeemScript(p)),
+			Parameters: []Parameter{{Name: "signature", Type: sc.Signature}},
+		},
+	}
+}
+
+// NewWatchOnlyAccount creates an account of the address without any key or contract,
+// transactions of the account can be built but must be signed elsewhere
+func NewWatchOnlyAccount(address string) (*Account, error) {
+	if _, err := helper.AddressToScriptHash(address); err != nil {
+		return nil, err
+	}
+	return &Account{Address: address}, nil
+}
+
 // NewAccount creates a new Account with a random generated PrivateKey.
 func NewAccount() (*Account, error) {
 	privateKey, err := keys.GenerateKeyPair()
@@ -85,26 +147,262 @@ func NewAccountFromNEP2(nep2Key, passphrase string) (*Account, error) {
 	return NewAccountFromKeyPair(wif), nil
 }
 
+// NewAccountFromContract creates an account of the verification script. The key pair is optional,
+// if given it should be a key of the signature or multi-signature script.
+// Use NewAccountFromContractParameters for a custom contract with known parameters.
+func NewAccountFromContract(script []byte, pair *keys.KeyPair) (*Account, error) {
+	vs, err := keys.ParseVerificationScript(script)
+	if err != nil {
+		return nil, err
+	}
+	parameters := []Parameter{}
+	switch vs.Kind {
+	case keys.SignatureScript:
+		parameters = append(parameters, Parameter{Name: "signature", Type: sc.Signature})
+	case keys.MultiSigScript:
+		for i := 0; i < vs.M; i++ {
+			parameters = append(parameters, Parameter{Name: fmt.Sprintf("parameter%d", i), Type: sc.Signature})
+		}
+	}
+	if pair != nil {
+		found := false
+		for _, p := range vs.PublicKeys {
+			if bytes.Equal(p.EncodeCompression(), pair.PublicKey.EncodeCompression()) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("the key pair is not in the %s verification script", vs.Kind)
+		}
+	}
+	return &Account{
+		KeyPair: pair,
+		Address: helper.ScriptHashToAddress(vs.ScriptHash),
+		Contract: &Contract{
+			Script:     helper.BytesToHex(script),
+			Parameters: parameters,
+		},
+	}, nil
+}
+
+// NewAccountFromContractParameters creates a watch-only account of a custom verification script
+// with the parameters its invocation script should push
+func NewAccountFromContractParameters(script []byte, parameters []Parameter) (*Account, error) {
+	acc, err := NewAccountFromContract(script, nil)
+	if err != nil {
+		return nil, err
+	}
+	acc.Contract.Parameters = parameters
+	return acc, nil
+}
+
+// IsWatchOnly reports whether the account has neither a signer, a key pair nor an encrypted key
+func (a *Account) IsWatchOnly() bool {
+	return a.Signer == nil && !a.hasKeyPair() && a.Nep2Key == ""
+}
+
+// GetSigner returns the Signer of the account, or a signer of the KeyPair if the Signer is not set, nil if neither
+// is set. The signer of the KeyPair reads it under the lock of the account, and fails once the wallet is locked.
+func (a *Account) GetSigner() keys.Signer {
+	if a.Signer != nil {
+		return a.Signer
+	}
+	a.keyMu.RLock()
+	defer a.keyMu.RUnlock()
+	if a.KeyPair != nil {
+		return &accountSigner{account: a, publicKey: a.KeyPair.PublicKey}
+	}
+	return nil
+}
+
+// hasKeyPair reports whether the KeyPair is set, under the lock of the account
+func (a *Account) hasKeyPair() bool {
+	a.keyMu.RLock()
+	defer a.keyMu.RUnlock()
+	return a.KeyPair != nil
+}
+
+// isUnencrypted reports whether the account has a KeyPair which is not encrypted to the Nep2Key yet
+func (a *Account) isUnencrypted() bool {
+	a.keyMu.RLock()
+	defer a.keyMu.RUnlock()
+	return a.KeyPair != nil && a.Nep2Key == ""
+}
+
+// isEncrypted reports whether the account has a Nep2Key which is not decrypted to the KeyPair yet
+func (a *Account) isEncrypted() bool {
+	a.keyMu.RLock()
+	defer a.keyMu.RUnlock()
+	return a.KeyPair == nil && a.Nep2Key != ""
+}
+
+// clearKeyPair clears and removes the KeyPair, waiting for the signatures in progress
+func (a *Account) clearKeyPair() {
+	a.keyMu.Lock()
+	defer a.keyMu.Unlock()
+	if a.KeyPair != nil {
+		a.KeyPair.Clear()
+		a.KeyPair = nil
+	}
+}
+
+// accountSigner signs with the KeyPair of the account while holding the read lock of the account
+type accountSigner struct {
+	account   *Account
+	publicKey *keys.PublicKey
+}
+
+// Public implements the keys.Signer interface.
+func (s *accountSigner) Public() *keys.PublicKey {
+	return s.publicKey
+}
+
+// Sign implements the keys.Signer interface.
+func (s *accountSigner) Sign(message []byte) ([]byte, error) {
+	s.account.keyMu.RLock()
+	defer s.account.keyMu.RUnlock()
+	if s.account.KeyPair == nil {
+		return nil, fmt.Errorf("the account %s is locked", s.account.Address)
+	}
+	return s.account.KeyPair.Sign(message)
+}
+
+// ScriptHash returns the script hash of the address
+func (a *Account) ScriptHash() (helper.UInt160, error) {
+	return helper.AddressToScriptHash(a.Address)
+}
+
+// VerificationScript returns the verification script of the contract, which is nil for a watch-only address
+func (a *Account) VerificationScript() ([]byte, error) {
+	if a.Contract == nil {
+		if signer := a.GetSigner(); signer != nil {
+			return keys.CreateSignatureRedeemScript(signer.Public()), nil
+		}
+		return nil, nil
+	}
+	script, err := hex.DecodeString(a.Contract.Script)
+	if err != nil {
+		return nil, err
+	}
+	return script, nil
+}
+
+// canSignAlone reports whether the signer of the account is enough to sign its transactions
+func (a *Account) canSignAlone() error {
+	signer := a.GetSigner()
+	if signer == nil {
+		if a.Nep2Key != "" {
+			return fmt.Errorf("the account %s is not decrypted", a.Address)
+		}
+		return fmt.Errorf("the account %s is watch-only", a.Address)
+	}
+	script, err := a.VerificationScript()
+	if err != nil {
+		return err
+	}
+	vs, err := keys.ParseVerificationScript(script)
+	if err != nil {
+		return err
+	}
+	if vs.Kind != keys.SignatureScript || !bytes.Equal(vs.PublicKeys[0].EncodeCompression(), signer.Public().EncodeCompression()) {
+		return fmt.Errorf("the %s contract of the account %s cannot be signed with its key alone", vs.Kind, a.Address)
+	}
+	return nil
+}
+
+// MarshalJSON implements the json marshaller interface, an empty label or key is written as it was loaded,
+// and an empty key of a new account is null as NEP-6 requires
+func (a *Account) MarshalJSON() ([]byte, error) {
+	j := accountJson{
+		Address:  a.Address,
+		Default:  a.Default,
+		Locked:   a.Locked,
+		Contract: a.Contract,
+		Extra:    a.Extra,
+	}
+	if a.Label != "" || !a.nullLabel {
+		j.Label = &a.Label
+	}
+	if a.Nep2Key != "" || a.emptyKey {
+		j.Nep2Key = &a.Nep2Key
+	}
+	return json.Marshal(j)
+}
+
+// UnmarshalJSON implements the json unmarshaller interface.
+func (a *Account) UnmarshalJSON(data []byte) error {
+	var j accountJson
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	*a = Account{
+		Address:   j.Address,
+		Default:   j.Default,
+		Locked:    j.Locked,
+		Contract:  j.Contract,
+		Extra:     j.Extra,
+		nullLabel: j.Label == nil,
+		emptyKey:  j.Nep2Key != nil && *j.Nep2Key == "",
+	}
+	if j.Label != nil {
+		a.Label = *j.Label
+	}
+	if j.Nep2Key != nil {
+		a.Nep2Key = *j.Nep2Key
+	}
+	return nil
+}
+
 // Encrypt encrypts the wallet's PrivateKey with the given passphrase under the NEP-2 standard.
 func (a *Account) Encrypt(passphrase string) (err error) {
-	if a.Nep2Key, err = keys.NEP2Encrypt(a.KeyPair, passphrase); err != nil {
+	return a.EncryptWithParams(passphrase, keys.DefaultScryptParams())
+}
+
+// EncryptWithParams encrypts the PrivateKey with the scrypt parameters of the wallet
+func (a *Account) EncryptWithParams(passphrase string, params keys.ScryptParams) (err error) {
+	// the slow encryption only needs the read lock, so the account can sign meanwhile
+	a.keyMu.RLock()
+	if a.KeyPair == nil {
+		a.keyMu.RUnlock()
+		return fmt.Errorf("the account %s is locked", a.Address)
+	}
+	nep2Key, err := keys.NEP2EncryptWithParams(a.KeyPair, passphrase, params)
+	var address string
+	if err == nil {
+		address = a.KeyPair.PublicKey.Address()
+	}
+	a.keyMu.RUnlock()
+	if err != nil {
 		return err
 	}
 
+	a.keyMu.Lock()
+	defer a.keyMu.Unlock()
+	a.Nep2Key = nep2Key
 	if a.Address == "" {
-		a.Address = a.KeyPair.PublicKey.Address()
+		a.Address = address
 	}
 	return nil
 }
 
 // Decrypt encrypts the wallet's PrivateKey with the given passphrase under the NEP-2 standard.
 func (a *Account) Decrypt(passphrase string) (err error) {
+	return a.DecryptWithParams(passphrase, keys.DefaultScryptParams())
+}
+
+// DecryptWithParams decrypts the Nep2Key with the scrypt parameters of the wallet
+func (a *Account) DecryptWithParams(passphrase string, params keys.ScryptParams) (err error) {
+	a.keyMu.Lock()
+	defer a.keyMu.Unlock()
 	if a.KeyPair == nil {
-		a.KeyPair, err = keys.NEP2Decrypt(a.Nep2Key, passphrase)
+		if a.KeyPair, err = keys.NEP2DecryptWithParams(a.Nep2Key, passphrase, params); err != nil {
+			return err
+		}
 	}
 
 	if a.Address == "" {
 		a.Address = a.KeyPair.PublicKey.Address()
 	}
-	return err
+	return nil
 }
@@ -1,10 +1,14 @@
 package wallet
 
 import (
+	"encoding/json"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 
+	"github.com/joeqian10/neo-gogogo/crypto"
+	"github.com/joeqian10/neo-gogogo/helper"
+	"github.com/joeqian10/neo-gogogo/sc"
 	"github.com/joeqian10/neo-gogogo/wallet/keys"
 )
 
@@ -72,3 +76,75 @@ func compareFields(t *testing.T, tk keys.Ktype, acc *Account) {
 		t.Fatalf("expected %s got %s", want, have)
 	}
 }
+
+func TestNewAccountFromSigner(t *testing.T) {
+	pair, _ := keys.NewKeyPairFromWIF(keys.KeyCases[0].Wif)
+	acc := NewAccountFromSigner(keys.NewKeyPairSigner(pair, keys.SignOptions{LowS: true}))
+	assert.Nil(t, acc.KeyPair)
+	assert.False(t, acc.IsWatchOnly())
+	assert.Equal(t, keys.KeyCases[0].Address, acc.Address)
+	assert.Equal(t, pair.PublicKey, acc.GetSigner().Public())
+	assert.Nil(t, acc.canSignAlone())
+
+	// the signer is preferred to the key pair
+	acc.KeyPair = pair
+	_, ok := acc.GetSigner().(*keys.KeyPairSigner)
+	assert.True(t, ok)
+}
+
+func TestNewAccountFromContract(t *testing.T) {
+	pairs := make([]*keys.KeyPair, 3)
+	publicKeys := make([]*keys.PublicKey, 3)
+	for i := range pairs {
+		pairs[i], _ = keys.NewKeyPairFromWIF(keys.KeyCases[i].Wif)
+		publicKeys[i] = pairs[i].PublicKey
+	}
+	script, _ := keys.CreateMultiSigRedeemScript(2, publicKeys...)
+	acc, err := NewAccountFromContract(script, pairs[1])
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(acc.Contract.Parameters))
+	assert.Equal(t, helper.BytesToHex(script), acc.Contract.Script)
+	hash, _ := helper.UInt160FromBytes(crypto.Hash160(script))
+	assert.Equal(t, helper.ScriptHashToAddress(hash), acc.Address)
+
+	acc, err = NewAccountFromContract(keys.CreateSignatureRedeemScript(pairs[0].PublicKey), nil)
+	assert.Nil(t, err)
+	assert.Equal(t, keys.KeyCases[0].Address, acc.Address)
+	assert.Equal(t, 1, len(acc.Contract.Parameters))
+
+	pair, _ := keys.NewKeyPairFromWIF(keys.KeyCases[3].Wif)
+	_, err = NewAccountFromContract(script, pair)
+	assert.NotNil(t, err)
+}
+
+func TestAccount_MarshalJSON(t *testing.T) {
+	acc, err := NewWatchOnlyAccount("AJh4YxusYvG3SPzatzv1yWaKVn4iYJ6xua")
+	assert.Nil(t, err)
+	assert.True(t, acc.IsWatchOnly())
+	b, err := json.Marshal(acc)
+	assert.Nil(t, err)
+	assert.Equal(t, `{"address":"AJh4YxusYvG3SPzatzv1yWaKVn4iYJ6xua","label":"","isDefault":false,"lock":false,"key":null,"contract":null,"extra":null}`, string(b))
+
+	script := helper.HexToBytes("00c1046e616d656763d26113bac4208254d98a3eebaee66230ead7b9")
+	acc, err = NewAccountFromContractParameters(script, []Parameter{{Name: "owner", Type: sc.Hash160}, {Name: "amount", Type: sc.Integer}})
+	assert.Nil(t, err)
+	acc.Extra = map[string]interface{}{"note": "custom"}
+	b, err = json.Marshal(acc)
+	assert.Nil(t, err)
+
+	loaded := &Account{}
+	assert.Nil(t, json.Unmarshal(b, loaded))
+	assert.Equal(t, acc, loaded)
+	assert.Equal(t, sc.Integer, loaded.Contract.Parameters[1].Type)
+	s, err := loaded.VerificationScript()
+	assert.Nil(t, err)
+	assert.Equal(t, script, s)
+
+	// null label and empty key are kept as they were
+	data := `{"address":"AJh4YxusYvG3SPzatzv1yWaKVn4iYJ6xua","label":null,"isDefault":true,"lock":false,"key":"","contract":null,"extra":{"a":[1,2]}}`
+	assert.Nil(t, json.Unmarshal([]byte(data), loaded))
+	assert.True(t, loaded.IsWatchOnly())
+	b, err = json.Marshal(loaded)
+	assert.Nil(t, err)
+	assert.Equal(t, data, string(b))
+}
@@ -0,0 +1,255 @@
+package wallet
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/joeqian10/neo-gogogo/helper"
+	"github.com/joeqian10/neo-gogogo/sc"
+	"github.com/joeqian10/neo-gogogo/wallet/keys"
+)
+
+// NewWalletFromDb3 imports the legacy sqlite wallet of neo-gui 2.x. The master key is decrypted with
+// the password and the IV stored in the Key table, and decrypts the private keys of the Account table.
+// Each row of the Address table becomes an account with its contract in the Contract table, or a
+// watch-only account. The .db3 format has no labels, so the labels are empty.
+// The returned accounts have decrypted key pairs, call EncryptAll before Save.
+func NewWalletFromDb3(path string, password string) (*Wallet, error) {
+	f, err := openSqliteFile(path)
+	if err != nil {
+		return nil, err
+	}
+	stored, err := readDb3Keys(f)
+	if err != nil {
+		return nil, err
+	}
+	iv, encryptedMasterKey := stored["IV"], stored["MasterKey"]
+	if len(iv) != aes.BlockSize || len(encryptedMasterKey) != 32 {
+		return nil, fmt.Errorf("invalid IV or MasterKey in the wallet")
+	}
+	// the password key is sha256(sha256(password)), and the file stores its sha256 to check the password
+	passwordKey := sha256.Sum256([]byte(password))
+	passwordKey = sha256.Sum256(passwordKey[:])
+	if hash, ok := stored["PasswordHash"]; ok {
+		expected := sha256.Sum256(passwordKey[:])
+		if !bytes.Equal(hash, expected[:]) {
+			return nil, fmt.Errorf("password mismatch")
+		}
+	}
+	masterKey, err := aesCbcDecrypt(encryptedMasterKey, passwordKey[:], iv)
+	if err != nil {
+		return nil, err
+	}
+	defer clearBytes(masterKey)
+
+	pairs, err := readDb3KeyPairs(f, masterKey, iv)
+	if err != nil {
+		return nil, err
+	}
+	contracts, err := f.readTable("Contract")
+	if err != nil {
+		return nil, err
+	}
+	accounts := make(map[helper.UInt160]*Account)
+	for _, row := range contracts {
+		acc, err := newDb3ContractAccount(row, pairs)
+		if err != nil {
+			return nil, err
+		}
+		hash, _ := acc.ScriptHash()
+		accounts[hash] = acc
+	}
+
+	w := NewWallet()
+	w.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	addresses, err := f.readTable("Address")
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range addresses {
+		hash, err := db3UInt160(row["ScriptHash"])
+		if err != nil {
+			return nil, err
+		}
+		acc, ok := accounts[hash]
+		if !ok {
+			acc = &Account{Address: helper.ScriptHashToAddress(hash)}
+		}
+		delete(accounts, hash)
+		w.AddAccount(acc)
+	}
+	// contracts without an address row are kept, in the order of the Contract table
+	for _, row := range contracts {
+		hash, _ := db3UInt160(row["ScriptHash"])
+		if acc, ok := accounts[hash]; ok {
+			w.AddAccount(acc)
+		}
+	}
+	return w, nil
+}
+
+// readDb3Keys reads the Name and Value of the Key table
+func readDb3Keys(f *sqliteFile) (map[string][]byte, error) {
+	rows, err := f.readTable("Key")
+	if err != nil {
+		return nil, err
+	}
+	stored := make(map[string][]byte, len(rows))
+	for _, row := range rows {
+		name, _ := row["Name"].(string)
+		value, _ := row["Value"].([]byte)
+		stored[name] = value
+	}
+	return stored, nil
+}
+
+// readDb3KeyPairs decrypts the Account table by the PublicKeyHash, an encrypted key is
+// the uncompressed public key without the prefix followed by the private key
+func readDb3KeyPairs(f *sqliteFile, masterKey []byte, iv []byte) (map[helper.UInt160]*keys.KeyPair, error) {
+	rows, err := f.readTable("Account")
+	if err != nil {
+		return nil, err
+	}
+	pairs := make(map[helper.UInt160]*keys.KeyPair, len(rows))
+	for _, row := range rows {
+		hash, err := db3UInt160(row["PublicKeyHash"])
+		if err != nil {
+			return nil, err
+		}
+		encrypted, _ := row["PrivateKeyEncrypted"].([]byte)
+		if len(encrypted) != 96 {
+			return nil, fmt.Errorf("invalid encrypted private key of %s", hash.String())
+		}
+		decrypted, err := aesCbcDecrypt(encrypted, masterKey, iv)
+		if err != nil {
+			return nil, err
+		}
+		// the key pair keeps the private key slice, which must outlive the decrypted data
+		pair, err := keys.NewKeyPair(append([]byte{}, decrypted[64:]...))
+		clearBytes(decrypted[64:])
+		if err != nil {
+			return nil, err
+		}
+		publicKey := make([]byte, 64)
+		pair.PublicKey.X.FillBytes(publicKey[:32])
+		pair.PublicKey.Y.FillBytes(publicKey[32:])
+		if !bytes.Equal(publicKey, decrypted[:64]) {
+			return nil, fmt.Errorf("the private key of %s does not match its public key", hash.String())
+		}
+		pairs[hash] = pair
+	}
+	return pairs, nil
+}
+
+// newDb3ContractAccount creates the account of a row of the Contract table, whose RawData is the
+// parameter list and the script as var bytes, prefixed with the public key hash in the early versions
+func newDb3ContractAccount(row map[string]interface{}, pairs map[helper.UInt160]*keys.KeyPair) (*Account, error) {
+	hash, err := db3UInt160(row["ScriptHash"])
+	if err != nil {
+		return nil, err
+	}
+	publicKeyHash, err := db3UInt160(row["PublicKeyHash"])
+	if err != nil {
+		return nil, err
+	}
+	raw, _ := row["RawData"].([]byte)
+	parameterList, script, ok := readDb3Contract(raw)
+	if !ok && len(raw) > 20 {
+		parameterList, script, ok = readDb3Contract(raw[20:])
+	}
+	if !ok {
+		return nil, fmt.Errorf("invalid contract data of %s", hash.String())
+	}
+	// parameter names as neo-gui names them when it migrates a .db3 wallet to NEP-6
+	parameters := make([]Parameter, len(parameterList))
+	for i, t := range parameterList {
+		parameters[i] = Parameter{Name: fmt.Sprintf("parameter%d", i), Type: sc.ContractParameterType(t)}
+	}
+	acc, err := NewAccountFromContractParameters(script, parameters)
+	if err != nil {
+		return nil, err
+	}
+	if acc.Address != helper.ScriptHashToAddress(hash) {
+		return nil, fmt.Errorf("the contract script does not match the script hash %s", hash.String())
+	}
+	acc.KeyPair = pairs[publicKeyHash]
+	return acc, nil
+}
+
+// readDb3Contract reads two var bytes which take the whole data
+func readDb3Contract(data []byte) (parameterList []byte, script []byte, ok bool) {
+	parameterList, rest, ok := readVarBytes(data)
+	if !ok {
+		return nil, nil, false
+	}
+	script, rest, ok = readVarBytes(rest)
+	if !ok || len(rest) != 0 || len(script) == 0 {
+		return nil, nil, false
+	}
+	return parameterList, script, true
+}
+
+func readVarBytes(data []byte) (value []byte, rest []byte, ok bool) {
+	if len(data) == 0 {
+		return nil, nil, false
+	}
+	var n uint64
+	switch data[0] {
+	case 0xfd:
+		if len(data) < 3 {
+			return nil, nil, false
+		}
+		n, data = uint64(binary.LittleEndian.Uint16(data[1:])), data[3:]
+	case 0xfe:
+		if len(data) < 5 {
+			return nil, nil, false
+		}
+		n, data = uint64(binary.LittleEndian.Uint32(data[1:])), data[5:]
+	case 0xff:
+		if len(data) < 9 {
+			return nil, nil, false
+		}
+		n, data = binary.LittleEndian.Uint64(data[1:]), data[9:]
+	default:
+		n, data = uint64(data[0]), data[1:]
+	}
+	if n > uint64(len(data)) {
+		return nil, nil, false
+	}
+	return data[:n], data[n:], true
+}
+
+// db3UInt160 reads a script hash column, which is stored in little endian
+func db3UInt160(value interface{}) (helper.UInt160, error) {
+	b, ok := value.([]byte)
+	if !ok || len(b) != 20 {
+		return helper.UInt160{}, fmt.Errorf("invalid script hash in the wallet")
+	}
+	return helper.UInt160FromBytes(b)
+}
+
+// aesCbcDecrypt decrypts AES-256-CBC without padding, as neo-gui encrypts the wallet keys
+func aesCbcDecrypt(data []byte, key []byte, iv []byte) ([]byte, error) {
+	if len(data)%aes.BlockSize != 0 {
+		return nil, fmt.Errorf("invalid length of the encrypted data")
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]byte, len(data))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(result, data)
+	return result, nil
+}
+
+func clearBytes(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
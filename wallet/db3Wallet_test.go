@@ -0,0 +1,47 @@
+package wallet
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/joeqian10/neo-gogogo/helper"
+	"github.com/joeqian10/neo-gogogo/sc"
+	"github.com/joeqian10/neo-gogogo/wallet/keys"
+)
+
+// test.db3 is built by makeTestDb3.go in the format of UserWallet of neo 2.x
+func TestNewWalletFromDb3(t *testing.T) {
+	w, err := NewWalletFromDb3("test.db3", "neo-gogogo")
+	assert.Nil(t, err)
+	assert.Equal(t, "test", w.Name)
+	assert.Equal(t, 102, len(w.Accounts))
+
+	// the signature contract of the first key
+	acc := w.Accounts[0]
+	assert.Equal(t, keys.KeyCases[0].Address, acc.Address)
+	assert.Equal(t, keys.KeyCases[0].PrivateKey, helper.BytesToHex(acc.KeyPair.PrivateKey))
+	assert.Equal(t, []Parameter{{Name: "parameter0", Type: sc.Signature}}, acc.Contract.Parameters)
+	assert.Equal(t, "", acc.Label)
+
+	// the 1 of 2 multi-signature contract in the early format, with the second key
+	acc = w.Accounts[1]
+	assert.Equal(t, "AcQ8e8N8Hm5tkh43bsZKP4LzsafJgX8DNY", acc.Address)
+	assert.Equal(t, keys.KeyCases[1].PrivateKey, helper.BytesToHex(acc.KeyPair.PrivateKey))
+	vs, err := keys.ParseVerificationScript(helper.HexToBytes(acc.Contract.Script))
+	assert.Nil(t, err)
+	assert.Equal(t, keys.MultiSigScript, vs.Kind)
+
+	for _, acc := range w.Accounts[2:] {
+		assert.True(t, acc.IsWatchOnly())
+		assert.Nil(t, acc.Contract)
+	}
+
+	err = w.EncryptAllWithOptions("neo-gogogo", CryptOptions{})
+	assert.Nil(t, err)
+}
+
+func TestNewWalletFromDb3_WrongPassword(t *testing.T) {
+	_, err := NewWalletFromDb3("test.db3", "wrong")
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,159 @@
+package wallet
+
+import (
+	"fmt"
+
+	"github.com/joeqian10/neo-gogogo/rpc"
+	"github.com/joeqian10/neo-gogogo/wallet/keys"
+)
+
+// DefaultGapLimit is the number of consecutive unused addresses after which the discovery stops, as BIP44 suggests
+const DefaultGapLimit = 20
+
+// hdAccount derives the external chain m/44'/888'/account'/0 of a deterministic wallet
+type hdAccount struct {
+	account  uint32
+	chainKey *keys.ExtendedKey
+	next     uint32
+}
+
+// NewWalletFromMnemonic creates a deterministic wallet whose accounts are derived from the BIP39 mnemonic
+// along m/44'/888'/account'/0/index
+func NewWalletFromMnemonic(mnemonic string, passphrase string, account uint32) (*Wallet, error) {
+	w := NewWallet()
+	if err := w.SetMnemonic(mnemonic, passphrase, account); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// SetMnemonic makes the wallet deterministic, the mnemonic is kept in memory only and
+// should be set again after the wallet is loaded from file
+func (w *Wallet) SetMnemonic(mnemonic string, passphrase string, account uint32) error {
+	seed, err := keys.NewSeedFromMnemonic(mnemonic, passphrase)
+	if err != nil {
+		return err
+	}
+	master, err := keys.NewMasterKey(seed)
+	if err != nil {
+		return err
+	}
+	chainKey, err := master.Derive(fmt.Sprintf("m/44'/%d'/%d'/0", keys.NeoCoinType, account))
+	if err != nil {
+		return err
+	}
+	w.hd = &hdAccount{account: account, chainKey: chainKey}
+	return nil
+}
+
+// IsDeterministic reports whether the accounts of the wallet can be derived from a mnemonic
+func (w *Wallet) IsDeterministic() bool {
+	return w.hd != nil
+}
+
+// DeriveAccount derives the account of the index without adding it to the wallet
+func (w *Wallet) DeriveAccount(index uint32) (*Account, error) {
+	if w.hd == nil {
+		return nil, fmt.Errorf("the wallet is not deterministic")
+	}
+	key, err := w.hd.chainKey.Child(index)
+	if err != nil {
+		return nil, err
+	}
+	pair, err := key.KeyPair()
+	if err != nil {
+		return nil, err
+	}
+	return NewAccountFromKeyPair(pair), nil
+}
+
+// addNextDerivedAccount adds the account of the next index which is not in the wallet yet
+func (w *Wallet) addNextDerivedAccount() (*Account, error) {
+	for {
+		index := w.hd.next
+		acc, err := w.DeriveAccount(index)
+		if err != nil {
+			return nil, err
+		}
+		w.hd.next = index + 1
+		if w.getAccount(acc.Address) == nil {
+			w.AddAccount(acc)
+			return acc, nil
+		}
+	}
+}
+
+// DiscoverAccounts scans the derived addresses from index 0 and adds the used ones to the wallet, until gapLimit
+// consecutive addresses are unused. An address is used if it has an asset balance, a NEP-5 transfer or
+// claimable GAS of spent NEO. The node keeps no state of an address whose assets were all spent and whose
+// GAS was claimed, such an address without NEP-5 transfers is seen as unused, so the gap limit should be
+// larger than the runs of those addresses. RPC errors other than a missing plugin are returned.
+// It returns the number of used addresses, AddNewAccount continues after the last one.
+func (w *Wallet) DiscoverAccounts(client rpc.IRpcClient, gapLimit int) (int, error) {
+	if w.hd == nil {
+		return 0, fmt.Errorf("the wallet is not deterministic")
+	}
+	if gapLimit <= 0 {
+		gapLimit = DefaultGapLimit
+	}
+	found := 0
+	for index, gap := uint32(0), 0; gap < gapLimit; index++ {
+		acc, err := w.DeriveAccount(index)
+		if err != nil {
+			return found, err
+		}
+		used, err := isAddressUsed(client, acc.Address)
+		if err != nil {
+			return found, err
+		}
+		if !used {
+			gap++
+			continue
+		}
+		gap = 0
+		found++
+		if w.getAccount(acc.Address) == nil {
+			w.AddAccount(acc)
+		}
+		if w.hd.next <= index {
+			w.hd.next = index + 1
+		}
+	}
+	return found, nil
+}
+
+// isAddressUsed checks the account state, all the NEP-5 transfers and the claimable GAS of the address,
+// the NEP-5 transfers and the claimable GAS are skipped if the node does not have the plugins
+func isAddressUsed(client rpc.IRpcClient, address string) (bool, error) {
+	state := client.GetAccountState(address)
+	if state.HasError() {
+		return false, fmt.Errorf(state.GetErrorInfo())
+	}
+	if len(state.Result.Balances) > 0 {
+		return true, nil
+	}
+	// from the start time 0, the node returns only the last 7 days by default
+	transfers := client.GetNep5TransfersFrom(address, 0)
+	if transfers.HasError() && !transfers.IsMethodNotFound() {
+		return false, fmt.Errorf(transfers.GetErrorInfo())
+	}
+	if len(transfers.Result.Sent) > 0 || len(transfers.Result.Received) > 0 {
+		return true, nil
+	}
+	// NEO which has been spent leaves claimable GAS until it is claimed
+	claimable := client.GetClaimable(address)
+	if claimable.HasError() && !claimable.IsMethodNotFound() {
+		return false, fmt.Errorf(claimable.GetErrorInfo())
+	}
+	return len(claimable.Result.Claimables) > 0, nil
+}
+
+// getAccount returns the account of the address in the wallet, or nil
+func (w *Wallet) getAccount(address string) *Account {
+	for _, acc := range w.Accounts {
+		if acc.Address == address {
+			return acc
+		}
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package wallet
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/joeqian10/neo-gogogo/rpc"
+	"github.com/joeqian10/neo-gogogo/rpc/models"
+	"github.com/joeqian10/neo-gogogo/wallet/keys"
+)
+
+const testMnemonic = "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+
+func TestNewWalletFromMnemonic(t *testing.T) {
+	w, err := NewWalletFromMnemonic(testMnemonic, "", 0)
+	assert.Nil(t, err)
+	assert.True(t, w.IsDeterministic())
+
+	seed, _ := keys.NewSeedFromMnemonic(testMnemonic, "")
+	master, _ := keys.NewMasterKey(seed)
+	key, _ := master.Derive(keys.NeoDerivationPath(0, 0, 1))
+	pair, _ := key.KeyPair()
+	acc, err := w.DeriveAccount(1)
+	assert.Nil(t, err)
+	assert.Equal(t, pair.PublicKey.Address(), acc.Address)
+
+	assert.Nil(t, w.AddNewAccount())
+	assert.Nil(t, w.AddNewAccount())
+	assert.Equal(t, 2, len(w.Accounts))
+	assert.Equal(t, acc.Address, w.Accounts[1].Address)
+
+	// the same mnemonic derives the same accounts
+	w2, _ := NewWalletFromMnemonic(testMnemonic, "", 0)
+	w2.AddAccount(w.Accounts[0])
+	assert.Nil(t, w2.AddNewAccount())
+	assert.Equal(t, acc.Address, w2.Accounts[1].Address)
+
+	_, err = NewWalletFromMnemonic("abandon about", "", 0)
+	assert.NotNil(t, err)
+	_, err = NewWallet().DeriveAccount(0)
+	assert.NotNil(t, err)
+}
+
+func TestWallet_DiscoverAccounts(t *testing.T) {
+	w, _ := NewWalletFromMnemonic(testMnemonic, "", 0)
+	acc0, _ := w.DeriveAccount(0)
+	acc2, _ := w.DeriveAccount(2)
+
+	clientMock := new(rpc.RpcClientMock)
+	clientMock.On("GetAccountState", acc0.Address).Return(rpc.GetAccountStateResponse{
+		Result: models.AccountState{Balances: []models.AccountStateBalance{{Asset: "0x01", Value: "1"}}},
+	})
+	clientMock.On("GetAccountState", mock.Anything).Return(rpc.GetAccountStateResponse{})
+	clientMock.On("GetNep5TransfersFrom", acc2.Address, uint32(0)).Return(rpc.GetNep5TransfersResponse{
+		Result: models.RpcNep5Transfers{Received: []models.Nep5Transfer{{Amount: "1"}}},
+	})
+	clientMock.On("GetNep5TransfersFrom", mock.Anything, uint32(0)).Return(rpc.GetNep5TransfersResponse{
+		ErrorResponse: rpc.ErrorResponse{Error: rpc.RpcError{Code: -32601, Message: "Method not found"}},
+	})
+	clientMock.On("GetClaimable", mock.Anything).Return(rpc.GetClaimableResponse{})
+
+	found, err := w.DiscoverAccounts(clientMock, 3)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, found)
+	assert.Equal(t, 2, len(w.Accounts))
+	assert.Equal(t, acc2.Address, w.Accounts[1].Address)
+	// 2 used addresses and 3 unused ones after them
+	clientMock.AssertNumberOfCalls(t, "GetAccountState", 6)
+
+	acc3, _ := w.DeriveAccount(3)
+	assert.Nil(t, w.AddNewAccount())
+	assert.Equal(t, acc3.Address, w.Accounts[2].Address)
+}
+
+func TestWallet_DiscoverAccounts_Nep5(t *testing.T) {
+	w, _ := NewWalletFromMnemonic(testMnemonic, "", 0)
+	acc0, _ := w.DeriveAccount(0)
+
+	// the address only ever held NEP-5 tokens, received more than 7 days ago and all sent
+	clientMock := new(rpc.RpcClientMock)
+	clientMock.On("GetAccountState", mock.Anything).Return(rpc.GetAccountStateResponse{})
+	clientMock.On("GetNep5TransfersFrom", acc0.Address, uint32(0)).Return(rpc.GetNep5TransfersResponse{
+		Result: models.RpcNep5Transfers{
+			Sent:     []models.Nep5Transfer{{Timestamp: 1555651816, Amount: "1"}},
+			Received: []models.Nep5Transfer{{Timestamp: 1555651800, Amount: "1"}},
+		},
+	})
+	clientMock.On("GetNep5TransfersFrom", mock.Anything, uint32(0)).Return(rpc.GetNep5TransfersResponse{})
+	clientMock.On("GetClaimable", mock.Anything).Return(rpc.GetClaimableResponse{})
+	found, err := w.DiscoverAccounts(clientMock, 2)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, found)
+	assert.Equal(t, acc0.Address, w.Accounts[0].Address)
+	clientMock.AssertNotCalled(t, "GetNep5Transfers", mock.Anything)
+}
+
+func TestWallet_DiscoverAccounts_Claimable(t *testing.T) {
+	w, _ := NewWalletFromMnemonic(testMnemonic, "", 0)
+	acc0, _ := w.DeriveAccount(0)
+
+	// the NEO of the address has been spent, only its claimable GAS is left
+	clientMock := new(rpc.RpcClientMock)
+	clientMock.On("GetAccountState", mock.Anything).Return(rpc.GetAccountStateResponse{})
+	clientMock.On("GetNep5TransfersFrom", mock.Anything, uint32(0)).Return(rpc.GetNep5TransfersResponse{})
+	clientMock.On("GetClaimable", acc0.Address).Return(rpc.GetClaimableResponse{
+		Result: models.RpcClaimable{Claimables: []models.Claimable{{TxId: "0x01", Value: 1}}},
+	})
+	clientMock.On("GetClaimable", mock.Anything).Return(rpc.GetClaimableResponse{
+		ErrorResponse: rpc.ErrorResponse{Error: rpc.RpcError{Code: -32601, Message: "Method not found"}},
+	})
+	found, err := w.DiscoverAccounts(clientMock, 2)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, found)
+}
+
+func TestWallet_DiscoverAccounts_Error(t *testing.T) {
+	w, _ := NewWalletFromMnemonic(testMnemonic, "", 0)
+	clientMock := new(rpc.RpcClientMock)
+	clientMock.On("GetAccountState", mock.Anything).Return(rpc.GetAccountStateResponse{})
+	clientMock.On("GetNep5TransfersFrom", mock.Anything, uint32(0)).Return(rpc.GetNep5TransfersResponse{
+		ErrorResponse: rpc.ErrorResponse{Error: rpc.RpcError{Code: -100, Message: "Unknown address"}},
+	})
+	found, err := w.DiscoverAccounts(clientMock, 2)
+	assert.EqualError(t, err, "Unknown address")
+	assert.Equal(t, 0, found)
+	assert.Equal(t, 0, len(w.Accounts))
+}
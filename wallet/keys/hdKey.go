@@ -0,0 +1,148 @@
+package keys
+
+import (
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+const (
+	// HardenedKeyStart is the index of the first hardened child key
+	HardenedKeyStart uint32 = 0x80000000
+	// NeoCoinType is the SLIP-44 coin type of NEO
+	NeoCoinType uint32 = 888
+)
+
+// the hmac key of the master key of secp256r1 in SLIP-10
+var nist256p1Seed = []byte("Nist256p1 seed")
+
+// ExtendedKey is a BIP32 private key with its chain code, derived on secp256r1 as SLIP-10 specifies
+type ExtendedKey struct {
+	PrivateKey []byte
+	ChainCode  []byte
+	Depth      byte
+	Index      uint32
+}
+
+// NewMasterKey creates the master key from the seed, e.g. the seed of NewSeedFromMnemonic
+func NewMasterKey(seed []byte) (*ExtendedKey, error) {
+	if len(seed) < 16 || len(seed) > 64 {
+		return nil, fmt.Errorf("invalid seed length %d", len(seed))
+	}
+	mac := hmac.New(sha512.New, nist256p1Seed)
+	mac.Write(seed)
+	i := mac.Sum(nil)
+	n := elliptic.P256().Params().N
+	for {
+		k := new(big.Int).SetBytes(i[:32])
+		if k.Sign() != 0 && k.Cmp(n) < 0 {
+			return &ExtendedKey{PrivateKey: i[:32], ChainCode: i[32:]}, nil
+		}
+		mac = hmac.New(sha512.New, nist256p1Seed)
+		mac.Write(i)
+		i = mac.Sum(nil)
+	}
+}
+
+// Child derives the child key of the index, indexes from HardenedKeyStart are hardened
+func (k *ExtendedKey) Child(index uint32) (*ExtendedKey, error) {
+	if k.Depth == 0xff {
+		return nil, fmt.Errorf("max depth of the extended key exceeded")
+	}
+	data := make([]byte, 37)
+	if index >= HardenedKeyStart {
+		copy(data[1:33], k.PrivateKey)
+	} else {
+		pair, err := NewKeyPair(k.PrivateKey)
+		if err != nil {
+			return nil, err
+		}
+		copy(data, pair.PublicKey.EncodeCompression())
+	}
+	binary.BigEndian.PutUint32(data[33:], index)
+
+	n := elliptic.P256().Params().N
+	parent := new(big.Int).SetBytes(k.PrivateKey)
+	for {
+		mac := hmac.New(sha512.New, k.ChainCode)
+		mac.Write(data)
+		i := mac.Sum(nil)
+		il := new(big.Int).SetBytes(i[:32])
+		if il.Cmp(n) < 0 {
+			child := il.Add(il, parent)
+			child.Mod(child, n)
+			if child.Sign() != 0 {
+				privateKey := make([]byte, 32)
+				child.FillBytes(privateKey)
+				return &ExtendedKey{PrivateKey: privateKey, ChainCode: i[32:], Depth: k.Depth + 1, Index: index}, nil
+			}
+		}
+		// invalid key, retry with 0x01 || IR || index
+		data[0] = 1
+		copy(data[1:33], i[32:])
+	}
+}
+
+// Derive derives the key of the path relative to this key, e.g. "m/44'/888'/0'/0/0"
+func (k *ExtendedKey) Derive(path string) (*ExtendedKey, error) {
+	indexes, err := ParseDerivationPath(path)
+	if err != nil {
+		return nil, err
+	}
+	key := k
+	for _, index := range indexes {
+		if key, err = key.Child(index); err != nil {
+			return nil, err
+		}
+	}
+	return key, nil
+}
+
+// Clear overwrites the private key and the chain code with zeros
+func (k *ExtendedKey) Clear() {
+	for i := range k.PrivateKey {
+		k.PrivateKey[i] = 0
+	}
+	for i := range k.ChainCode {
+		k.ChainCode[i] = 0
+	}
+}
+
+// KeyPair returns the key pair of the extended key
+func (k *ExtendedKey) KeyPair() (*KeyPair, error) {
+	return NewKeyPair(k.PrivateKey)
+}
+
+// ParseDerivationPath parses a BIP32 path like "m/44'/888'/0'/0/0", hardened indexes end with ' or h
+func ParseDerivationPath(path string) ([]uint32, error) {
+	parts := strings.Split(strings.TrimSpace(path), "/")
+	if parts[0] == "m" {
+		parts = parts[1:]
+	}
+	indexes := make([]uint32, 0, len(parts))
+	for _, p := range parts {
+		hardened := strings.HasSuffix(p, "'") || strings.HasSuffix(p, "h") || strings.HasSuffix(p, "H")
+		if hardened {
+			p = p[:len(p)-1]
+		}
+		i, err := strconv.ParseUint(p, 10, 32)
+		if err != nil || uint32(i) >= HardenedKeyStart {
+			return nil, fmt.Errorf("invalid derivation path %s", path)
+		}
+		if hardened {
+			i += uint64(HardenedKeyStart)
+		}
+		indexes = append(indexes, uint32(i))
+	}
+	return indexes, nil
+}
+
+// NeoDerivationPath returns the BIP44 path m/44'/888'/account'/change/index
+func NeoDerivationPath(account uint32, change uint32, index uint32) string {
+	return fmt.Sprintf("m/44'/%d'/%d'/%d/%d", NeoCoinType, account, change, index)
+}
@@ -0,0 +1,45 @@
+package keys
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/joeqian10/neo-gogogo/helper"
+)
+
+// test vector 1 of SLIP-10 for nist256p1
+func TestNewMasterKey(t *testing.T) {
+	master, err := NewMasterKey(helper.HexToBytes("000102030405060708090a0b0c0d0e0f"))
+	assert.Nil(t, err)
+	assert.Equal(t, "beeb672fe4621673f722f38529c07392fecaa61015c80c34f29ce8b41b3cb6ea", helper.BytesToHex(master.ChainCode))
+	assert.Equal(t, "612091aaa12e22dd2abef664f8a01a82cae99ad7441b7ef8110424915c268bc2", helper.BytesToHex(master.PrivateKey))
+	pair, _ := master.KeyPair()
+	assert.Equal(t, "0266874dc6ade47b3ecd096745ca09bcd29638dd52c2c12117b11ed3e458cfa9e8", pair.PublicKey.String())
+
+	child, err := master.Derive("m/0'")
+	assert.Nil(t, err)
+	assert.Equal(t, "3460cea53e6a6bb5fb391eeef3237ffd8724bf0a40e94943c98b83825342ee11", helper.BytesToHex(child.ChainCode))
+	assert.Equal(t, "6939694369114c67917a182c59ddb8cafc3004e63ca5d3b84403ba8613debc0c", helper.BytesToHex(child.PrivateKey))
+
+	child, err = master.Derive("m/0'/1")
+	assert.Nil(t, err)
+	assert.Equal(t, "4187afff1aafa8445010097fb99d23aee9f599450c7bd140b6826ac22ba21d0c", helper.BytesToHex(child.ChainCode))
+	assert.Equal(t, "284e9d38d07d21e4e281b645089a94f4cf5a5a81369acf151a1c3a57f18b2129", helper.BytesToHex(child.PrivateKey))
+
+	child, err = master.Derive("m/0'/1/2'/2/1000000000")
+	assert.Nil(t, err)
+	assert.Equal(t, byte(5), child.Depth)
+	assert.Equal(t, uint32(1000000000), child.Index)
+}
+
+func TestParseDerivationPath(t *testing.T) {
+	indexes, err := ParseDerivationPath(NeoDerivationPath(1, 0, 5))
+	assert.Nil(t, err)
+	assert.Equal(t, []uint32{HardenedKeyStart + 44, HardenedKeyStart + 888, HardenedKeyStart + 1, 0, 5}, indexes)
+
+	for _, path := range []string{"", "m/a", "m/-1", "m/2147483648", "m/1''"} {
+		_, err = ParseDerivationPath(path)
+		assert.NotNil(t, err, path)
+	}
+}
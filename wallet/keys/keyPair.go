@@ -98,31 +98,83 @@ func (p *KeyPair) ExportNep2(password string) (string, error) {
 	return nep2, nil
 }
 
+// Clear overwrites the private key with zeros, the key pair cannot sign after it
+func (p *KeyPair) Clear() {
+	for i := range p.PrivateKey {
+		p.PrivateKey[i] = 0
+	}
+}
+
 // String implements the Stringer interface.
 func (p *KeyPair) String() string {
 	return helper.BytesToHex(p.PrivateKey)
 }
 
-// sign message with KeyPair
+// SignOptions configures how KeyPair.SignWithOptions creates a signature
+type SignOptions struct {
+	// Random uses a random nonce from crypto/rand instead of the deterministic nonce of RFC 6979
+	Random bool
+	// LowS replaces s with n - s when s is greater than n / 2
+	LowS bool
+}
+
+// Sign signs the sha256 of the message with the deterministic nonce of RFC 6979,
+// the same message always gets the same signature
 func (p *KeyPair) Sign(message []byte) ([]byte, error) {
+	return p.SignWithOptions(message, SignOptions{})
+}
+
+// SignWithOptions signs the sha256 of the message, returns r and s of 32 bytes each
+func (p *KeyPair) SignWithOptions(message []byte, options SignOptions) ([]byte, error) {
 	privateKey := p.ToEcdsa()
 	hash := sha256.Sum256(message)
-	r, s, err := ecdsa.Sign(rand.Reader, privateKey, hash[:])
-
-	if err != nil {
-		return nil, err
+	var r, s *big.Int
+	var err error
+	if options.Random {
+		r, s, err = ecdsa.Sign(rand.Reader, privateKey, hash[:])
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		r, s = signDeterministic(privateKey, hash[:])
 	}
 
 	params := privateKey.Curve.Params()
+	if options.LowS && s.Cmp(new(big.Int).Rsh(params.N, 1)) > 0 {
+		s = new(big.Int).Sub(params.N, s)
+	}
 	curveOrderByteSize := params.P.BitLen() / 8
-	rBytes, sBytes := r.Bytes(), s.Bytes()
 	signature := make([]byte, curveOrderByteSize*2)
-	copy(signature[curveOrderByteSize-len(rBytes):], rBytes)
-	copy(signature[curveOrderByteSize*2-len(sBytes):], sBytes)
+	r.FillBytes(signature[:curveOrderByteSize])
+	s.FillBytes(signature[curveOrderByteSize:])
 
 	return signature, nil
 }
 
+// signDeterministic computes r and s with the nonces of RFC 6979. Like crypto/ecdsa of go 1.18, it uses
+// math/big, which is not constant-time, so the signer should not run where an attacker can time it.
+func signDeterministic(privateKey *ecdsa.PrivateKey, hash []byte) (r, s *big.Int) {
+	n := privateKey.Curve.Params().N
+	e := new(big.Int).SetBytes(hash)
+	nonces := newRfc6979(n, privateKey.D, hash)
+	for {
+		k := nonces.next()
+		x, _ := privateKey.Curve.ScalarBaseMult(k.Bytes())
+		r = new(big.Int).Mod(x, n)
+		if r.Sign() == 0 {
+			continue
+		}
+		// s = k^-1 * (e + r * d) mod n
+		s = new(big.Int).Mul(r, privateKey.D)
+		s.Add(s, e)
+		s.Mul(s, new(big.Int).ModInverse(k, n))
+		s.Mod(s, n)
+		if s.Sign() != 0 {
+			return r, s
+		}
+	}
+}
+
 // Verify returns true if the signature is valid and corresponds
 // to the hash and public key
 func VerifySignature(message []byte, signature []byte, p *PublicKey) bool {
@@ -1,9 +1,13 @@
 package keys
 
 import (
+	"crypto/elliptic"
+	"math/big"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/joeqian10/neo-gogogo/helper"
 )
 
 func TestExportWIF(t *testing.T) {
@@ -64,3 +68,28 @@ func TestWrongPubKey(t *testing.T) {
 	actual := VerifySignature(sample, signedData, wrongPubKey)
 	assert.Equal(t, false, actual)
 }
+
+func TestKeyPair_Sign_Rfc6979(t *testing.T) {
+	// test vectors of RFC 6979 A.2.5, P-256 with SHA-256
+	keyPair, _ := NewKeyPair(helper.HexToBytes("c9afa9d845ba75166b5c215767b1d6934e50c3db36e89b127b8a622b120f6721"))
+	signature, err := keyPair.Sign([]byte("sample"))
+	assert.Nil(t, err)
+	assert.Equal(t, "efd48b2aacb6a8fd1140dd9cd45e81d69d2c877b56aaf991c34d0ea84eaf3716"+
+		"f7cb1c942d657c41d436c7a1b6e29f65f3e900dbb9aff4064dc4ab2f843acda8", helper.BytesToHex(signature))
+	signature, _ = keyPair.Sign([]byte("test"))
+	assert.Equal(t, "f1abb023518351cd71d881567b1ea663ed3efcf6c5132b354f28d3b0b7d38367"+
+		"019f4113742a2b14bd25926b49c649155f267e60d3814b4c0cc84250e46f0083", helper.BytesToHex(signature))
+
+	// low s
+	signature, _ = keyPair.SignWithOptions([]byte("sample"), SignOptions{LowS: true})
+	assert.Equal(t, "efd48b2aacb6a8fd1140dd9cd45e81d69d2c877b56aaf991c34d0ea84eaf3716", helper.BytesToHex(signature[:32]))
+	s := new(big.Int).SetBytes(signature[32:])
+	assert.True(t, s.Cmp(new(big.Int).Rsh(elliptic.P256().Params().N, 1)) <= 0)
+	assert.True(t, VerifySignature([]byte("sample"), signature, keyPair.PublicKey))
+
+	// random
+	s1, _ := keyPair.SignWithOptions([]byte("sample"), SignOptions{Random: true})
+	s2, _ := keyPair.SignWithOptions([]byte("sample"), SignOptions{Random: true})
+	assert.NotEqual(t, s1, s2)
+	assert.True(t, VerifySignature([]byte("sample"), s1, keyPair.PublicKey))
+}
@@ -0,0 +1,64 @@
+package keys
+
+import (
+	"crypto/rand"
+
+	"github.com/joeqian10/neo-gogogo/helper"
+	"github.com/joeqian10/neo-gogogo/helper/io"
+)
+
+// SignedMessage is the result of signMessage of the NeoLine and O3 dapi, hex strings without 0x
+type SignedMessage struct {
+	PublicKey string `json:"publicKey"`
+	// Salt is the random hex string prepended to the message
+	Salt    string `json:"salt"`
+	Message string `json:"message"`
+	// Signature is r and s of the signature of the envelope
+	Signature string `json:"data"`
+}
+
+// SignMessage signs the text with a random salt in the message envelope of the Neo wallets, to prove
+// the ownership of the address of the signer
+func SignMessage(message string, signer Signer) (*SignedMessage, error) {
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	return signMessageWithSalt(message, helper.BytesToHex(salt), signer)
+}
+
+func signMessageWithSalt(message string, salt string, signer Signer) (*SignedMessage, error) {
+	signature, err := signer.Sign(messageEnvelope(salt + message))
+	if err != nil {
+		return nil, err
+	}
+	return &SignedMessage{
+		PublicKey: helper.BytesToHex(signer.Public().EncodeCompression()),
+		Salt:      salt,
+		Message:   message,
+		Signature: helper.BytesToHex(signature),
+	}, nil
+}
+
+// VerifyMessage reports whether the signature of the message is valid and the public key is of the address
+func VerifyMessage(m *SignedMessage, address string) bool {
+	publicKey, err := NewPublicKeyFromString(m.PublicKey)
+	if err != nil || publicKey.Address() != address {
+		return false
+	}
+	signature := helper.HexToBytes(m.Signature)
+	if len(signature) != 64 {
+		return false
+	}
+	return VerifySignature(messageEnvelope(m.Salt+m.Message), signature, publicKey)
+}
+
+// messageEnvelope wraps the text as the wallets do, in an invalid transaction which cannot be relayed:
+// 010001f0 + var bytes of the text + 0000
+func messageEnvelope(text string) []byte {
+	bbw := io.NewBufBinaryWriter()
+	bbw.WriteLE(helper.HexToBytes("010001f0"))
+	bbw.WriteVarString(text)
+	bbw.WriteLE([]byte{0x00, 0x00})
+	return bbw.Bytes()
+}
@@ -0,0 +1,85 @@
+package keys
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/sha256"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/joeqian10/neo-gogogo/helper"
+)
+
+func TestMessageEnvelope(t *testing.T) {
+	// "salt" + "Hello" is 9 bytes
+	assert.Equal(t, "010001f00973616c7448656c6c6f0000", helper.BytesToHex(messageEnvelope("saltHello")))
+}
+
+func TestVerifyMessage_KnownAnswer(t *testing.T) {
+	// signed by KeyCases[0] with the deterministic nonce, the salt is as long as the one of the wallets
+	m := &SignedMessage{
+		PublicKey: "03b7a7f933199f28cc1c48d22a21c78ac3992cf7fceb038a9c670fe55444426619",
+		Salt:      "058b9e03e7154e4db1e489c1256b7347",
+		Message:   "Hello World",
+		Signature: "9d2927078ccc5bf48cd28b43f2143139991e8907918c51f7b31b7f9d3bccb116" +
+			"2524178f20f92eeba0af88d3e74ea3f815de8d6de80e5b486c64a9b8bdfb99e7",
+	}
+	assert.True(t, VerifyMessage(m, KeyCases[0].Address))
+
+	// the envelope written by hand, 0x2b is the length of the salt and the message, checked with crypto/ecdsa
+	envelope := helper.HexToBytes("010001f02b" +
+		"3035386239653033653731353465346462316534383963313235366237333437" + "48656c6c6f20576f726c64" + "0000")
+	assert.Equal(t, envelope, messageEnvelope(m.Salt+m.Message))
+	publicKey, err := NewPublicKeyFromString(m.PublicKey)
+	assert.Nil(t, err)
+	hash := sha256.Sum256(envelope)
+	signature := helper.HexToBytes(m.Signature)
+	r, s := new(big.Int).SetBytes(signature[:32]), new(big.Int).SetBytes(signature[32:])
+	assert.True(t, ecdsa.Verify(&ecdsa.PublicKey{Curve: elliptic.P256(), X: publicKey.X, Y: publicKey.Y}, hash[:], r, s))
+
+	pair, err := NewKeyPairFromWIF(KeyCases[0].Wif)
+	assert.Nil(t, err)
+	signed, err := signMessageWithSalt(m.Message, m.Salt, NewKeyPairSigner(pair, SignOptions{}))
+	assert.Nil(t, err)
+	assert.Equal(t, m, signed)
+}
+
+func TestSignMessage(t *testing.T) {
+	pair, err := NewKeyPairFromWIF(KeyCases[0].Wif)
+	assert.Nil(t, err)
+
+	m, err := SignMessage("Hello World", pair)
+	assert.Nil(t, err)
+	assert.Equal(t, KeyCases[0].PublicKey, m.PublicKey)
+	assert.Equal(t, 32, len(m.Salt))
+	assert.Equal(t, "Hello World", m.Message)
+	assert.True(t, VerifyMessage(m, KeyCases[0].Address))
+	assert.False(t, VerifyMessage(m, KeyCases[1].Address))
+
+	// the salt is random
+	m2, err := SignMessage("Hello World", pair)
+	assert.Nil(t, err)
+	assert.NotEqual(t, m.Salt, m2.Salt)
+
+	tampered := *m
+	tampered.Message = "Hello World!"
+	assert.False(t, VerifyMessage(&tampered, KeyCases[0].Address))
+	tampered = *m
+	tampered.Salt = m2.Salt
+	assert.False(t, VerifyMessage(&tampered, KeyCases[0].Address))
+	tampered = *m
+	tampered.Signature = m.Signature[:64]
+	assert.False(t, VerifyMessage(&tampered, KeyCases[0].Address))
+}
+
+func TestSignMessage_Signer(t *testing.T) {
+	pair, err := NewKeyPairFromWIF(KeyCases[1].Wif)
+	assert.Nil(t, err)
+
+	m, err := signMessageWithSalt("密码", "0123456789abcdef0123456789abcdef", NewKeyPairSigner(pair, SignOptions{Random: true}))
+	assert.Nil(t, err)
+	assert.Equal(t, "0123456789abcdef0123456789abcdef", m.Salt)
+	assert.True(t, VerifyMessage(m, KeyCases[1].Address))
+}
@@ -0,0 +1,101 @@
+package keys
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"golang.org/x/crypto/pbkdf2"
+	"golang.org/x/text/unicode/norm"
+)
+
+var bip39WordIndex = func() map[string]int {
+	m := make(map[string]int, len(bip39EnglishWords))
+	for i, w := range bip39EnglishWords {
+		m[w] = i
+	}
+	return m
+}()
+
+// NewMnemonic generates a BIP39 mnemonic from random entropy of the bit size,
+// which should be a multiple of 32 between 128 and 256
+func NewMnemonic(bitSize int) (string, error) {
+	if bitSize%32 != 0 || bitSize < 128 || bitSize > 256 {
+		return "", fmt.Errorf("invalid entropy bit size %d", bitSize)
+	}
+	entropy := make([]byte, bitSize/8)
+	if _, err := rand.Read(entropy); err != nil {
+		return "", err
+	}
+	return NewMnemonicFromEntropy(entropy)
+}
+
+// NewMnemonicFromEntropy encodes the entropy to a BIP39 mnemonic in english
+func NewMnemonicFromEntropy(entropy []byte) (string, error) {
+	l := len(entropy) * 8
+	if l%32 != 0 || l < 128 || l > 256 {
+		return "", fmt.Errorf("invalid entropy length %d", len(entropy))
+	}
+	checksumBits := uint(l / 32)
+	hash := sha256.Sum256(entropy)
+	// entropy followed by the checksum bits
+	n := new(big.Int).SetBytes(entropy)
+	n.Lsh(n, checksumBits)
+	n.Or(n, big.NewInt(int64(hash[0]>>(8-checksumBits))))
+
+	count := (l + int(checksumBits)) / 11
+	words := make([]string, count)
+	mask := big.NewInt(2047)
+	for i := count - 1; i >= 0; i-- {
+		words[i] = bip39EnglishWords[new(big.Int).And(n, mask).Int64()]
+		n.Rsh(n, 11)
+	}
+	return strings.Join(words, " "), nil
+}
+
+// MnemonicToEntropy decodes the BIP39 mnemonic and checks its checksum
+func MnemonicToEntropy(mnemonic string) ([]byte, error) {
+	words := strings.Fields(norm.NFKD.String(mnemonic))
+	count := len(words)
+	if count%3 != 0 || count < 12 || count > 24 {
+		return nil, fmt.Errorf("invalid mnemonic word count %d", count)
+	}
+	n := new(big.Int)
+	for _, w := range words {
+		i, ok := bip39WordIndex[w]
+		if !ok {
+			return nil, fmt.Errorf("invalid mnemonic word %s", w)
+		}
+		n.Lsh(n, 11)
+		n.Or(n, big.NewInt(int64(i)))
+	}
+	checksumBits := uint(count / 3)
+	checksum := byte(new(big.Int).And(n, big.NewInt(1<<checksumBits-1)).Int64())
+	n.Rsh(n, checksumBits)
+	entropy := make([]byte, (count*11-int(checksumBits))/8)
+	n.FillBytes(entropy)
+	hash := sha256.Sum256(entropy)
+	if hash[0]>>(8-checksumBits) != checksum {
+		return nil, fmt.Errorf("invalid mnemonic checksum")
+	}
+	return entropy, nil
+}
+
+// IsMnemonicValid reports whether the mnemonic has valid words and checksum
+func IsMnemonicValid(mnemonic string) bool {
+	_, err := MnemonicToEntropy(mnemonic)
+	return err == nil
+}
+
+// NewSeedFromMnemonic checks the mnemonic and derives the 64 bytes BIP39 seed with the passphrase
+func NewSeedFromMnemonic(mnemonic string, passphrase string) ([]byte, error) {
+	if _, err := MnemonicToEntropy(mnemonic); err != nil {
+		return nil, err
+	}
+	m := strings.Join(strings.Fields(norm.NFKD.String(mnemonic)), " ")
+	salt := "mnemonic" + norm.NFKD.String(passphrase)
+	return pbkdf2.Key([]byte(m), []byte(salt), 2048, 64, sha512.New), nil
+}
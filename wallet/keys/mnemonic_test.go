@@ -0,0 +1,52 @@
+package keys
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/joeqian10/neo-gogogo/helper"
+)
+
+func TestNewMnemonicFromEntropy(t *testing.T) {
+	assert.Equal(t, 2048, len(bip39EnglishWords))
+	cases := []struct{ entropy, mnemonic, seed string }{
+		{
+			"00000000000000000000000000000000",
+			"abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about",
+			"c55257c360c07c72029aebc1b53c05ed0362ada38ead3e3e9efa3708e53495531f09a6987599d18264c1e1c92f2cf141630c7a3c4ab7c81b2f001698e7463b04",
+		},
+		{
+			"7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f",
+			"legal winner thank year wave sausage worth useful legal winner thank yellow",
+			"2e8905819b8723fe2c1d161860e5ee1830318dbf49a83bd451cfb8440c28bd6fa457fe1296106559a3c80937a1c1069be3a3a5bd381ee6260e8d9739fce1f607",
+		},
+	}
+	for _, c := range cases {
+		m, err := NewMnemonicFromEntropy(helper.HexToBytes(c.entropy))
+		assert.Nil(t, err)
+		assert.Equal(t, c.mnemonic, m)
+		entropy, err := MnemonicToEntropy(m)
+		assert.Nil(t, err)
+		assert.Equal(t, c.entropy, helper.BytesToHex(entropy))
+		seed, err := NewSeedFromMnemonic(m, "TREZOR")
+		assert.Nil(t, err)
+		assert.Equal(t, c.seed, helper.BytesToHex(seed))
+	}
+}
+
+func TestNewMnemonic(t *testing.T) {
+	for _, bits := range []int{128, 160, 192, 224, 256} {
+		m, err := NewMnemonic(bits)
+		assert.Nil(t, err)
+		assert.Equal(t, bits/32*3, len(strings.Fields(m)))
+		assert.True(t, IsMnemonicValid(m))
+	}
+	_, err := NewMnemonic(100)
+	assert.NotNil(t, err)
+
+	assert.False(t, IsMnemonicValid("abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon"))
+	assert.False(t, IsMnemonicValid("abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon neo"))
+	assert.False(t, IsMnemonicValid("abandon about"))
+}
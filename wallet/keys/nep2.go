@@ -26,14 +26,31 @@ var (
 	nepHeader = []byte{0x01, 0x42}
 )
 
+// ScryptParams are the scrypt parameters of NEP-2, a NEP-6 wallet records them in its scrypt field
+type ScryptParams struct {
+	N int `json:"n"`
+	R int `json:"r"`
+	P int `json:"p"`
+}
+
+// DefaultScryptParams returns the parameters of the package vars N, R and P
+func DefaultScryptParams() ScryptParams {
+	return ScryptParams{N: N, R: R, P: P}
+}
+
 // NEP2Encrypt encrypts a the PrivateKey using a given passphrase
 // under the NEP-2 standard.
 func NEP2Encrypt(keyPair *KeyPair, passphrase string) (s string, err error) {
+	return NEP2EncryptWithParams(keyPair, passphrase, DefaultScryptParams())
+}
+
+// NEP2EncryptWithParams encrypts the PrivateKey with the scrypt parameters
+func NEP2EncryptWithParams(keyPair *KeyPair, passphrase string, params ScryptParams) (s string, err error) {
 	address := keyPair.PublicKey.Address()
 	addrHash := Hash256([]byte(address))[:4]
 	// Normalize the passphrase according to the NFC standard.
 	phraseNorm := norm.NFC.Bytes([]byte(passphrase))
-	derivedKey, err := scrypt.Key(phraseNorm, addrHash, N, R, P, keyLen)
+	derivedKey, err := scrypt.Key(phraseNorm, addrHash, params.N, params.R, params.P, keyLen)
 	if err != nil {
 		return s, err
 	}
@@ -63,6 +80,11 @@ func NEP2Encrypt(keyPair *KeyPair, passphrase string) (s string, err error) {
 // NEP2Decrypt decrypts an encrypted key using a given passphrase
 // under the NEP-2 standard.
 func NEP2Decrypt(key, passphrase string) (s *KeyPair, err error) {
+	return NEP2DecryptWithParams(key, passphrase, DefaultScryptParams())
+}
+
+// NEP2DecryptWithParams decrypts the encrypted key with the scrypt parameters
+func NEP2DecryptWithParams(key, passphrase string, params ScryptParams) (s *KeyPair, err error) {
 	b, err := Base58CheckDecode(key)
 	if err != nil {
 		return s, err
@@ -74,7 +96,7 @@ func NEP2Decrypt(key, passphrase string) (s *KeyPair, err error) {
 	addrHash := b[3:7]
 	// Normalize the passphrase according to the NFC standard.
 	phraseNorm := norm.NFC.Bytes([]byte(passphrase))
-	derivedKey, err := scrypt.Key(phraseNorm, addrHash, N, R, P, keyLen)
+	derivedKey, err := scrypt.Key(phraseNorm, addrHash, params.N, params.R, params.P, keyLen)
 	if err != nil {
 		return s, err
 	}
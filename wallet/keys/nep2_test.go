@@ -36,3 +36,20 @@ func TestNEP2Decrypt(t *testing.T) {
 		assert.Equal(t, testCase.Address, address)
 	}
 }
+
+func TestNEP2EncryptWithParams(t *testing.T) {
+	keyPair, _ := NewKeyPairFromWIF(KeyCases[0].Wif)
+	params := ScryptParams{N: 2, R: 1, P: 1}
+	nep2Key, err := NEP2EncryptWithParams(keyPair, "password", params)
+	assert.Nil(t, err)
+	assert.NotEqual(t, KeyCases[0].Nep2key, nep2Key)
+
+	decrypted, err := NEP2DecryptWithParams(nep2Key, "password", params)
+	assert.Nil(t, err)
+	assert.Equal(t, keyPair.PrivateKey, decrypted.PrivateKey)
+
+	_, err = NEP2Decrypt(nep2Key, "password")
+	assert.EqualError(t, err, "password mismatch")
+	_, err = NEP2EncryptWithParams(keyPair, "password", ScryptParams{N: 3, R: 1, P: 1})
+	assert.NotNil(t, err)
+}
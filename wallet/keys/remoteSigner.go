@@ -0,0 +1,109 @@
+package keys
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/joeqian10/neo-gogogo/helper"
+)
+
+// RemoteSignPath is the path of the sign api of a signing daemon
+const RemoteSignPath = "/sign"
+
+const unixScheme = "unix://"
+
+// RemoteSignRequest is the body posted to the sign api
+type RemoteSignRequest struct {
+	// PublicKey is the compressed public key in hex, which selects the signing key of the daemon
+	PublicKey string `json:"public_key"`
+	// Message is the message to sign in hex, for a transaction it is the unsigned raw transaction
+	Message string `json:"message"`
+}
+
+// RemoteSignResponse is the body returned by the sign api, the status is not 200 when Error is set
+type RemoteSignResponse struct {
+	Signature string `json:"signature,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// RemoteSigner is a Signer which asks a signing daemon, e.g. a hsm proxy, to sign over http or a unix socket.
+// The daemon serves POST RemoteSignPath with a RemoteSignRequest and answers a RemoteSignResponse.
+type RemoteSigner struct {
+	PublicKey *PublicKey
+	// Endpoint is the base url of the daemon, or unix:// followed by the socket path
+	Endpoint string
+	// Token is sent as a bearer token in the Authorization header if not empty
+	Token string
+
+	url        string
+	httpClient *http.Client
+}
+
+// NewRemoteSigner creates the signer of the public key, the endpoint is like http://127.0.0.1:8000
+// or unix:///var/run/signer.sock
+func NewRemoteSigner(endpoint string, publicKey *PublicKey, timeout time.Duration) *RemoteSigner {
+	s := &RemoteSigner{
+		PublicKey:  publicKey,
+		Endpoint:   endpoint,
+		url:        strings.TrimRight(endpoint, "/") + RemoteSignPath,
+		httpClient: &http.Client{Timeout: timeout},
+	}
+	if strings.HasPrefix(endpoint, unixScheme) {
+		path := strings.TrimPrefix(endpoint, unixScheme)
+		s.url = "http://unix" + RemoteSignPath
+		s.httpClient.Transport = &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, "unix", path)
+			},
+		}
+	}
+	return s
+}
+
+// Public implements the Signer interface.
+func (s *RemoteSigner) Public() *PublicKey {
+	return s.PublicKey
+}
+
+// Sign implements the Signer interface, the returned signature is verified against the public key
+func (s *RemoteSigner) Sign(message []byte) ([]byte, error) {
+	body, err := json.Marshal(RemoteSignRequest{
+		PublicKey: helper.BytesToHex(s.PublicKey.EncodeCompression()),
+		Message:   helper.BytesToHex(message),
+	})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest(http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.Token)
+	}
+	res, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	var response RemoteSignResponse
+	if err = json.NewDecoder(res.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("invalid response of the signing daemon, status %d: %v", res.StatusCode, err)
+	}
+	if res.StatusCode != http.StatusOK || response.Error != "" {
+		return nil, fmt.Errorf("signing daemon refused to sign, status %d: %s", res.StatusCode, response.Error)
+	}
+	signature := helper.HexToBytes(response.Signature)
+	if len(signature) != 64 || !VerifySignature(message, signature, s.PublicKey) {
+		return nil, fmt.Errorf("signing daemon returned an invalid signature")
+	}
+	return signature, nil
+}
@@ -0,0 +1,66 @@
+package keys
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/joeqian10/neo-gogogo/helper"
+)
+
+// testSignHandler signs with the pair, or refuses the message "refuse"
+func testSignHandler(pair *KeyPair) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var request RemoteSignRequest
+		_ = json.NewDecoder(r.Body).Decode(&request)
+		message := helper.HexToBytes(request.Message)
+		if r.URL.Path != RemoteSignPath || string(message) == "refuse" {
+			w.WriteHeader(http.StatusForbidden)
+			_ = json.NewEncoder(w).Encode(RemoteSignResponse{Error: "policy violation"})
+			return
+		}
+		signature, _ := pair.Sign(message)
+		_ = json.NewEncoder(w).Encode(RemoteSignResponse{Signature: helper.BytesToHex(signature)})
+	}
+}
+
+func TestRemoteSigner_Http(t *testing.T) {
+	pair, _ := NewKeyPairFromWIF(KeyCases[0].Wif)
+	server := httptest.NewServer(testSignHandler(pair))
+	defer server.Close()
+
+	var signer Signer = NewRemoteSigner(server.URL+"/", pair.PublicKey, time.Second)
+	signature, err := signer.Sign([]byte("hello"))
+	assert.Nil(t, err)
+	expected, _ := pair.Sign([]byte("hello"))
+	assert.Equal(t, expected, signature)
+
+	_, err = signer.Sign([]byte("refuse"))
+	assert.EqualError(t, err, "signing daemon refused to sign, status 403: policy violation")
+
+	// the daemon signs with another key
+	other, _ := GenerateKeyPair()
+	_, err = NewRemoteSigner(server.URL, other.PublicKey, time.Second).Sign([]byte("hello"))
+	assert.EqualError(t, err, "signing daemon returned an invalid signature")
+}
+
+func TestRemoteSigner_UnixSocket(t *testing.T) {
+	pair, _ := NewKeyPairFromWIF(KeyCases[0].Wif)
+	path := filepath.Join(t.TempDir(), "signer.sock")
+	listener, err := net.Listen("unix", path)
+	assert.Nil(t, err)
+	server := &http.Server{Handler: testSignHandler(pair)}
+	go func() { _ = server.Serve(listener) }()
+	defer server.Close()
+
+	signer := NewRemoteSigner("unix://"+path, pair.PublicKey, time.Second)
+	signature, err := signer.Sign([]byte("hello"))
+	assert.Nil(t, err)
+	assert.True(t, VerifySignature([]byte("hello"), signature, signer.Public()))
+}
@@ -0,0 +1,57 @@
+package keys
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"math/big"
+)
+
+// rfc6979 generates the deterministic nonces of RFC 6979 section 3.2 with HMAC-SHA256,
+// the hash should be the 32 bytes sha256 of the message and q should be a 256 bits curve order
+type rfc6979 struct {
+	q *big.Int
+	k []byte
+	v []byte
+}
+
+func newRfc6979(q *big.Int, privateKey *big.Int, hash []byte) *rfc6979 {
+	x := make([]byte, 32)
+	privateKey.FillBytes(x)
+	// bits2octets(h1), the hash is as long as q so bits2int is a plain conversion
+	h := new(big.Int).SetBytes(hash)
+	h.Mod(h, q)
+	h1 := make([]byte, 32)
+	h.FillBytes(h1)
+
+	g := &rfc6979{q: q, k: make([]byte, 32), v: make([]byte, 32)}
+	for i := range g.v {
+		g.v[i] = 0x01
+	}
+	for _, b := range []byte{0x00, 0x01} {
+		g.k = g.mac(g.v, []byte{b}, x, h1)
+		g.v = g.mac(g.v)
+	}
+	return g
+}
+
+func (g *rfc6979) mac(data ...[]byte) []byte {
+	m := hmac.New(sha256.New, g.k)
+	for _, d := range data {
+		m.Write(d)
+	}
+	return m.Sum(nil)
+}
+
+// next returns the next nonce in [1, q-1], call it again if the nonce gives r or s of zero
+func (g *rfc6979) next() *big.Int {
+	for {
+		g.v = g.mac(g.v)
+		k := new(big.Int).SetBytes(g.v)
+		// prepare for the next candidate before returning, as step h.3 does
+		g.k = g.mac(g.v, []byte{0x00})
+		g.v = g.mac(g.v)
+		if k.Sign() > 0 && k.Cmp(g.q) < 0 {
+			return k
+		}
+	}
+}
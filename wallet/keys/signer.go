@@ -0,0 +1,51 @@
+package keys
+
+// Signer signs messages with a private key which may be kept out of the process, e.g. in a hsm.
+// KeyPair is a Signer.
+type Signer interface {
+	// Public returns the public key of the signing key
+	Public() *PublicKey
+	// Sign signs the sha256 of the message, returns r and s of 32 bytes each
+	Sign(message []byte) ([]byte, error)
+}
+
+// SignerSlice sorts signers by their public keys
+type SignerSlice []Signer
+
+func (ss SignerSlice) Len() int           { return len(ss) }
+func (ss SignerSlice) Less(i, j int) bool { return ss[i].Public().Compare(ss[j].Public()) == -1 }
+func (ss SignerSlice) Swap(i, j int)      { ss[i], ss[j] = ss[j], ss[i] }
+
+// Public implements the Signer interface.
+func (p *KeyPair) Public() *PublicKey {
+	return p.PublicKey
+}
+
+// KeyPairSigner is the Signer of an in-memory key pair which signs with the options
+type KeyPairSigner struct {
+	KeyPair *KeyPair
+	Options SignOptions
+}
+
+func NewKeyPairSigner(pair *KeyPair, options SignOptions) *KeyPairSigner {
+	return &KeyPairSigner{KeyPair: pair, Options: options}
+}
+
+// Public implements the Signer interface.
+func (s *KeyPairSigner) Public() *PublicKey {
+	return s.KeyPair.PublicKey
+}
+
+// Sign implements the Signer interface.
+func (s *KeyPairSigner) Sign(message []byte) ([]byte, error) {
+	return s.KeyPair.SignWithOptions(message, s.Options)
+}
+
+// KeyPairsToSigners converts the key pairs for the functions taking signers
+func KeyPairsToSigners(pairs []*KeyPair) []Signer {
+	signers := make([]Signer, len(pairs))
+	for i, p := range pairs {
+		signers[i] = p
+	}
+	return signers
+}
@@ -0,0 +1,34 @@
+package keys
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeyPairSigner(t *testing.T) {
+	pair, _ := NewKeyPairFromWIF(KeyCases[0].Wif)
+	var signer Signer = pair
+	assert.Equal(t, pair.PublicKey, signer.Public())
+
+	signer = NewKeyPairSigner(pair, SignOptions{Random: true})
+	s1, err := signer.Sign([]byte("hello"))
+	assert.Nil(t, err)
+	s2, _ := signer.Sign([]byte("hello"))
+	assert.NotEqual(t, s1, s2)
+	assert.True(t, VerifySignature([]byte("hello"), s1, signer.Public()))
+}
+
+func TestSignerSlice(t *testing.T) {
+	var pairs []*KeyPair
+	for _, c := range KeyCases {
+		pair, _ := NewKeyPairFromWIF(c.Wif)
+		pairs = append(pairs, pair)
+	}
+	signers := SignerSlice(KeyPairsToSigners(pairs))
+	sort.Sort(signers)
+	for i := 1; i < len(signers); i++ {
+		assert.Equal(t, -1, signers[i-1].Public().Compare(signers[i].Public()))
+	}
+}
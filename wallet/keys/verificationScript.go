@@ -0,0 +1,115 @@
+package keys
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/joeqian10/neo-gogogo/crypto"
+	"github.com/joeqian10/neo-gogogo/helper"
+	"github.com/joeqian10/neo-gogogo/sc"
+)
+
+// VerificationScriptKind is the kind of a verification script
+type VerificationScriptKind byte
+
+const (
+	// ContractScript is any verification script other than the standard ones
+	ContractScript VerificationScriptKind = iota
+	// SignatureScript is the script created by CreateSignatureRedeemScript
+	SignatureScript
+	// MultiSigScript is the script created by CreateMultiSigRedeemScript
+	MultiSigScript
+)
+
+// MaxMultiSigKeys is the max number of public keys of a multi-signature script
+const MaxMultiSigKeys = 1024
+
+const (
+	pushBytes2  = sc.PUSHBYTES1 + 1
+	pushBytes33 = sc.PUSHBYTES1 + 32 // a compressed public key
+)
+
+// String implements the Stringer interface.
+func (k VerificationScriptKind) String() string {
+	switch k {
+	case SignatureScript:
+		return "Signature"
+	case MultiSigScript:
+		return "MultiSig"
+	}
+	return "Contract"
+}
+
+// VerificationScript describes a verification script
+type VerificationScript struct {
+	Kind VerificationScriptKind
+	// M is the number of signatures required, 1 for SignatureScript and 0 for ContractScript
+	M int
+	// PublicKeys are the keys in the script, empty for ContractScript
+	PublicKeys []*PublicKey
+	Script     []byte
+	ScriptHash helper.UInt160
+}
+
+// ParseVerificationScript recognizes the single signature and the m-of-n multi-signature scripts
+// with m and n pushed as PUSH1-PUSH16, PUSHBYTES1 or PUSHBYTES2, any other valid script is a ContractScript
+func ParseVerificationScript(script []byte) (*VerificationScript, error) {
+	instructions, err := sc.Disassemble(script)
+	if err != nil {
+		return nil, err
+	}
+	if len(instructions) == 0 {
+		return nil, fmt.Errorf("verification script is empty")
+	}
+	hash, _ := helper.UInt160FromBytes(crypto.Hash160(script))
+	result := &VerificationScript{Kind: ContractScript, Script: script, ScriptHash: hash}
+	if len(instructions) == 2 && instructions[0].OpCode == pushBytes33 && instructions[1].OpCode == sc.CHECKSIG {
+		p, err := NewPublicKey(instructions[0].Operand)
+		if err != nil {
+			return result, nil
+		}
+		result.Kind, result.M, result.PublicKeys = SignatureScript, 1, []*PublicKey{p}
+		return result, nil
+	}
+	l := len(instructions)
+	if l < 4 || instructions[l-1].OpCode != sc.CHECKMULTISIG {
+		return result, nil
+	}
+	m, ok := pushedCount(instructions[0])
+	if !ok {
+		return result, nil
+	}
+	n, ok := pushedCount(instructions[l-2])
+	if !ok || n != l-3 || m > n {
+		return result, nil
+	}
+	publicKeys := make([]*PublicKey, n)
+	for i, ins := range instructions[1 : l-2] {
+		if ins.OpCode != pushBytes33 {
+			return result, nil
+		}
+		p, err := NewPublicKey(ins.Operand)
+		if err != nil {
+			return result, nil
+		}
+		publicKeys[i] = p
+	}
+	result.Kind, result.M, result.PublicKeys = MultiSigScript, m, publicKeys
+	return result, nil
+}
+
+// pushedCount reads the m or n of a multi-signature script
+func pushedCount(ins sc.Instruction) (int, bool) {
+	var count int
+	switch {
+	case ins.OpCode >= sc.PUSH1 && ins.OpCode <= sc.PUSH16:
+		count = int(ins.OpCode-sc.PUSH1) + 1
+	case ins.OpCode == sc.PUSHBYTES1:
+		count = int(ins.Operand[0])
+	case ins.OpCode == pushBytes2:
+		count = int(binary.LittleEndian.Uint16(ins.Operand))
+	default:
+		return 0, false
+	}
+	return count, count >= 1 && count <= MaxMultiSigKeys
+}
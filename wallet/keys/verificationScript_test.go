@@ -0,0 +1,64 @@
+package keys
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/joeqian10/neo-gogogo/helper"
+	"github.com/joeqian10/neo-gogogo/sc"
+)
+
+func TestParseVerificationScript(t *testing.T) {
+	p, _ := NewPublicKeyFromString(KeyCases[0].PublicKey)
+	vs, err := ParseVerificationScript(CreateSignatureRedeemScript(p))
+	assert.Nil(t, err)
+	assert.Equal(t, SignatureScript, vs.Kind)
+	assert.Equal(t, 1, vs.M)
+	assert.Equal(t, KeyCases[0].PublicKey, vs.PublicKeys[0].String())
+	assert.Equal(t, KeyCases[0].ScriptHash, vs.ScriptHash.String())
+
+	for _, c := range []struct{ m, n int }{{3, 4}, {17, 20}, {200, 300}} {
+		publicKeys := make([]*PublicKey, c.n)
+		for i := range publicKeys {
+			pair, _ := GenerateKeyPair()
+			publicKeys[i] = pair.PublicKey
+		}
+		script, err := CreateMultiSigRedeemScript(c.m, publicKeys...)
+		assert.Nil(t, err)
+		vs, err = ParseVerificationScript(script)
+		assert.Nil(t, err)
+		assert.Equal(t, MultiSigScript, vs.Kind)
+		assert.Equal(t, c.m, vs.M)
+		assert.Equal(t, c.n, len(vs.PublicKeys))
+	}
+
+	// 2-of-2 pushed by PUSHBYTES1
+	sb := sc.NewScriptBuilder()
+	sb.EmitPushBytes([]byte{2})
+	sb.EmitPushBytes(p.EncodeCompression())
+	sb.EmitPushBytes(p.EncodeCompression())
+	sb.EmitPushBytes([]byte{2})
+	sb.Emit(sc.CHECKMULTISIG)
+	vs, err = ParseVerificationScript(sb.ToArray())
+	assert.Nil(t, err)
+	assert.Equal(t, MultiSigScript, vs.Kind)
+	assert.Equal(t, 2, vs.M)
+
+	for _, script := range []string{
+		"0101" + "21" + KeyCases[0].PublicKey + "52ae", // n does not match the keys
+		"53" + "21" + KeyCases[0].PublicKey + "51ae",   // m > n
+		"00c1046e616d656763d26113bac4208254d98a3eebaee66230ead7b9",
+		"51",
+	} {
+		vs, err = ParseVerificationScript(helper.HexToBytes(script))
+		assert.Nil(t, err)
+		assert.Equal(t, ContractScript, vs.Kind, script)
+		assert.Nil(t, vs.PublicKeys)
+	}
+
+	_, err = ParseVerificationScript([]byte{})
+	assert.NotNil(t, err)
+	_, err = ParseVerificationScript([]byte{0x21, 0x02})
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,126 @@
+//go:build ignore
+
+// makeTestDb3 writes the sql which builds test.db3, the legacy wallet read by TestNewWalletFromDb3:
+//
+//	go run makeTestDb3.go | sqlite3 test.db3
+//
+// The tables and the encryption follow UserWallet of neo 2.x (neo/Wallets/SQLite: UserWallet.cs,
+// WalletDataContext.cs, Account.cs, Address.cs, Contract.cs and Key.cs), whose database neo-gui 2.x creates:
+//   - Key holds PasswordHash = sha256(passwordKey) with passwordKey = sha256(sha256(password)), the IV,
+//     MasterKey = AES-256-CBC(masterKey, passwordKey, IV) and Version as 4 little endian int32.
+//   - Account holds PublicKeyHash = hash160 of the compressed public key, and PrivateKeyEncrypted =
+//     AES-256-CBC(uncompressed public key without the 0x04 prefix || private key, masterKey, IV).
+//   - Contract holds the ScriptHash, the PublicKeyHash and RawData, which is the serialized
+//     VerificationContract: the parameter list and the script as var bytes, prefixed with the
+//     PublicKeyHash by the versions before 2.0.
+//   - Address holds the ScriptHash of every address, including the watch-only ones.
+//
+// The keys are keys.KeyCases[0] and [1], the password is "neo-gogogo". 100 watch-only addresses make
+// interior b-tree pages, and a key of 3000 bytes makes overflow pages for the sqlite reader.
+package main
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha256"
+	"fmt"
+
+	"github.com/joeqian10/neo-gogogo/crypto"
+	"github.com/joeqian10/neo-gogogo/helper"
+	"github.com/joeqian10/neo-gogogo/wallet/keys"
+)
+
+// the schema created by the entity framework migrations of WalletDataContext
+const schema = `PRAGMA page_size=1024;
+CREATE TABLE "Account" ("PublicKeyHash" BLOB NOT NULL CONSTRAINT "PK_Account" PRIMARY KEY, "PrivateKeyEncrypted" BLOB NOT NULL);
+CREATE TABLE "Address" ("ScriptHash" BLOB NOT NULL CONSTRAINT "PK_Address" PRIMARY KEY);
+CREATE TABLE "Key" ("Name" TEXT NOT NULL CONSTRAINT "PK_Key" PRIMARY KEY, "Value" BLOB NOT NULL);
+CREATE TABLE "Contract" ("ScriptHash" BLOB NOT NULL CONSTRAINT "PK_Contract" PRIMARY KEY, "PublicKeyHash" BLOB NOT NULL, "RawData" BLOB NOT NULL, CONSTRAINT "FK_Contract_Account_PublicKeyHash" FOREIGN KEY ("PublicKeyHash") REFERENCES "Account" ("PublicKeyHash") ON DELETE CASCADE, CONSTRAINT "FK_Contract_Address_ScriptHash" FOREIGN KEY ("ScriptHash") REFERENCES "Address" ("ScriptHash") ON DELETE CASCADE);
+CREATE INDEX "IX_Contract_PublicKeyHash" ON "Contract" ("PublicKeyHash");
+`
+
+func main() {
+	password := "neo-gogogo"
+	iv := helper.HexToBytes("000102030405060708090a0b0c0d0e0f")
+	masterKey := sha256.Sum256([]byte("db3 master key"))
+	passwordKey := sha256.Sum256([]byte(password))
+	passwordKey = sha256.Sum256(passwordKey[:])
+	passwordHash := sha256.Sum256(passwordKey[:])
+
+	fmt.Print(schema)
+	insert("Key", "'PasswordHash'", blob(passwordHash[:]))
+	insert("Key", "'IV'", blob(iv))
+	insert("Key", "'MasterKey'", blob(aesCbcEncrypt(masterKey[:], passwordKey[:], iv)))
+	extra := make([]byte, 3000)
+	for i := range extra {
+		extra[i] = byte(i % 251)
+	}
+	insert("Key", "'Extra'", blob(extra))
+	insert("Key", "'Version'", blob([]byte{2, 0, 0, 0, 9, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0}))
+
+	var pairs []*keys.KeyPair
+	var publicKeyHashes [][]byte
+	for i := 0; i < 2; i++ {
+		pair, err := keys.NewKeyPairFromWIF(keys.KeyCases[i].Wif)
+		if err != nil {
+			panic(err)
+		}
+		pairs = append(pairs, pair)
+		publicKeyHash := crypto.Hash160(pair.PublicKey.EncodeCompression())
+		publicKeyHashes = append(publicKeyHashes, publicKeyHash)
+		plain := make([]byte, 96)
+		pair.PublicKey.X.FillBytes(plain[:32])
+		pair.PublicKey.Y.FillBytes(plain[32:64])
+		copy(plain[64:], pair.PrivateKey)
+		insert("Account", blob(publicKeyHash), blob(aesCbcEncrypt(plain, masterKey[:], iv)))
+	}
+
+	signature := keys.CreateSignatureRedeemScript(pairs[0].PublicKey)
+	signatureHash := crypto.Hash160(signature)
+	multi, err := keys.CreateMultiSigRedeemScript(1, pairs[0].PublicKey, pairs[1].PublicKey)
+	if err != nil {
+		panic(err)
+	}
+	multiHash := crypto.Hash160(multi)
+	insert("Address", blob(signatureHash))
+	insert("Address", blob(multiHash))
+	for i := 0; i < 100; i++ {
+		h := sha256.Sum256([]byte(fmt.Sprintf("watch %d", i)))
+		insert("Address", blob(h[:20]))
+	}
+
+	insert("Contract", blob(signatureHash), blob(publicKeyHashes[0]),
+		blob(append(varBytes([]byte{byte(0x00)}), varBytes(signature)...)))
+	// the format before 2.0, prefixed with the public key hash
+	raw := append(append(append([]byte{}, publicKeyHashes[1]...), varBytes([]byte{0x00})...), varBytes(multi)...)
+	insert("Contract", blob(multiHash), blob(publicKeyHashes[1]), blob(raw))
+}
+
+func insert(table string, values ...string) {
+	fmt.Printf("INSERT INTO \"%s\" VALUES (", table)
+	for i, v := range values {
+		if i > 0 {
+			fmt.Print(", ")
+		}
+		fmt.Print(v)
+	}
+	fmt.Println(");")
+}
+
+func blob(b []byte) string {
+	return "X'" + helper.BytesToHex(b) + "'"
+}
+
+func varBytes(b []byte) []byte {
+	return append([]byte{byte(len(b))}, b...)
+}
+
+func aesCbcEncrypt(data []byte, key []byte, iv []byte) []byte {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		panic(err)
+	}
+	result := make([]byte, len(data))
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(result, data)
+	return result
+}
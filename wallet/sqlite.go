@@ -0,0 +1,321 @@
+package wallet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"strings"
+)
+
+// sqliteFile is a minimal read-only reader of the sqlite 3 file format, enough to read the rows of
+// the rowid tables of a legacy wallet. Indexes, WITHOUT ROWID tables and the journal are not supported.
+type sqliteFile struct {
+	data       []byte
+	pageSize   int
+	usableSize int
+}
+
+var sqliteMagic = []byte("SQLite format 3\x00")
+
+func openSqliteFile(path string) (*sqliteFile, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return newSqliteFile(data)
+}
+
+func newSqliteFile(data []byte) (*sqliteFile, error) {
+	if len(data) < 100 || !bytes.Equal(data[:16], sqliteMagic) {
+		return nil, fmt.Errorf("not a sqlite 3 database")
+	}
+	pageSize := int(binary.BigEndian.Uint16(data[16:18]))
+	if pageSize == 1 {
+		pageSize = 65536
+	}
+	if pageSize < 512 || pageSize&(pageSize-1) != 0 {
+		return nil, fmt.Errorf("invalid sqlite page size %d", pageSize)
+	}
+	if encoding := binary.BigEndian.Uint32(data[56:60]); encoding > 1 {
+		return nil, fmt.Errorf("unsupported sqlite text encoding %d", encoding)
+	}
+	return &sqliteFile{data: data, pageSize: pageSize, usableSize: pageSize - int(data[20])}, nil
+}
+
+func (f *sqliteFile) page(number uint32) ([]byte, error) {
+	start := int(number-1) * f.pageSize
+	if number == 0 || start+f.pageSize > len(f.data) {
+		return nil, fmt.Errorf("sqlite page %d out of range", number)
+	}
+	return f.data[start : start+f.pageSize], nil
+}
+
+// readTable returns the rows of the table as maps from the column names to int64, float64, string, []byte or nil
+func (f *sqliteFile) readTable(name string) ([]map[string]interface{}, error) {
+	master, err := f.readBTree(1)
+	if err != nil {
+		return nil, err
+	}
+	// sqlite_master: type, name, tbl_name, rootpage, sql
+	for _, row := range master {
+		if len(row) < 5 || row[0] != "table" || !strings.EqualFold(fmt.Sprint(row[1]), name) {
+			continue
+		}
+		rootPage, ok1 := row[3].(int64)
+		sql, ok2 := row[4].(string)
+		if !ok1 || !ok2 {
+			return nil, fmt.Errorf("invalid schema of the table %s", name)
+		}
+		if strings.Contains(strings.ToUpper(sql), "WITHOUT ROWID") {
+			return nil, fmt.Errorf("WITHOUT ROWID table %s is not supported", name)
+		}
+		columns := parseColumnNames(sql)
+		records, err := f.readBTree(uint32(rootPage))
+		if err != nil {
+			return nil, err
+		}
+		rows := make([]map[string]interface{}, len(records))
+		for i, record := range records {
+			rows[i] = make(map[string]interface{}, len(columns))
+			for j, column := range columns {
+				// columns added by ALTER TABLE are missing in the older records
+				if j < len(record) {
+					rows[i][column] = record[j]
+				} else {
+					rows[i][column] = nil
+				}
+			}
+		}
+		return rows, nil
+	}
+	return nil, fmt.Errorf("table %s not found", name)
+}
+
+// readBTree returns the records of the table b-tree in rowid order
+func (f *sqliteFile) readBTree(root uint32) ([][]interface{}, error) {
+	var records [][]interface{}
+	var visit func(number uint32, depth int) error
+	visit = func(number uint32, depth int) error {
+		if depth > 64 {
+			return fmt.Errorf("sqlite b-tree is too deep")
+		}
+		page, err := f.page(number)
+		if err != nil {
+			return err
+		}
+		offset := 0
+		if number == 1 {
+			offset = 100
+		}
+		if offset+8 > len(page) {
+			return fmt.Errorf("sqlite page %d is truncated", number)
+		}
+		kind := page[offset]
+		cellCount := int(binary.BigEndian.Uint16(page[offset+3:]))
+		headerSize := 8
+		if kind == 0x05 {
+			headerSize = 12
+		} else if kind != 0x0d {
+			return fmt.Errorf("sqlite page %d is not a table b-tree page", number)
+		}
+		if offset+headerSize+cellCount*2 > len(page) {
+			return fmt.Errorf("sqlite page %d is truncated", number)
+		}
+		for i := 0; i < cellCount; i++ {
+			cell := int(binary.BigEndian.Uint16(page[offset+headerSize+i*2:]))
+			if cell+4 > len(page) {
+				return fmt.Errorf("invalid cell pointer in sqlite page %d", number)
+			}
+			if kind == 0x05 {
+				if err = visit(binary.BigEndian.Uint32(page[cell:]), depth+1); err != nil {
+					return err
+				}
+				continue
+			}
+			payload, err := f.readPayload(page, cell)
+			if err != nil {
+				return err
+			}
+			record, err := parseRecord(payload)
+			if err != nil {
+				return err
+			}
+			records = append(records, record)
+		}
+		if kind == 0x05 {
+			return visit(binary.BigEndian.Uint32(page[offset+8:]), depth+1)
+		}
+		return nil
+	}
+	return records, visit(root, 0)
+}
+
+// readPayload reads the payload of the leaf table cell, following the overflow pages
+func (f *sqliteFile) readPayload(page []byte, cell int) ([]byte, error) {
+	size, n := readVarint(page[cell:])
+	cell += n
+	_, n = readVarint(page[cell:]) // rowid
+	cell += n
+	total := int(size)
+	if total < 0 || total > len(f.data) {
+		return nil, fmt.Errorf("invalid sqlite payload size %d", size)
+	}
+	u := f.usableSize
+	local := total
+	if x := u - 35; total > x {
+		m := (u-12)*32/255 - 23
+		local = m + (total-m)%(u-4)
+		if local > x {
+			local = m
+		}
+	}
+	if cell+local > len(page) {
+		return nil, fmt.Errorf("sqlite cell is truncated")
+	}
+	payload := make([]byte, 0, total)
+	payload = append(payload, page[cell:cell+local]...)
+	if local == total {
+		return payload, nil
+	}
+	if cell+local+4 > len(page) {
+		return nil, fmt.Errorf("sqlite cell is truncated")
+	}
+	next := binary.BigEndian.Uint32(page[cell+local:])
+	for len(payload) < total {
+		overflow, err := f.page(next)
+		if err != nil {
+			return nil, err
+		}
+		next = binary.BigEndian.Uint32(overflow)
+		n := total - len(payload)
+		if n > u-4 {
+			n = u - 4
+		}
+		payload = append(payload, overflow[4:4+n]...)
+	}
+	return payload, nil
+}
+
+// parseRecord decodes the values of the record format
+func parseRecord(payload []byte) ([]interface{}, error) {
+	headerSize, n := readVarint(payload)
+	if n == 0 || int(headerSize) > len(payload) {
+		return nil, fmt.Errorf("invalid sqlite record header")
+	}
+	var types []uint64
+	for pos := n; pos < int(headerSize); {
+		t, n := readVarint(payload[pos:])
+		if n == 0 {
+			return nil, fmt.Errorf("invalid sqlite record header")
+		}
+		types = append(types, t)
+		pos += n
+	}
+	values := make([]interface{}, len(types))
+	body := payload[headerSize:]
+	for i, t := range types {
+		size := 0
+		switch {
+		case t >= 1 && t <= 4:
+			size = int(t)
+		case t == 5:
+			size = 6
+		case t == 6 || t == 7:
+			size = 8
+		case t >= 12:
+			size = int(t-12) / 2
+		}
+		if size > len(body) {
+			return nil, fmt.Errorf("sqlite record is truncated")
+		}
+		v := body[:size]
+		body = body[size:]
+		switch {
+		case t == 0:
+			values[i] = nil
+		case t <= 6:
+			// big endian two's complement integer of 1, 2, 3, 4, 6 or 8 bytes
+			var x int64
+			if len(v) > 0 && v[0]&0x80 != 0 {
+				x = -1
+			}
+			for _, b := range v {
+				x = x<<8 | int64(b)
+			}
+			values[i] = x
+		case t == 7:
+			values[i] = math.Float64frombits(binary.BigEndian.Uint64(v))
+		case t == 8:
+			values[i] = int64(0)
+		case t == 9:
+			values[i] = int64(1)
+		case t >= 12 && t%2 == 0:
+			values[i] = append([]byte{}, v...)
+		case t >= 13:
+			values[i] = string(v)
+		default:
+			return nil, fmt.Errorf("invalid sqlite serial type %d", t)
+		}
+	}
+	return values, nil
+}
+
+// readVarint reads the big endian varint of sqlite, returns 0 bytes read if the data is truncated
+func readVarint(data []byte) (uint64, int) {
+	var v uint64
+	for i := 0; i < 9; i++ {
+		if i >= len(data) {
+			return 0, 0
+		}
+		if i == 8 {
+			return v<<8 | uint64(data[i]), 9
+		}
+		v = v<<7 | uint64(data[i]&0x7f)
+		if data[i]&0x80 == 0 {
+			return v, i + 1
+		}
+	}
+	return v, 9
+}
+
+// parseColumnNames returns the column names of the CREATE TABLE statement in order
+func parseColumnNames(sql string) []string {
+	start, end := strings.Index(sql, "("), strings.LastIndex(sql, ")")
+	if start < 0 || end <= start {
+		return nil
+	}
+	var columns []string
+	depth, from := 0, start+1
+	body := sql[:end]
+	for i := start + 1; i <= len(body); i++ {
+		if i < len(body) {
+			switch body[i] {
+			case '(':
+				depth++
+				continue
+			case ')':
+				depth--
+				continue
+			case ',':
+				if depth > 0 {
+					continue
+				}
+			default:
+				continue
+			}
+		}
+		def := strings.Fields(body[from:i])
+		from = i + 1
+		if len(def) == 0 {
+			continue
+		}
+		switch strings.ToUpper(def[0]) {
+		case "CONSTRAINT", "PRIMARY", "UNIQUE", "CHECK", "FOREIGN":
+			continue
+		}
+		columns = append(columns, strings.Trim(def[0], "\"`[]'"))
+	}
+	return columns
+}
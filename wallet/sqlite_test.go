@@ -0,0 +1,61 @@
+package wallet
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSqliteFile_ReadTable(t *testing.T) {
+	f, err := openSqliteFile("test.db3")
+	assert.Nil(t, err)
+	assert.Equal(t, 1024, f.pageSize)
+
+	// the rows of Address take several leaf pages under an interior page
+	addresses, err := f.readTable("Address")
+	assert.Nil(t, err)
+	assert.Equal(t, 102, len(addresses))
+	for _, row := range addresses {
+		assert.Equal(t, 20, len(row["ScriptHash"].([]byte)))
+	}
+
+	// the value of Extra is stored in overflow pages
+	stored, err := f.readTable("Key")
+	assert.Nil(t, err)
+	assert.Equal(t, 5, len(stored))
+	for _, row := range stored {
+		if row["Name"] != "Extra" {
+			continue
+		}
+		value := row["Value"].([]byte)
+		assert.Equal(t, 3000, len(value))
+		for i, b := range value {
+			if b != byte(i%251) {
+				t.Fatalf("wrong byte at %d", i)
+			}
+		}
+	}
+
+	_, err = f.readTable("Transaction")
+	assert.NotNil(t, err)
+}
+
+func TestNewSqliteFile(t *testing.T) {
+	_, err := newSqliteFile([]byte("not a sqlite database"))
+	assert.NotNil(t, err)
+}
+
+func TestParseColumnNames(t *testing.T) {
+	sql := `CREATE TABLE "Contract" ("ScriptHash" BLOB NOT NULL CONSTRAINT "PK_Contract" PRIMARY KEY, "PublicKeyHash" BLOB NOT NULL, "RawData" BLOB NOT NULL, CONSTRAINT "FK_Contract_Account_PublicKeyHash" FOREIGN KEY ("PublicKeyHash") REFERENCES "Account" ("PublicKeyHash") ON DELETE CASCADE)`
+	assert.Equal(t, []string{"ScriptHash", "PublicKeyHash", "RawData"}, parseColumnNames(sql))
+	assert.Equal(t, []string{"a", "b"}, parseColumnNames("CREATE TABLE t (a DECIMAL(10, 2), b TEXT)"))
+}
+
+func TestReadVarint(t *testing.T) {
+	v, n := readVarint([]byte{0x81, 0x00})
+	assert.Equal(t, uint64(128), v)
+	assert.Equal(t, 2, n)
+
+	_, n = readVarint([]byte{0x81})
+	assert.Equal(t, 0, n)
+}
@@ -3,7 +3,12 @@ package wallet
 import (
 	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"time"
 
 	"github.com/joeqian10/neo-gogogo/wallet/keys"
 )
@@ -30,13 +35,26 @@ type Wallet struct {
 	// Extra metadata can be used for storing arbitrary data.
 	// This field can be empty.
 	Extra interface{} `json:"extra"`
+
+	// the BIP44 account key of a deterministic wallet, it is not saved to the file
+	hd *hdAccount
+
+	// lockMu guards the auto-lock timer and the keys cleared by Lock
+	lockMu    sync.Mutex
+	autoLock  time.Duration
+	lockTimer *time.Timer
 }
 
 // ScryptParams is a json-serializable container for scrypt KDF parameters.
-type ScryptParams struct {
-	N int `json:"n"`
-	R int `json:"r"`
-	P int `json:"p"`
+type ScryptParams = keys.ScryptParams
+
+// CryptOptions configures EncryptAllWithOptions and DecryptAllWithOptions
+type CryptOptions struct {
+	// Workers is the max number of accounts encrypted or decrypted at once, 0 means runtime.NumCPU()
+	Workers int
+	// Progress is called after each account is done with the number of done accounts and the total,
+	// the calls are not concurrent
+	Progress func(done int, total int)
 }
 
 // NewWallet creates a NEO wallet.
@@ -44,13 +62,17 @@ func NewWallet() *Wallet {
 	return &Wallet{
 		Version:  walletVersion,
 		Accounts: []*Account{},
-		Scrypt:   &ScryptParams{keys.N, keys.R, keys.P},
+		Scrypt:   &ScryptParams{N: keys.N, R: keys.R, P: keys.P},
 	}
 }
 
-// CreateAccount generates a new account for the end user and encrypts
-// the private key with the given passphrase.
+// AddNewAccount generates a new account for the end user, a deterministic wallet
+// derives the account of the next unused index.
 func (w *Wallet) AddNewAccount() error {
+	if w.hd != nil {
+		_, err := w.addNextDerivedAccount()
+		return err
+	}
 	acc, err := NewAccount()
 	if err != nil {
 		return err
@@ -79,6 +101,26 @@ func (w *Wallet) ImportFromNEP2Key(nep2Key, passphare string) error {
 	return nil
 }
 
+// Import a watch-only account of the address
+func (w *Wallet) ImportWatchOnly(address string) error {
+	acc, err := NewWatchOnlyAccount(address)
+	if err != nil {
+		return err
+	}
+	w.AddAccount(acc)
+	return nil
+}
+
+// Import account from a verification script, the key pair is optional
+func (w *Wallet) ImportFromContract(script []byte, pair *keys.KeyPair) error {
+	acc, err := NewAccountFromContract(script, pair)
+	if err != nil {
+		return err
+	}
+	w.AddAccount(acc)
+	return nil
+}
+
 // AddAccount adds an existing Account to the wallet if the account is not in wallet
 func (w *Wallet) AddAccount(acc *Account) {
 	for _, account := range w.Accounts {
@@ -90,59 +132,190 @@ func (w *Wallet) AddAccount(acc *Account) {
 	w.Accounts = append(w.Accounts, acc)
 }
 
-// encrypt all the accounts in wallet, save the nep2Key
+// encrypt all the accounts in wallet with the scrypt parameters of the wallet, save the nep2Key
 func (w *Wallet) EncryptAll(password string) error {
+	return w.EncryptAllWithOptions(password, CryptOptions{})
+}
+
+// EncryptAllWithOptions encrypts the accounts with key pairs in parallel
+func (w *Wallet) EncryptAllWithOptions(password string, options CryptOptions) error {
+	var accounts []*Account
 	for _, acc := range w.Accounts {
-		if acc.KeyPair != nil {
-			err := acc.Encrypt(password)
-			if err != nil {
-				return err
-			}
+		if acc.hasKeyPair() {
+			accounts = append(accounts, acc)
 		}
 	}
-	return nil
+	params := w.scryptParams()
+	return runParallel(accounts, options, func(acc *Account) error {
+		return acc.EncryptWithParams(password, params)
+	})
 }
 
-// decrypt all the accounts in wallet, save the nep2Key
+// decrypt all the accounts in wallet with the scrypt parameters of the wallet, save the key pair
 func (w *Wallet) DecryptAll(password string) error {
+	return w.DecryptAllWithOptions(password, CryptOptions{})
+}
+
+// DecryptAllWithOptions decrypts the encrypted accounts in parallel, it stops at the first error
+// and the accounts decrypted before are kept
+func (w *Wallet) DecryptAllWithOptions(password string, options CryptOptions) error {
+	var accounts []*Account
 	for _, acc := range w.Accounts {
-		if acc.KeyPair == nil && acc.Nep2Key != "" {
-			err := acc.Decrypt(password)
-			if err != nil {
-				return err
+		if acc.isEncrypted() {
+			accounts = append(accounts, acc)
+		}
+	}
+	params := w.scryptParams()
+	return runParallel(accounts, options, func(acc *Account) error {
+		return acc.DecryptWithParams(password, params)
+	})
+}
+
+// scryptParams returns the scrypt parameters of the wallet, or the default ones if it has none
+func (w *Wallet) scryptParams() keys.ScryptParams {
+	if w.Scrypt == nil {
+		return keys.DefaultScryptParams()
+	}
+	return *w.Scrypt
+}
+
+// runParallel runs f on the accounts with at most options.Workers goroutines, and returns the first error
+func runParallel(accounts []*Account, options CryptOptions, f func(acc *Account) error) error {
+	workers := options.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		firstErr error
+		done     int
+	)
+	jobs := make(chan *Account)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for acc := range jobs {
+				err := f(acc)
+				mu.Lock()
+				if err != nil && firstErr == nil {
+					firstErr = err
+				}
+				done++
+				if options.Progress != nil {
+					options.Progress(done, len(accounts))
+				}
+				mu.Unlock()
 			}
+		}()
+	}
+	for _, acc := range accounts {
+		mu.Lock()
+		failed := firstErr != nil
+		mu.Unlock()
+		if failed {
+			break
 		}
+		jobs <- acc
 	}
-	return nil
+	close(jobs)
+	wg.Wait()
+	return firstErr
+}
+
+// SaveOptions configures SaveWithOptions
+type SaveOptions struct {
+	// Backups is the number of previous versions kept as path.1 (the latest) to path.n, 0 keeps none
+	Backups int
 }
 
-// Save saves the wallet data. It's the internal io.ReadWriter
-// that is responsible for saving the data. This can
-// be a buffer, file, etc..
+// Save saves the wallet data atomically, a crash leaves either the old or the new file.
 func (w *Wallet) Save(path string) error {
+	return w.SaveWithOptions(path, SaveOptions{})
+}
+
+// SaveWithOptions writes the wallet to a temp file in the same directory, syncs it and renames it to the path,
+// the previous file is rotated to the backups first
+func (w *Wallet) SaveWithOptions(path string, options SaveOptions) error {
 	for _, acc := range w.Accounts {
-		if acc.KeyPair != nil && acc.Nep2Key == "" {
+		if acc.isUnencrypted() {
 			return fmt.Errorf("please encrypt the accounts before save wallet")
 		}
 	}
 
-	file, err := os.Create(path)
+	dir := filepath.Dir(path)
+	file, err := ioutil.TempFile(dir, filepath.Base(path)+".tmp")
 	if err != nil {
 		return err
 	}
+	tempPath := file.Name()
+	defer os.Remove(tempPath) // no-op after the rename
 	err = json.NewEncoder(file).Encode(w)
+	if err == nil {
+		err = file.Sync()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
 	if err != nil {
 		return err
 	}
-	return file.Close()
+	if options.Backups > 0 {
+		if err = rotateBackups(path, options.Backups); err != nil {
+			return err
+		}
+	}
+	if err = os.Rename(tempPath, path); err != nil {
+		return err
+	}
+	return syncDir(dir)
+}
+
+// rotateBackups moves path.i to path.i+1 and links path to path.1, the oldest backup is dropped
+func rotateBackups(path string, backups int) error {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return nil
+	}
+	for i := backups - 1; i >= 1; i-- {
+		from := fmt.Sprintf("%s.%d", path, i)
+		if _, err := os.Stat(from); os.IsNotExist(err) {
+			continue
+		}
+		if err := os.Rename(from, fmt.Sprintf("%s.%d", path, i+1)); err != nil {
+			return err
+		}
+	}
+	// a hard link keeps the wallet file in place until the rename replaces it
+	latest := path + ".1"
+	_ = os.Remove(latest)
+	if err := os.Link(path, latest); err == nil {
+		return nil
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(latest, data, 0600)
+}
+
+// syncDir makes the rename durable, it is not supported on every platform so errors are ignored
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return nil
+	}
+	_ = d.Sync()
+	return d.Close()
 }
 
 // NewWalletFromFile creates a Wallet from the given wallet file path
 func NewWalletFromFile(path string) (*Wallet, error) {
-	file, err := os.OpenFile(path, os.O_RDWR, os.ModeAppend)
+	file, err := os.Open(path)
 	if err != nil {
 		return nil, err
 	}
+	defer file.Close()
 	wall := &Wallet{}
 	if err := json.NewDecoder(file).Decode(wall); err != nil {
 		return nil, err
@@ -0,0 +1,161 @@
+package wallet
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"sync"
+
+	"github.com/joeqian10/neo-gogogo/helper"
+	"github.com/joeqian10/neo-gogogo/rpc"
+	"github.com/joeqian10/neo-gogogo/rpc/models"
+)
+
+// DefaultBalanceConcurrency is the default number of rpc queries GetBalance runs at once
+const DefaultBalanceConcurrency = 4
+
+// AccountBalance is the balance of an account of the wallet
+type AccountBalance struct {
+	Address string
+	// Assets are the global asset totals of the unspents
+	Assets map[helper.UInt256]helper.Fixed8
+	// Unspents are the unspents grouped by asset as the rpc returns them
+	Unspents []models.UnspentBalance
+	// ClaimableGas is the gas of spent NEO which can be claimed now
+	ClaimableGas helper.Fixed8
+	// UnclaimedGas is the gas of unspent NEO which can be claimed after the NEO is spent
+	UnclaimedGas helper.Fixed8
+	// Nep5 are the NEP-5 balances without decimals, empty if the node does not have the plugin
+	Nep5 map[helper.UInt160]*big.Int
+}
+
+// WalletBalance is the balance of all accounts of the wallet
+type WalletBalance struct {
+	Accounts     []*AccountBalance
+	Assets       map[helper.UInt256]helper.Fixed8
+	ClaimableGas helper.Fixed8
+	UnclaimedGas helper.Fixed8
+	Nep5         map[helper.UInt160]*big.Int
+}
+
+// GetBalance queries the unspents, the unclaimed gas and the NEP-5 balances of all accounts of the wallet,
+// running at most concurrency rpc queries at once, and sums them up. The NEP-5 balances are skipped
+// if the node does not have the plugin.
+func (w *Wallet) GetBalance(client rpc.IRpcClient, concurrency int) (*WalletBalance, error) {
+	if concurrency <= 0 {
+		concurrency = DefaultBalanceConcurrency
+	}
+	accounts := make([]*AccountBalance, len(w.Accounts))
+	var queries []func() error
+	for i, acc := range w.Accounts {
+		ab := &AccountBalance{
+			Address: acc.Address,
+			Assets:  make(map[helper.UInt256]helper.Fixed8),
+			Nep5:    make(map[helper.UInt160]*big.Int),
+		}
+		accounts[i] = ab
+		queries = append(queries,
+			func() error { return ab.queryUnspents(client) },
+			func() error { return ab.queryUnclaimed(client) },
+			func() error { return ab.queryNep5Balances(client) })
+	}
+
+	errs := make([]error, len(queries))
+	semaphore := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, query := range queries {
+		wg.Add(1)
+		semaphore <- struct{}{}
+		go func(i int, query func() error) {
+			defer func() {
+				<-semaphore
+				wg.Done()
+			}()
+			errs[i] = query()
+		}(i, query)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	result := &WalletBalance{
+		Accounts: accounts,
+		Assets:   make(map[helper.UInt256]helper.Fixed8),
+		Nep5:     make(map[helper.UInt160]*big.Int),
+	}
+	for _, ab := range accounts {
+		for asset, amount := range ab.Assets {
+			result.Assets[asset] = result.Assets[asset].Add(amount)
+		}
+		for asset, amount := range ab.Nep5 {
+			if result.Nep5[asset] == nil {
+				result.Nep5[asset] = new(big.Int)
+			}
+			result.Nep5[asset].Add(result.Nep5[asset], amount)
+		}
+		result.ClaimableGas = result.ClaimableGas.Add(ab.ClaimableGas)
+		result.UnclaimedGas = result.UnclaimedGas.Add(ab.UnclaimedGas)
+	}
+	return result, nil
+}
+
+func (ab *AccountBalance) queryUnspents(client rpc.IRpcClient) error {
+	response := client.GetUnspents(ab.Address)
+	if response.HasError() {
+		return fmt.Errorf(response.GetErrorInfo())
+	}
+	ab.Unspents = response.Result.Balances
+	for _, balance := range ab.Unspents {
+		asset, err := helper.UInt256FromString(balance.AssetHash)
+		if err != nil {
+			return err
+		}
+		total := ab.Assets[asset]
+		for _, unspent := range balance.Unspents {
+			total = total.Add(fixed8FromFloat64(unspent.Value))
+		}
+		ab.Assets[asset] = total
+	}
+	return nil
+}
+
+func (ab *AccountBalance) queryUnclaimed(client rpc.IRpcClient) error {
+	response := client.GetUnclaimed(ab.Address)
+	if response.HasError() {
+		return fmt.Errorf(response.GetErrorInfo())
+	}
+	ab.ClaimableGas = fixed8FromFloat64(response.Result.Available)
+	ab.UnclaimedGas = fixed8FromFloat64(response.Result.Unavailable)
+	return nil
+}
+
+func (ab *AccountBalance) queryNep5Balances(client rpc.IRpcClient) error {
+	response := client.GetNep5Balances(ab.Address)
+	// the node does not have the plugin of NEP-5 balances
+	if response.IsMethodNotFound() {
+		return nil
+	}
+	if response.HasError() {
+		return fmt.Errorf(response.GetErrorInfo())
+	}
+	for _, balance := range response.Result.Balances {
+		asset, err := helper.UInt160FromString(balance.AssetHash)
+		if err != nil {
+			return err
+		}
+		amount, ok := new(big.Int).SetString(balance.Amount, 10)
+		if !ok {
+			return fmt.Errorf("invalid NEP-5 amount %s of %s", balance.Amount, balance.AssetHash)
+		}
+		ab.Nep5[asset] = amount
+	}
+	return nil
+}
+
+// fixed8FromFloat64 rounds to the nearest Fixed8, helper.Fixed8FromFloat64 truncates values like 0.29
+func fixed8FromFloat64(val float64) helper.Fixed8 {
+	return helper.NewFixed8(int64(math.Round(val * helper.D)))
+}
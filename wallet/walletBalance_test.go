@@ -0,0 +1,76 @@
+package wallet
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/joeqian10/neo-gogogo/helper"
+	"github.com/joeqian10/neo-gogogo/rpc"
+	"github.com/joeqian10/neo-gogogo/rpc/models"
+	"github.com/joeqian10/neo-gogogo/tx"
+)
+
+func TestWallet_GetBalance(t *testing.T) {
+	w, _ := NewWalletFromMnemonic(testMnemonic, "", 0)
+	assert.Nil(t, w.AddNewAccount())
+	assert.Nil(t, w.AddNewAccount())
+	a0, a1 := w.Accounts[0].Address, w.Accounts[1].Address
+	token := "0x9aff1e08aea2048a26a3d2ddbb3df495b932b1e7"
+
+	clientMock := new(rpc.RpcClientMock)
+	for _, address := range []string{a0, a1} {
+		clientMock.On("GetUnspents", address).Return(rpc.GetUnspentsResponse{
+			Result: models.RpcUnspent{Balances: []models.UnspentBalance{
+				{AssetHash: tx.NeoTokenId, Unspents: []models.Unspent{{Value: 2}, {Value: 3}}},
+				{AssetHash: tx.GasTokenId, Unspents: []models.Unspent{{Value: 0.29}}},
+			}},
+		})
+		clientMock.On("GetUnclaimed", address).Return(rpc.GetUnclaimedResponse{
+			Result: models.UnclaimedGasInAddress{Available: 0.1, Unavailable: 0.02},
+		})
+	}
+	clientMock.On("GetNep5Balances", a0).Return(rpc.GetNep5BalancesResponse{
+		Result: models.RpcNep5Balances{Balances: []models.Nep5Balance{{AssetHash: token, Amount: "100000000000000000000"}}},
+	})
+	// a node without the plugin
+	clientMock.On("GetNep5Balances", a1).Return(rpc.GetNep5BalancesResponse{
+		ErrorResponse: rpc.ErrorResponse{Error: rpc.RpcError{Code: -32601, Message: "Method not found"}},
+	})
+
+	b, err := w.GetBalance(clientMock, 2)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(b.Accounts))
+	assert.Equal(t, a1, b.Accounts[1].Address)
+	assert.Equal(t, helper.Fixed8FromInt64(5), b.Accounts[0].Assets[tx.NeoToken])
+	assert.Equal(t, helper.Fixed8FromInt64(10), b.Assets[tx.NeoToken])
+	assert.Equal(t, helper.NewFixed8(58000000), b.Assets[tx.GasToken])
+	assert.Equal(t, helper.NewFixed8(20000000), b.ClaimableGas)
+	assert.Equal(t, helper.NewFixed8(4000000), b.UnclaimedGas)
+	assert.Equal(t, 0, len(b.Accounts[1].Nep5))
+	hash, _ := helper.UInt160FromString(token)
+	expected, _ := new(big.Int).SetString("100000000000000000000", 10)
+	assert.Equal(t, expected, b.Nep5[hash])
+
+	// the first error is returned
+	clientMock = new(rpc.RpcClientMock)
+	clientMock.On("GetUnspents", mock.Anything).Return(rpc.GetUnspentsResponse{
+		ErrorResponse: rpc.ErrorResponse{Error: rpc.RpcError{Code: -100, Message: "Invalid address"}},
+	})
+	clientMock.On("GetUnclaimed", mock.Anything).Return(rpc.GetUnclaimedResponse{})
+	clientMock.On("GetNep5Balances", mock.Anything).Return(rpc.GetNep5BalancesResponse{})
+	_, err = w.GetBalance(clientMock, 0)
+	assert.EqualError(t, err, "Invalid address")
+
+	// only a missing plugin of NEP-5 balances is skipped
+	clientMock = new(rpc.RpcClientMock)
+	clientMock.On("GetUnspents", mock.Anything).Return(rpc.GetUnspentsResponse{})
+	clientMock.On("GetUnclaimed", mock.Anything).Return(rpc.GetUnclaimedResponse{})
+	clientMock.On("GetNep5Balances", mock.Anything).Return(rpc.GetNep5BalancesResponse{
+		ErrorResponse: rpc.ErrorResponse{Error: rpc.RpcError{Code: -2146233088, Message: "Plugin failed"}},
+	})
+	_, err = w.GetBalance(clientMock, 0)
+	assert.EqualError(t, err, "Plugin failed")
+}
@@ -49,65 +49,89 @@ func (w *WalletHelper) GetBalance(address string) (neoBalance int, gasBalance fl
 	return neoBalance, gasBalance, nil
 }
 
-// Transfer is used to transfer neo or gas or other utxo asset, single signature, return txid
-func (w *WalletHelper) Transfer(assetId helper.UInt256, from string, to string, amount float64) (string, error) {
-	f, err := helper.AddressToScriptHash(from)
+// signedTransaction is a transaction which can be signed and sent
+type signedTransaction interface {
+	tx.ITransaction
+	HashString() string
+	RawTransactionString() string
+}
+
+// signAndSend signs the transaction with the signer of the account and sends it, return txid
+func (w *WalletHelper) signAndSend(t signedTransaction) (string, error) {
+	if err := w.Account.canSignAlone(); err != nil {
+		return "", err
+	}
+	err := tx.AddSignature(t, w.Account.GetSigner())
 	if err != nil {
 		return "", err
 	}
+	// use RPC to send the tx
+	response := w.TxBuilder.Client.SendRawTransaction(t.RawTransactionString())
+	if response.HasError() {
+		return "", fmt.Errorf(response.ErrorResponse.Error.Message)
+	}
+	return t.HashString(), nil
+}
+
+// MakeTransfer builds the unsigned transaction transferring neo or gas or other utxo asset, so that
+// a watch-only or contract account can sign it elsewhere, e.g. with tx.AddSignature or tx.AddMultiSignature
+func (w *WalletHelper) MakeTransfer(assetId helper.UInt256, from string, to string, amount float64) (*tx.ContractTransaction, error) {
+	f, err := helper.AddressToScriptHash(from)
+	if err != nil {
+		return nil, err
+	}
 	t, err := helper.AddressToScriptHash(to)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	a := helper.Fixed8FromFloat64(amount)
 	ctx, err := w.TxBuilder.MakeContractTransaction(f, t, assetId, a, nil, helper.UInt160{}, helper.Zero)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	// sign
-	err = tx.AddSignature(ctx, w.Account.KeyPair)
+	return ctx, nil
+}
+
+// Transfer is used to transfer neo or gas or other utxo asset, single signature, return txid
+func (w *WalletHelper) Transfer(assetId helper.UInt256, from string, to string, amount float64) (string, error) {
+	ctx, err := w.MakeTransfer(assetId, from, to, amount)
 	if err != nil {
 		return "", err
 	}
-	// use RPC to send the tx
-	response := w.TxBuilder.Client.SendRawTransaction(ctx.RawTransactionString())
-	if response.HasError() {
-		return "", fmt.Errorf(response.ErrorResponse.Error.Message)
-	}
-	return ctx.HashString(), nil
+	return w.signAndSend(ctx)
 }
 
-// ClaimGas, return txid
-func (w *WalletHelper) ClaimGas(from string) (string, error) {
+// MakeClaimGas builds the unsigned claim transaction of the address
+func (w *WalletHelper) MakeClaimGas(from string) (*tx.ClaimTransaction, error) {
 	f, err := helper.AddressToScriptHash(from)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	ctx, err := w.TxBuilder.MakeClaimTransaction(f, helper.UInt160{}, nil)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	// sign
-	err = tx.AddSignature(ctx, w.Account.KeyPair)
+	return ctx, nil
+}
+
+// ClaimGas, return txid
+func (w *WalletHelper) ClaimGas(from string) (string, error) {
+	ctx, err := w.MakeClaimGas(from)
 	if err != nil {
 		return "", err
 	}
-	// use RPC to send the tx
-	response := w.TxBuilder.Client.SendRawTransaction(ctx.RawTransactionString())
-	if response.HasError() {
-		return "", fmt.Errorf(response.ErrorResponse.Error.Message)
-	}
-	return ctx.HashString(), nil
+	return w.signAndSend(ctx)
 }
 
-func (w *WalletHelper) TransferNep5(assetId helper.UInt160, from string, to string, amount float64) (string, error) {
+// MakeTransferNep5 builds the unsigned invocation transaction of the nep5 transfer
+func (w *WalletHelper) MakeTransferNep5(assetId helper.UInt160, from string, to string, amount float64) (*tx.InvocationTransaction, error) {
 	f, err := helper.AddressToScriptHash(from)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	t, err := helper.AddressToScriptHash(to)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	a := helper.Fixed8FromFloat64(amount)
 	sb := sc.NewScriptBuilder()
@@ -127,19 +151,17 @@ func (w *WalletHelper) TransferNep5(assetId helper.UInt160, from string, to stri
 	script := sb.ToArray()
 	itx, err := w.TxBuilder.MakeInvocationTransaction(script, f, nil, helper.UInt160{}, helper.Zero, helper.Zero)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	// sign
-	err = tx.AddSignature(itx, w.Account.KeyPair)
+	return itx, nil
+}
+
+func (w *WalletHelper) TransferNep5(assetId helper.UInt160, from string, to string, amount float64) (string, error) {
+	itx, err := w.MakeTransferNep5(assetId, from, to, amount)
 	if err != nil {
 		return "", err
 	}
-	// use RPC to send the tx
-	response := w.TxBuilder.Client.SendRawTransaction(itx.RawTransactionString())
-	if response.HasError() {
-		return "", fmt.Errorf(response.ErrorResponse.Error.Message)
-	}
-	return itx.HashString(), nil
+	return w.signAndSend(itx)
 }
 
 func (w *WalletHelper) DeployContract(script []byte,
@@ -210,24 +232,21 @@ func (w *WalletHelper) DeployContract(script []byte,
 	if err != nil {
 		return nil, err
 	}
-	err = tx.AddSignature(itx, w.Account.KeyPair)
-	if err != nil {
+	if _, err = w.signAndSend(itx); err != nil {
 		return nil, err
 	}
-	response := w.TxBuilder.Client.SendRawTransaction(itx.RawTransactionString())
-	if response.HasError() {
-		return nil, fmt.Errorf(response.ErrorResponse.Error.Message)
-	}
-
 	return &scriptHash, nil
 }
 
-func (w *WalletHelper) InvokeContract(scriptHash helper.UInt160, method string, args []sc.ContractParameter) (*helper.UInt256, error) {
+// MakeInvokeContract builds the unsigned invocation transaction of the contract method sent by the account
+func (w *WalletHelper) MakeInvokeContract(scriptHash helper.UInt160, method string, args []sc.ContractParameter) (*tx.InvocationTransaction, error) {
 	sb := sc.NewScriptBuilder()
-	sb.MakeInvocationScript(scriptHash.Bytes(), method, args)
+	if err := sb.MakeInvocationScript(scriptHash.Bytes(), method, args); err != nil {
+		return nil, err
+	}
 	script := sb.ToArray()
 
-	from, err := helper.AddressToScriptHash(w.Account.Address)
+	from, err := w.Account.ScriptHash()
 	if err != nil {
 		return nil, err
 	}
@@ -235,13 +254,28 @@ func (w *WalletHelper) InvokeContract(scriptHash helper.UInt160, method string,
 	if err != nil {
 		return nil, err
 	}
-	err = tx.AddSignature(itx, w.Account.KeyPair)
+	return itx, nil
+}
+
+func (w *WalletHelper) InvokeContract(scriptHash helper.UInt160, method string, args []sc.ContractParameter) (*helper.UInt256, error) {
+	itx, err := w.MakeInvokeContract(scriptHash, method, args)
 	if err != nil {
 		return nil, err
 	}
-	response := w.TxBuilder.Client.SendRawTransaction(itx.RawTransactionString())
-	if response.HasError() {
-		return nil, fmt.Errorf(response.ErrorResponse.Error.Message)
+	if _, err = w.signAndSend(itx); err != nil {
+		return nil, err
 	}
 	return &itx.Hash, nil
 }
+
+// InvokeAbiMethod checks the go values against the contract abi and invokes the method with a signed transaction
+func (w *WalletHelper) InvokeAbiMethod(abi *sc.ContractAbi, method string, args ...interface{}) (*helper.UInt256, error) {
+	params, err := abi.MakeParameters(method, args...)
+	if err != nil {
+		return nil, err
+	}
+	if method == abi.EntryPoint {
+		method = ""
+	}
+	return w.InvokeContract(abi.Hash, method, params)
+}
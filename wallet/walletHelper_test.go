@@ -10,6 +10,7 @@ import (
 	"github.com/joeqian10/neo-gogogo/rpc"
 	"github.com/joeqian10/neo-gogogo/rpc/models"
 	"github.com/joeqian10/neo-gogogo/tx"
+	"github.com/joeqian10/neo-gogogo/wallet/keys"
 )
 
 func TestNewWalletHelper(t *testing.T) {
@@ -333,3 +334,56 @@ func TestWalletHelper_TransferNep5(t *testing.T) {
 //	tokenBalanceAfter, _ := nep5Api.BalanceOf(tokenHash, addressHash)
 //	assert.Equal(t, uint64(0), tokenBalanceAfter-tokenBalance)
 //}
+
+func TestWalletHelper_MakeTransfer_WatchOnly(t *testing.T) {
+	var clientMock = new(rpc.RpcClientMock)
+	var tb = &tx.TransactionBuilder{
+		EndPoint: "",
+		Client:   clientMock,
+	}
+	pairs := make([]*keys.KeyPair, 3)
+	publicKeys := make([]*keys.PublicKey, 3)
+	for i := range pairs {
+		pairs[i], _ = keys.NewKeyPairFromWIF(keys.KeyCases[i].Wif)
+		publicKeys[i] = pairs[i].PublicKey
+	}
+	script, _ := keys.CreateMultiSigRedeemScript(2, publicKeys...)
+	account, err := NewAccountFromContract(script, pairs[0])
+	assert.Nil(t, err)
+
+	clientMock.On("GetUnspents", account.Address).Return(rpc.GetUnspentsResponse{
+		Result: models.RpcUnspent{
+			Balances: []models.UnspentBalance{
+				{
+					Unspents: []models.Unspent{
+						{
+							Txid:  "c3182952855314b3f4b1ecf01a03b891d4627d19426ce841275f6d4c186e729a",
+							N:     0,
+							Value: 100,
+						},
+					},
+					AssetHash: "c56f33fc6ecfcd0c225c4ab356fee59390af8560be0e930faebe74a6daff7c9b",
+					Asset:     "NEO",
+					Amount:    100,
+				},
+			},
+			Address: account.Address,
+		},
+	})
+
+	walletHelper := NewWalletHelper(tb, account)
+	_, err = walletHelper.Transfer(tx.NeoToken, account.Address, "AdQk428wVzpkHTxc4MP5UMdsgNdrm36dyV", 10)
+	assert.NotNil(t, err)
+
+	ctx, err := walletHelper.MakeTransfer(tx.NeoToken, account.Address, "AdQk428wVzpkHTxc4MP5UMdsgNdrm36dyV", 10)
+	assert.Nil(t, err)
+	assert.Nil(t, tx.AddMultiSignature(ctx, keys.KeyPairsToSigners(pairs[:2]), 2, publicKeys))
+	assert.Equal(t, script, ctx.Witnesses[0].VerificationScript)
+	assert.True(t, tx.VerifyMultiSignatureWitness(ctx.UnsignedRawTransaction(), ctx.Witnesses[0]))
+
+	watchOnly, _ := NewWatchOnlyAccount(account.Address)
+	walletHelper = NewWalletHelper(tb, watchOnly)
+	_, err = walletHelper.Transfer(tx.NeoToken, account.Address, "AdQk428wVzpkHTxc4MP5UMdsgNdrm36dyV", 10)
+	assert.NotNil(t, err)
+	clientMock.AssertNotCalled(t, "SendRawTransaction", mock.Anything)
+}
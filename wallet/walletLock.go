@@ -0,0 +1,97 @@
+package wallet
+
+import (
+	"fmt"
+	"time"
+)
+
+// Lock clears the decrypted private keys of all accounts, they can be decrypted again with Unlock.
+// The key of a deterministic wallet is cleared as well, call SetMnemonic again to derive new accounts.
+// It fails without clearing anything if an account has no encrypted key, since its key would be lost.
+func (w *Wallet) Lock() error {
+	w.lockMu.Lock()
+	defer w.lockMu.Unlock()
+	return w.lock()
+}
+
+func (w *Wallet) lock() error {
+	for _, acc := range w.Accounts {
+		if acc.isUnencrypted() {
+			return fmt.Errorf("please encrypt the account %s before lock wallet", acc.Address)
+		}
+	}
+	for _, acc := range w.Accounts {
+		acc.clearKeyPair()
+	}
+	if w.hd != nil {
+		w.hd.chainKey.Clear()
+		w.hd = nil
+	}
+	if w.lockTimer != nil {
+		w.lockTimer.Stop()
+		w.lockTimer = nil
+	}
+	return nil
+}
+
+// Unlock decrypts all accounts, the wallet is locked again after the auto-lock timeout if it is set
+func (w *Wallet) Unlock(password string) error {
+	w.lockMu.Lock()
+	defer w.lockMu.Unlock()
+	if err := w.DecryptAll(password); err != nil {
+		return err
+	}
+	w.resetLockTimer()
+	return nil
+}
+
+// IsLocked reports whether no account has a decrypted key
+func (w *Wallet) IsLocked() bool {
+	w.lockMu.Lock()
+	defer w.lockMu.Unlock()
+	for _, acc := range w.Accounts {
+		if acc.hasKeyPair() {
+			return false
+		}
+	}
+	return true
+}
+
+// SetAutoLock makes the wallet lock itself when it is not touched for the timeout after Unlock,
+// 0 disables the auto-lock. Long-running services should call Touch whenever they use the keys.
+func (w *Wallet) SetAutoLock(timeout time.Duration) {
+	w.lockMu.Lock()
+	defer w.lockMu.Unlock()
+	w.autoLock = timeout
+	w.resetLockTimer()
+}
+
+// Touch restarts the auto-lock timeout
+func (w *Wallet) Touch() {
+	w.lockMu.Lock()
+	defer w.lockMu.Unlock()
+	if w.lockTimer != nil {
+		w.resetLockTimer()
+	}
+}
+
+// resetLockTimer starts the auto-lock timer again, lockMu should be held
+func (w *Wallet) resetLockTimer() {
+	if w.lockTimer != nil {
+		w.lockTimer.Stop()
+		w.lockTimer = nil
+	}
+	if w.autoLock <= 0 {
+		return
+	}
+	var timer *time.Timer
+	timer = time.AfterFunc(w.autoLock, func() {
+		w.lockMu.Lock()
+		defer w.lockMu.Unlock()
+		// a timer replaced by Touch may still fire
+		if w.lockTimer == timer {
+			_ = w.lock()
+		}
+	})
+	w.lockTimer = timer
+}
@@ -0,0 +1,93 @@
+package wallet
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/joeqian10/neo-gogogo/wallet/keys"
+)
+
+func TestWallet_Lock(t *testing.T) {
+	w := NewWallet()
+	w.Scrypt = &ScryptParams{N: 2, R: 1, P: 1}
+	assert.Nil(t, w.ImportFromWIF(keys.KeyCases[0].Wif))
+	assert.Nil(t, w.ImportWatchOnly(keys.KeyCases[1].Address))
+	pair := w.Accounts[0].KeyPair
+	assert.EqualError(t, w.Lock(), "please encrypt the account "+keys.KeyCases[0].Address+" before lock wallet")
+	assert.False(t, w.IsLocked())
+
+	assert.Nil(t, w.EncryptAll("password"))
+	assert.Nil(t, w.Lock())
+	assert.True(t, w.IsLocked())
+	assert.Equal(t, make([]byte, 32), pair.PrivateKey)
+
+	assert.NotNil(t, w.Unlock("wrong"))
+	assert.Nil(t, w.Unlock("password"))
+	assert.Equal(t, keys.KeyCases[0].PrivateKey, w.Accounts[0].KeyPair.String())
+}
+
+func TestWallet_SetAutoLock(t *testing.T) {
+	w := NewWallet()
+	w.Scrypt = &ScryptParams{N: 2, R: 1, P: 1}
+	assert.Nil(t, w.ImportFromWIF(keys.KeyCases[0].Wif))
+	assert.Nil(t, w.EncryptAll("password"))
+
+	w.SetAutoLock(100 * time.Millisecond)
+	assert.Nil(t, w.Unlock("password"))
+	time.Sleep(60 * time.Millisecond)
+	w.Touch()
+	time.Sleep(60 * time.Millisecond)
+	assert.False(t, w.IsLocked())
+	time.Sleep(100 * time.Millisecond)
+	assert.True(t, w.IsLocked())
+
+	// disabled
+	w.SetAutoLock(0)
+	assert.Nil(t, w.Unlock("password"))
+	time.Sleep(150 * time.Millisecond)
+	assert.False(t, w.IsLocked())
+}
+
+func TestWallet_SignWhileLocking(t *testing.T) {
+	w := NewWallet()
+	w.Scrypt = &ScryptParams{N: 2, R: 1, P: 1}
+	assert.Nil(t, w.ImportFromWIF(keys.KeyCases[0].Wif))
+	assert.Nil(t, w.EncryptAll("password"))
+	w.SetAutoLock(20 * time.Millisecond)
+	assert.Nil(t, w.Unlock("password"))
+
+	signer := w.Accounts[0].GetSigner()
+	message := []byte("hello")
+	for !w.IsLocked() {
+		// a signature is valid until the timer locks the wallet, then signing fails
+		signature, err := signer.Sign(message)
+		if err != nil {
+			assert.EqualError(t, err, "the account "+keys.KeyCases[0].Address+" is locked")
+			break
+		}
+		assert.True(t, keys.VerifySignature(message, signature, signer.Public()))
+	}
+	_, err := signer.Sign(message)
+	assert.NotNil(t, err)
+	assert.Nil(t, w.Accounts[0].GetSigner())
+}
+
+func TestWallet_EncryptWhileLocking(t *testing.T) {
+	w := NewWallet()
+	w.Scrypt = &ScryptParams{N: 2, R: 1, P: 1}
+	assert.Nil(t, w.ImportFromWIF(keys.KeyCases[0].Wif))
+	assert.Nil(t, w.ImportFromWIF(keys.KeyCases[1].Wif))
+
+	// Lock fails until the accounts are encrypted, then removes the keys
+	done := make(chan error)
+	go func() { done <- w.EncryptAll("password") }()
+	for w.Lock() != nil {
+	}
+	assert.Nil(t, <-done)
+	assert.True(t, w.IsLocked())
+	for _, acc := range w.Accounts {
+		assert.NotEqual(t, "", acc.Nep2Key)
+	}
+}
@@ -1,7 +1,10 @@
 package wallet
 
 import (
+	"fmt"
 	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -31,9 +34,10 @@ func TestWallet_Save(t *testing.T) {
 	testWallet, err := NewWalletFromFile(path)
 	assert.Nil(t, err)
 
-	err = testWallet.Save("testWrite.json")
+	path = filepath.Join(t.TempDir(), "testWrite.json")
+	err = testWallet.Save(path)
 	assert.Nil(t, err)
-	testWrite, err := NewWalletFromFile("testWrite.json")
+	testWrite, err := NewWalletFromFile(path)
 
 	assert.Nil(t, err)
 	assert.Equal(t, testWallet, testWrite)
@@ -73,7 +77,7 @@ func TestEncryptWallet(t *testing.T) {
 	assert.Equal(t, len(testWallet.Accounts), 1)
 	assert.Equal(t, testWallet.Accounts[0].Address, keys.KeyCases[0].Address)
 
-	path := "testWrite.json"
+	path := filepath.Join(t.TempDir(), "testWrite.json")
 	err = testWallet.Save(path)
 	assert.NotNil(t, err)
 	assert.Equal(t, "please encrypt the accounts before save wallet", err.Error())
@@ -92,3 +96,59 @@ func TestEncryptWallet(t *testing.T) {
 	assert.Equal(t, keys.KeyCases[0].PrivateKey, wallet2.Accounts[0].KeyPair.String())
 
 }
+
+func TestWallet_EncryptAllWithOptions(t *testing.T) {
+	w := NewWallet()
+	w.Scrypt = &ScryptParams{N: 2, R: 1, P: 1}
+	for i := 0; i < 20; i++ {
+		assert.Nil(t, w.AddNewAccount())
+	}
+	assert.Nil(t, w.ImportWatchOnly(keys.KeyCases[0].Address))
+
+	var calls []int
+	progress := func(done int, total int) {
+		assert.Equal(t, 20, total)
+		calls = append(calls, done)
+	}
+	assert.Nil(t, w.EncryptAllWithOptions("password", CryptOptions{Workers: 4, Progress: progress}))
+	assert.Equal(t, 20, len(calls))
+	assert.Equal(t, 20, calls[19])
+	// the scrypt parameters of the wallet are used
+	pair, err := keys.NEP2DecryptWithParams(w.Accounts[0].Nep2Key, "password", *w.Scrypt)
+	assert.Nil(t, err)
+	assert.Equal(t, w.Accounts[0].KeyPair.PrivateKey, pair.PrivateKey)
+
+	expected := w.Accounts[19].KeyPair.PrivateKey
+	for _, acc := range w.Accounts {
+		acc.KeyPair = nil
+	}
+	assert.EqualError(t, w.DecryptAllWithOptions("wrong", CryptOptions{Workers: 2}), "password mismatch")
+
+	calls = nil
+	assert.Nil(t, w.DecryptAllWithOptions("password", CryptOptions{Progress: progress}))
+	assert.Equal(t, 20, len(calls))
+	assert.Equal(t, expected, w.Accounts[19].KeyPair.PrivateKey)
+	assert.Nil(t, w.Accounts[20].KeyPair)
+}
+
+func TestWallet_SaveWithOptions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "wallet.json")
+	w := NewWallet()
+	for i := 0; i < 4; i++ {
+		w.Name = fmt.Sprintf("v%d", i)
+		assert.Nil(t, w.SaveWithOptions(path, SaveOptions{Backups: 2}))
+	}
+	names := map[string]string{path: "v3", path + ".1": "v2", path + ".2": "v1"}
+	for p, name := range names {
+		loaded, err := NewWalletFromFile(p)
+		assert.Nil(t, err)
+		assert.Equal(t, name, loaded.Name)
+	}
+	_, err := os.Stat(path + ".3")
+	assert.True(t, os.IsNotExist(err))
+	// no temp file is left
+	files, _ := ioutil.ReadDir(filepath.Dir(path))
+	assert.Equal(t, 3, len(files))
+	info, _ := os.Stat(path)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+}